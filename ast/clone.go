@@ -0,0 +1,59 @@
+package ast
+
+import "reflect"
+
+// Returns a deep copy of the tree. Locations are copied as is, so
+// messages about the copy point to the original code.
+func Clone[T Node](node T) T {
+	return cloneValue(reflect.ValueOf(node)).Interface().(T)
+}
+
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		clone := reflect.New(v.Type().Elem())
+		clone.Elem().Set(cloneValue(v.Elem()))
+		return clone
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(cloneValue(v.Elem()))
+		return clone
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		clone := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+
+		for i := range v.Len() {
+			clone.Index(i).Set(cloneValue(v.Index(i)))
+		}
+
+		return clone
+
+	case reflect.Struct:
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(v)
+
+		for i := range v.NumField() {
+			if clone.Field(i).CanSet() {
+				clone.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+
+		return clone
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,251 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/saffage/jet/token"
+)
+
+// Every node is encoded as a JSON object with the "NodeKind" key, the
+// "Range" key (which is ignored by [DecodeJSON]) and all fields of
+// the node in order of their declaration. Embedded fields are keyed
+// by the name of their type.
+
+const (
+	jsonKindKey  = "NodeKind"
+	jsonRangeKey = "Range"
+)
+
+var (
+	nodeInterface = reflect.TypeFor[Node]()
+	nodeKinds     = map[string]reflect.Type{}
+)
+
+func init() {
+	for _, node := range []Node{
+		(*BadNode)(nil),
+		(*Empty)(nil),
+		(*Ident)(nil),
+		(*Literal)(nil),
+		(*Comment)(nil),
+		(*CommentGroup)(nil),
+		(*AttributeList)(nil),
+		(*Decl)(nil),
+		(*TupleDecl)(nil),
+		(*ArrayType)(nil),
+		(*StructType)(nil),
+		(*EnumType)(nil),
+		(*Variant)(nil),
+		(*Signature)(nil),
+		(*BuiltIn)(nil),
+		(*Call)(nil),
+		(*Index)(nil),
+		(*Function)(nil),
+		(*Dot)(nil),
+		(*Deref)(nil),
+		(*Op)(nil),
+		(*List)(nil),
+		(*StmtList)(nil),
+		(*BracketList)(nil),
+		(*ParenList)(nil),
+		(*CurlyList)(nil),
+		(*If)(nil),
+		(*Else)(nil),
+		(*While)(nil),
+		(*For)(nil),
+		(*Match)(nil),
+		(*MatchArm)(nil),
+		(*Defer)(nil),
+		(*Return)(nil),
+		(*Break)(nil),
+		(*Continue)(nil),
+		(*Import)(nil),
+	} {
+		t := reflect.TypeOf(node).Elem()
+		nodeKinds[t.Name()] = t
+	}
+}
+
+// Returns the name of the node type (for example, "Ident").
+func KindOf(node Node) string {
+	return reflect.TypeOf(node).Elem().Name()
+}
+
+// Encodes the tree into indented JSON. The result can be decoded
+// back with [DecodeJSON].
+func EncodeJSON(node Node) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	if err := encodeValue(buf, reflect.ValueOf(&node).Elem()); err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+
+	if err := json.Indent(out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// Decodes the tree encoded by [EncodeJSON].
+func DecodeJSON(data []byte) (Node, error) {
+	return decodeNode(data)
+}
+
+func encodeNode(buf *bytes.Buffer, node Node) error {
+	v := reflect.ValueOf(node).Elem()
+	t := v.Type()
+
+	if _, ok := nodeKinds[t.Name()]; !ok {
+		return fmt.Errorf("unknown node type %T", node)
+	}
+
+	rng, err := json.Marshal(struct{ Start, End token.Pos }{node.Pos(), node.PosEnd()})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(buf, `{%q:%q,%q:%s`, jsonKindKey, t.Name(), jsonRangeKey, rng)
+
+	for i := 0; i < t.NumField(); i++ {
+		fmt.Fprintf(buf, ",%q:", t.Field(i).Name)
+
+		if err := encodeValue(buf, v.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte('}')
+	return nil
+}
+
+func encodeValue(buf *bytes.Buffer, v reflect.Value) error {
+	switch {
+	case v.Type().Implements(nodeInterface):
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+
+		return encodeNode(buf, v.Interface().(Node))
+
+	case v.Kind() == reflect.Slice:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+
+		buf.WriteByte('[')
+
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := encodeValue(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+		return nil
+
+	default:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return err
+		}
+
+		buf.Write(data)
+		return nil
+	}
+}
+
+func decodeNode(data []byte) (Node, error) {
+	fields := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var kind string
+
+	if err := json.Unmarshal(fields[jsonKindKey], &kind); err != nil {
+		return nil, fmt.Errorf("invalid '%s' key: %w", jsonKindKey, err)
+	}
+
+	t, ok := nodeKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown node kind '%s'", kind)
+	}
+
+	ptr := reflect.New(t)
+
+	for i := 0; i < t.NumField(); i++ {
+		raw, ok := fields[t.Field(i).Name]
+		if !ok {
+			continue
+		}
+
+		if err := decodeValue(raw, ptr.Elem().Field(i)); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", kind, t.Field(i).Name, err)
+		}
+	}
+
+	return ptr.Interface().(Node), nil
+}
+
+func decodeValue(data []byte, v reflect.Value) error {
+	isNull := bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+
+	switch {
+	case v.Type().Implements(nodeInterface):
+		if isNull {
+			return nil
+		}
+
+		node, err := decodeNode(data)
+		if err != nil {
+			return err
+		}
+
+		nodeValue := reflect.ValueOf(node)
+
+		if !nodeValue.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("expected %s, got %T", v.Type(), node)
+		}
+
+		v.Set(nodeValue)
+		return nil
+
+	case v.Kind() == reflect.Slice:
+		if isNull {
+			return nil
+		}
+
+		var elems []json.RawMessage
+
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return err
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+
+		for i, elem := range elems {
+			if err := decodeValue(elem, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		v.Set(slice)
+		return nil
+
+	default:
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+}
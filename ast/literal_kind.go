@@ -1,6 +1,9 @@
 package ast
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 //go:generate stringer -type=LiteralKind -linecomment -output=literal_kind_string.go
 type LiteralKind byte
@@ -16,3 +19,20 @@ const (
 func (kind LiteralKind) MarshalJSON() ([]byte, error) {
 	return json.Marshal(kind.String())
 }
+
+func (kind *LiteralKind) UnmarshalJSON(data []byte) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	for k := UnknownLiteral; k <= StringLiteral; k++ {
+		if k.String() == s {
+			*kind = k
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown literal kind '%s'", s)
+}
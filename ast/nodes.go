@@ -27,6 +27,7 @@ type Node interface {
 type (
 	BadNode struct {
 		DesiredPos token.Pos
+		Decl       *Ident // optional, name of the declaration replaced by the node
 	}
 
 	Empty struct {
@@ -80,6 +81,7 @@ type (
 
 	// Represents '@[..attributes] mut name: T = expr'.
 	Decl struct {
+		Docs  *CommentGroup // optional, '##' comments above the declaration
 		Attrs *AttributeList
 		Ident *Ident
 		Mut   token.Pos // optional
@@ -87,6 +89,13 @@ type (
 		Value Node      // optional
 		IsVar bool      // indicates whether '=' is used before the value instead of ':'
 	}
+
+	// Represents 'mut (a, b) := expr'.
+	TupleDecl struct {
+		Names *ParenList // identifiers, '_' skips the element
+		Mut   token.Pos  // optional
+		Value Node
+	}
 )
 
 func (n *Comment) Pos() token.Pos    { return n.Start }
@@ -115,6 +124,15 @@ func (decl *Decl) PosEnd() token.Pos {
 	return decl.Ident.PosEnd()
 }
 
+func (decl *TupleDecl) Pos() token.Pos {
+	if decl.Mut.IsValid() {
+		return decl.Mut
+	}
+	return decl.Names.Pos()
+}
+
+func (decl *TupleDecl) PosEnd() token.Pos { return decl.Value.PosEnd() }
+
 func (n *CommentGroup) Merged() string {
 	buf := strings.Builder{}
 
@@ -136,26 +154,36 @@ type (
 		Args *BracketList
 	}
 
-	// Represents 'struct {...fields}'.
+	// Represents 'struct[...params] {...fields; ...methods}'.
 	StructType struct {
-		Fields []*Decl
-		TokPos token.Pos
-		Open   token.Pos
-		Close  token.Pos
+		TypeParams *BracketList // optional
+		Fields     []*Decl
+		Methods    []*Decl
+		TokPos     token.Pos
+		Open       token.Pos
+		Close      token.Pos
 	}
 
-	// Represents 'enum {...fields}'.
+	// Represents 'enum {...variants; ...methods}'.
 	EnumType struct {
-		Fields []*Ident
-		TokPos token.Pos
-		Open   token.Pos
-		Close  token.Pos
+		Variants []*Variant
+		Methods  []*Decl
+		TokPos   token.Pos
+		Open     token.Pos
+		Close    token.Pos
+	}
+
+	// Represents 'name' or 'name(...fields)' in the enum body.
+	Variant struct {
+		Ident  *Ident
+		Fields *ParenList // optional
 	}
 
-	// Represents '() -> ()'.
+	// Represents '[...params]() -> ()'.
 	Signature struct {
-		Params *ParenList
-		Result Node // can be nil in some cases
+		TypeParams *BracketList // optional
+		Params     *ParenList
+		Result     Node // can be nil in some cases
 	}
 
 	// Represents an identifier, prefixed with a '$' sign.
@@ -215,8 +243,27 @@ func (n *StructType) PosEnd() token.Pos { return n.Close }
 func (n *EnumType) Pos() token.Pos    { return n.TokPos }
 func (n *EnumType) PosEnd() token.Pos { return n.Close }
 
-func (n *Signature) Pos() token.Pos    { return n.Params.Pos() }
-func (n *Signature) PosEnd() token.Pos { return n.Result.PosEnd() }
+func (n *Variant) Pos() token.Pos { return n.Ident.Pos() }
+func (n *Variant) PosEnd() token.Pos {
+	if n.Fields != nil {
+		return n.Fields.PosEnd()
+	}
+	return n.Ident.PosEnd()
+}
+
+func (n *Signature) Pos() token.Pos {
+	if n.TypeParams != nil {
+		return n.TypeParams.Pos()
+	}
+	return n.Params.Pos()
+}
+
+func (n *Signature) PosEnd() token.Pos {
+	if n.Result != nil {
+		return n.Result.PosEnd()
+	}
+	return n.Params.PosEnd()
+}
 
 func (n *BuiltIn) Pos() token.Pos    { return n.TokPos }
 func (n *BuiltIn) PosEnd() token.Pos { return n.Ident.PosEnd() }
@@ -284,11 +331,33 @@ type (
 	}
 )
 
-func (n *List) Pos() token.Pos    { return n.Nodes[0].Pos() }
-func (n *List) PosEnd() token.Pos { return n.Nodes[len(n.Nodes)-1].PosEnd() }
+func (n *List) Pos() token.Pos {
+	if len(n.Nodes) == 0 {
+		return token.Pos{}
+	}
+	return n.Nodes[0].Pos()
+}
 
-func (n *StmtList) Pos() token.Pos    { return n.Nodes[0].Pos() }
-func (n *StmtList) PosEnd() token.Pos { return n.Nodes[len(n.Nodes)-1].PosEnd() }
+func (n *List) PosEnd() token.Pos {
+	if len(n.Nodes) == 0 {
+		return token.Pos{}
+	}
+	return n.Nodes[len(n.Nodes)-1].PosEnd()
+}
+
+func (n *StmtList) Pos() token.Pos {
+	if len(n.Nodes) == 0 {
+		return token.Pos{}
+	}
+	return n.Nodes[0].Pos()
+}
+
+func (n *StmtList) PosEnd() token.Pos {
+	if len(n.Nodes) == 0 {
+		return token.Pos{}
+	}
+	return n.Nodes[len(n.Nodes)-1].PosEnd()
+}
 
 func (n *BracketList) Pos() token.Pos    { return n.Open }
 func (n *BracketList) PosEnd() token.Pos { return n.Close }
@@ -317,18 +386,37 @@ type (
 	}
 
 	While struct {
+		Label  *Ident // optional
 		Cond   Node
 		Body   *CurlyList
 		TokPos token.Pos // 'while' token.
 	}
 
 	For struct {
+		Label    *Ident // optional
 		DeclList *List
 		IterExpr Node
 		Body     *CurlyList
 		TokPos   token.Pos // 'for' token.
 	}
 
+	// Represents 'match x {...arms}'.
+	Match struct {
+		X      Node
+		Arms   []*MatchArm
+		TokPos token.Pos // 'match' token.
+		Open   token.Pos
+		Close  token.Pos
+	}
+
+	// Represents 'variant => body' or 'variant(...names) => body'.
+	// The variant '_' matches any value.
+	MatchArm struct {
+		Variant *Ident
+		Names   *ParenList // optional
+		Body    Node
+	}
+
 	Defer struct {
 		X      Node
 		TokPos token.Pos // 'defer' token.
@@ -349,9 +437,12 @@ type (
 		TokPos token.Pos
 	}
 
+	// Represents 'import a.b.c', the last name is the name of the
+	// module, the other names are the packages that contain it.
 	Import struct {
-		Module *Ident
-		TokPos token.Pos
+		Attrs  *AttributeList // optional
+		Path   []*Ident
+		TokPos token.Pos // 'import' token.
 	}
 )
 
@@ -366,12 +457,28 @@ func (n *If) PosEnd() token.Pos {
 func (n *Else) Pos() token.Pos    { return n.TokPos }
 func (n *Else) PosEnd() token.Pos { return n.Body.PosEnd() }
 
-func (n *While) Pos() token.Pos    { return n.TokPos }
+func (n *While) Pos() token.Pos {
+	if n.Label != nil {
+		return n.Label.Pos()
+	}
+	return n.TokPos
+}
 func (n *While) PosEnd() token.Pos { return n.Body.PosEnd() }
 
-func (n *For) Pos() token.Pos    { return n.TokPos }
+func (n *For) Pos() token.Pos {
+	if n.Label != nil {
+		return n.Label.Pos()
+	}
+	return n.TokPos
+}
 func (n *For) PosEnd() token.Pos { return n.Body.PosEnd() }
 
+func (n *Match) Pos() token.Pos    { return n.TokPos }
+func (n *Match) PosEnd() token.Pos { return n.Close }
+
+func (n *MatchArm) Pos() token.Pos    { return n.Variant.Pos() }
+func (n *MatchArm) PosEnd() token.Pos { return n.Body.PosEnd() }
+
 func (n *Defer) Pos() token.Pos    { return n.TokPos }
 func (n *Defer) PosEnd() token.Pos { return n.X.PosEnd() }
 
@@ -412,7 +519,10 @@ func (n *Continue) PosEnd() token.Pos {
 }
 
 func (n *Import) Pos() token.Pos    { return n.TokPos }
-func (n *Import) PosEnd() token.Pos { return n.Module.PosEnd() }
+func (n *Import) PosEnd() token.Pos { return n.Module().PosEnd() }
+
+// Returns the name of the imported module.
+func (n *Import) Module() *Ident { return n.Path[len(n.Path)-1] }
 
 //-----------------------------------------------
 // TODO name it
@@ -427,10 +537,12 @@ func (*Comment) implNode()       {}
 func (*CommentGroup) implNode()  {}
 func (*AttributeList) implNode() {}
 func (*Decl) implNode()          {}
+func (*TupleDecl) implNode()     {}
 
 func (*ArrayType) implNode()  {}
 func (*StructType) implNode() {}
 func (*EnumType) implNode()   {}
+func (*Variant) implNode()    {}
 func (*Signature) implNode()  {}
 func (*BuiltIn) implNode()    {}
 func (*Call) implNode()       {}
@@ -450,6 +562,8 @@ func (*If) implNode()       {}
 func (*Else) implNode()     {}
 func (*While) implNode()    {}
 func (*For) implNode()      {}
+func (*Match) implNode()    {}
+func (*MatchArm) implNode() {}
 func (*Defer) implNode()    {}
 func (*Return) implNode()   {}
 func (*Break) implNode()    {}
@@ -1,6 +1,9 @@
 package ast
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 //go:generate stringer -type=OperatorKind -linecomment -output=operator_kind_string.go
 type OperatorKind byte
@@ -62,3 +65,20 @@ const (
 func (kind OperatorKind) MarshalJSON() ([]byte, error) {
 	return json.Marshal(kind.String())
 }
+
+func (kind *OperatorKind) UnmarshalJSON(data []byte) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	for k := UnknownOperator; k <= OperatorRangeExclusive; k++ {
+		if k.String() == s {
+			*kind = k
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown operator '%s'", s)
+}
@@ -43,6 +43,10 @@ func (n *Literal) Repr() string {
 func (decl *Decl) Repr() string {
 	buf := strings.Builder{}
 
+	if decl.Docs != nil {
+		buf.WriteString(decl.Docs.Repr())
+	}
+
 	if decl.Attrs != nil {
 		buf.WriteString(decl.Attrs.Repr())
 		buf.WriteByte(' ')
@@ -70,6 +74,14 @@ func (decl *Decl) Repr() string {
 	return buf.String()
 }
 
+func (decl *TupleDecl) Repr() string {
+	if decl.Mut.IsValid() {
+		return fmt.Sprintf("mut %s := %s", decl.Names.Repr(), decl.Value.Repr())
+	}
+
+	return fmt.Sprintf("%s := %s", decl.Names.Repr(), decl.Value.Repr())
+}
+
 func (n *AttributeList) Repr() string {
 	return "@" + n.List.Repr()
 }
@@ -99,7 +111,13 @@ func (n *ArrayType) Repr() string {
 
 func (n *StructType) Repr() string {
 	buf := strings.Builder{}
-	buf.WriteString("struct {")
+	buf.WriteString("struct")
+
+	if n.TypeParams != nil {
+		buf.WriteString(n.TypeParams.Repr())
+	}
+
+	buf.WriteString(" {")
 
 	for i, field := range n.Fields {
 		if i != 0 {
@@ -110,6 +128,15 @@ func (n *StructType) Repr() string {
 		buf.WriteString(field.Repr())
 	}
 
+	for i, method := range n.Methods {
+		if i != 0 || len(n.Fields) != 0 {
+			buf.WriteByte(';')
+		}
+
+		buf.WriteByte(' ')
+		buf.WriteString(method.Repr())
+	}
+
 	buf.WriteString(" }")
 	return buf.String()
 }
@@ -118,25 +145,50 @@ func (n *EnumType) Repr() string {
 	buf := strings.Builder{}
 	buf.WriteString("enum {")
 
-	for i, field := range n.Fields {
+	for i, variant := range n.Variants {
 		if i != 0 {
 			buf.WriteByte(';')
 		}
 
 		buf.WriteByte(' ')
-		buf.WriteString(field.Repr())
+		buf.WriteString(variant.Repr())
+	}
+
+	for i, method := range n.Methods {
+		if i != 0 || len(n.Variants) != 0 {
+			buf.WriteByte(';')
+		}
+
+		buf.WriteByte(' ')
+		buf.WriteString(method.Repr())
 	}
 
 	buf.WriteString(" }")
 	return buf.String()
 }
 
+func (n *Variant) Repr() string {
+	if n.Fields != nil {
+		return n.Ident.Repr() + n.Fields.Repr()
+	}
+
+	return n.Ident.Repr()
+}
+
 func (n *Signature) Repr() string {
 	if n.Result == nil {
-		return fmt.Sprintf("%s -> ()", n.Params.Repr())
+		return fmt.Sprintf("%s -> ()", n.params())
 	}
 
-	return fmt.Sprintf("%s -> %s", n.Params.Repr(), n.Result.Repr())
+	return fmt.Sprintf("%s -> %s", n.params(), n.Result.Repr())
+}
+
+func (n *Signature) params() string {
+	if n.TypeParams != nil {
+		return n.TypeParams.Repr() + n.Params.Repr()
+	}
+
+	return n.Params.Repr()
 }
 
 func (n *BuiltIn) Repr() string {
@@ -153,7 +205,7 @@ func (n *Index) Repr() string {
 
 func (n *Function) Repr() string {
 	if n.Signature.Result == nil {
-		return fmt.Sprintf("%s %s", n.Signature.Params.Repr(), n.Body.Repr())
+		return fmt.Sprintf("%s %s", n.Signature.params(), n.Body.Repr())
 	}
 
 	return fmt.Sprintf("%s %s", n.Signature.Repr(), n.Body.Repr())
@@ -245,11 +297,45 @@ func (n *Else) Repr() string {
 }
 
 func (n *While) Repr() string {
-	return fmt.Sprintf("while %s %s", n.Cond.Repr(), n.Body.Repr())
+	return fmt.Sprintf("%swhile %s %s", labelRepr(n.Label), n.Cond.Repr(), n.Body.Repr())
 }
 
 func (n *For) Repr() string {
-	return fmt.Sprintf("for %s in %s %s", n.DeclList.Repr(), n.IterExpr.Repr(), n.Body.Repr())
+	return fmt.Sprintf("%sfor %s in %s %s", labelRepr(n.Label), n.DeclList.Repr(), n.IterExpr.Repr(), n.Body.Repr())
+}
+
+func labelRepr(label *Ident) string {
+	if label != nil {
+		return label.Repr() + ": "
+	}
+	return ""
+}
+
+func (n *Match) Repr() string {
+	buf := strings.Builder{}
+	buf.WriteString("match ")
+	buf.WriteString(n.X.Repr())
+	buf.WriteString(" {")
+
+	for i, arm := range n.Arms {
+		if i != 0 {
+			buf.WriteByte(';')
+		}
+
+		buf.WriteByte(' ')
+		buf.WriteString(arm.Repr())
+	}
+
+	buf.WriteString(" }")
+	return buf.String()
+}
+
+func (n *MatchArm) Repr() string {
+	if n.Names != nil {
+		return fmt.Sprintf("%s%s => %s", n.Variant.Repr(), n.Names.Repr(), n.Body.Repr())
+	}
+
+	return fmt.Sprintf("%s => %s", n.Variant.Repr(), n.Body.Repr())
 }
 
 func (n *Defer) Repr() string {
@@ -281,5 +367,12 @@ func (n *Continue) Repr() string {
 }
 
 func (n *Import) Repr() string {
-	return fmt.Sprintf("import %s", n.Module)
+	names := make([]string, len(n.Path))
+	for i, name := range n.Path {
+		names[i] = name.Repr()
+	}
+	if n.Attrs != nil {
+		return n.Attrs.Repr() + " import " + strings.Join(names, ".")
+	}
+	return "import " + strings.Join(names, ".")
 }
@@ -0,0 +1,78 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/saffage/jet/token"
+)
+
+var posType = reflect.TypeFor[token.Pos]()
+
+// Returns the S-expression representation of the tree. Each node is
+// printed as '(Kind start..end :field value ... children)', where
+// nil child nodes and empty positions are omitted.
+func SExpr(node Node) string {
+	buf := &strings.Builder{}
+	writeSExpr(buf, node, 0)
+	return buf.String()
+}
+
+func writeSExpr(buf *strings.Builder, node Node, depth int) {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		buf.WriteString("nil")
+		return
+	}
+
+	v := reflect.ValueOf(node).Elem()
+	t := v.Type()
+
+	buf.WriteString("(" + t.Name() + " " + sexprRange(node.Pos(), node.PosEnd()))
+
+	children := []Node{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+
+		switch {
+		case value.Type().Implements(nodeInterface):
+			if !value.IsNil() {
+				children = append(children, value.Interface().(Node))
+			}
+
+		case value.Kind() == reflect.Slice:
+			for j := 0; j < value.Len(); j++ {
+				if elem := value.Index(j); !elem.IsNil() {
+					children = append(children, elem.Interface().(Node))
+				}
+			}
+
+		case value.Type() == posType:
+			// Positions are already included into the node range.
+
+		case value.Kind() == reflect.String:
+			fmt.Fprintf(buf, " :%s %s", field.Name, strconv.Quote(value.String()))
+
+		case value.Kind() == reflect.Bool:
+			if value.Bool() {
+				fmt.Fprintf(buf, " :%s true", field.Name)
+			}
+
+		default:
+			fmt.Fprintf(buf, " :%s %v", field.Name, value.Interface())
+		}
+	}
+
+	for _, child := range children {
+		buf.WriteString("\n" + strings.Repeat("  ", depth+1))
+		writeSExpr(buf, child, depth+1)
+	}
+
+	buf.WriteByte(')')
+}
+
+func sexprRange(start, end token.Pos) string {
+	return fmt.Sprintf("%d:%d..%d:%d", start.Line, start.Char, end.Line, end.Char)
+}
@@ -43,8 +43,13 @@ func (v Visitor) WalkTopDown(tree Node) {
 		v.walkList(n.List.List)
 
 	case *Decl:
+		// Declarations of the loop variables have neither a type
+		// nor a value.
 		assert(n.Ident != nil)
-		assert(n.Type != nil || n.Value != nil)
+
+		if n.Docs != nil {
+			v.WalkTopDown(n.Docs)
+		}
 
 		if n.Attrs != nil {
 			v.WalkTopDown(n.Attrs)
@@ -60,6 +65,13 @@ func (v Visitor) WalkTopDown(tree Node) {
 			v.WalkTopDown(n.Value)
 		}
 
+	case *TupleDecl:
+		assert(n.Names != nil)
+		assert(n.Value != nil)
+
+		v.WalkTopDown(n.Names)
+		v.WalkTopDown(n.Value)
+
 	case *ArrayType:
 		assert(n.X != nil)
 		assert(n.Args != nil)
@@ -68,22 +80,51 @@ func (v Visitor) WalkTopDown(tree Node) {
 		v.walkList(n.Args.List)
 
 	case *StructType:
+		if n.TypeParams != nil {
+			v.walkList(n.TypeParams.List)
+		}
+
 		for _, field := range n.Fields {
 			assert(field != nil)
 
 			v.WalkTopDown(field)
 		}
 
+		for _, method := range n.Methods {
+			assert(method != nil)
+
+			v.WalkTopDown(method)
+		}
+
 	case *EnumType:
-		for _, field := range n.Fields {
-			assert(field != nil)
+		for _, variant := range n.Variants {
+			assert(variant != nil)
 
-			v.WalkTopDown(field)
+			v.WalkTopDown(variant)
+		}
+
+		for _, method := range n.Methods {
+			assert(method != nil)
+
+			v.WalkTopDown(method)
+		}
+
+	case *Variant:
+		assert(n.Ident != nil)
+
+		v.WalkTopDown(n.Ident)
+
+		if n.Fields != nil {
+			v.walkList(n.Fields.List)
 		}
 
 	case *Signature:
 		assert(n.Params != nil)
 
+		if n.TypeParams != nil {
+			v.walkList(n.TypeParams.List)
+		}
+
 		v.walkList(n.Params.List)
 
 		if n.Result != nil {
@@ -129,8 +170,8 @@ func (v Visitor) WalkTopDown(tree Node) {
 		v.WalkTopDown(n.X)
 
 	case *Op:
-		assert(n.X != nil)
-		assert(n.Y != nil)
+		// Unary operators have only one operand, '...' in the
+		// variadic parameter can have none.
 
 		if n.X != nil {
 			v.WalkTopDown(n.X)
@@ -171,10 +212,36 @@ func (v Visitor) WalkTopDown(tree Node) {
 
 		v.WalkTopDown(n.Body)
 
+	case *Match:
+		assert(n.X != nil)
+
+		v.WalkTopDown(n.X)
+
+		for _, arm := range n.Arms {
+			assert(arm != nil)
+
+			v.WalkTopDown(arm)
+		}
+
+	case *MatchArm:
+		assert(n.Variant != nil)
+		assert(n.Body != nil)
+
+		v.WalkTopDown(n.Variant)
+
+		if n.Names != nil {
+			v.walkList(n.Names.List)
+		}
+
+		v.WalkTopDown(n.Body)
+
 	case *While:
 		assert(n.Cond != nil)
 		assert(n.Body != nil)
 
+		if n.Label != nil {
+			v.WalkTopDown(n.Label)
+		}
 		v.WalkTopDown(n.Cond)
 		v.WalkTopDown(n.Body)
 
@@ -184,6 +251,9 @@ func (v Visitor) WalkTopDown(tree Node) {
 		assert(n.IterExpr != nil)
 		assert(n.Body != nil)
 
+		if n.Label != nil {
+			v.WalkTopDown(n.Label)
+		}
 		v.walkList(n.DeclList)
 		v.WalkTopDown(n.IterExpr)
 		v.WalkTopDown(n.Body)
@@ -209,9 +279,17 @@ func (v Visitor) WalkTopDown(tree Node) {
 		}
 
 	case *Import:
-		assert(n.Module != nil)
+		assert(len(n.Path) > 0)
 
-		v.WalkTopDown(n.Module)
+		if n.Attrs != nil {
+			v.WalkTopDown(n.Attrs)
+		}
+
+		for _, name := range n.Path {
+			assert(name != nil)
+
+			v.WalkTopDown(name)
+		}
 
 	default:
 		// Should not happen.
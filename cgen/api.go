@@ -3,22 +3,29 @@ package cgen
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/types"
 )
 
-func Generate(w io.Writer, m *checker.Module) error {
+// Generates the C source file of the module and its header. The header
+// contains type definitions and prototypes of the module declarations,
+// so other modules can include it instead of generating them again.
+func Generate(src, header io.Writer, m *checker.Module) error {
 	gen := &generator{
-		Module: m,
-		out:    bufio.NewWriter(w),
-		scope:  m.Scope,
-	}
-
-	_, _ = gen.out.WriteString(prelude)
-
-	if err := gen.out.Flush(); err != nil {
-		panic(err)
+		Module:     m,
+		out:        bufio.NewWriter(header),
+		scope:      m.Scope,
+		arrayTypes: map[types.Type]string{},
+		sliceTypes: map[types.Type]string{},
+		funcTypes:  map[types.Type]string{},
+		tupleTypes: map[types.Type]string{},
+		typeDecls:  map[checker.Symbol]bool{},
+		thunks:     map[*checker.Func]string{},
+		lambdas:    map[*checker.Lambda]bool{},
 	}
 
 	mainFn := gen.defs(gen.Defs, gen.Scope)
@@ -28,13 +35,33 @@ func Generate(w io.Writer, m *checker.Module) error {
 		gen.fn(mainFn)
 	}
 
+	guard := "JET_MODULE_" + strings.ToUpper(ModuleName(m)) + "_H"
+
+	_, _ = gen.out.WriteString(generatedComment)
+	_, _ = gen.out.WriteString(fmt.Sprintf("\n#ifndef %[1]s\n#define %[1]s\n", guard))
+	_, _ = gen.out.WriteString(prelude)
 	_, _ = gen.out.WriteString(gen.includeSect.String())
-	_, _ = gen.out.WriteString("\n/* TYPES */\n")
+	_, _ = gen.out.WriteString("\n\n/* TYPES */\n")
+	_, _ = gen.out.WriteString(gen.forwardTypeSect.String())
 	_, _ = gen.out.WriteString(gen.typeSect.String())
 	_, _ = gen.out.WriteString("\n/* DECL */\n")
-	_, _ = gen.out.WriteString(gen.declVarsSect.String())
+	_, _ = gen.out.WriteString(gen.externVarsSect.String())
 	_, _ = gen.out.WriteString("\n")
 	_, _ = gen.out.WriteString(gen.declFnsSect.String())
+	_, _ = gen.out.WriteString(fmt.Sprintf("\n#endif /* %s */\n", guard))
+
+	if err := gen.out.Flush(); err != nil {
+		panic(err)
+	}
+
+	gen.out.Reset(src)
+
+	_, _ = gen.out.WriteString(generatedComment)
+	_, _ = gen.out.WriteString(fmt.Sprintf("\n#include \"%s\"\n", HeaderName(m)))
+	_, _ = gen.out.WriteString("\n/* DECL */\n")
+	_, _ = gen.out.WriteString(gen.declVarsSect.String())
+	_, _ = gen.out.WriteString("\n/* LAMBDAS */\n")
+	_, _ = gen.out.WriteString(gen.lambdaSect.String())
 	_, _ = gen.out.WriteString("\n/* CODE */\n")
 	_, _ = gen.out.WriteString(gen.codeSect.String())
 
@@ -44,3 +71,15 @@ func Generate(w io.Writer, m *checker.Module) error {
 
 	return errors.Join(gen.errors...)
 }
+
+// Returns the name of the header file generated for the module.
+func HeaderName(m *checker.Module) string {
+	return ModuleName(m) + ".h"
+}
+
+// Returns the name of the module that is used in the generated code and
+// in the names of the generated files. Packages of the module are
+// separated by '__', so modules with the same name do not conflict.
+func ModuleName(m *checker.Module) string {
+	return strings.ReplaceAll(m.QualifiedName(), ".", "__")
+}
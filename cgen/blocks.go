@@ -9,19 +9,13 @@ func (gen *generator) block(list *ast.StmtList, result *checker.Var) {
 	gen.line("{\n")
 	gen.indent++
 
-	if _, ok := scopeIDs[gen.scope]; !ok {
-		scopeIDs[gen.scope] = 0
-	} else {
-		scopeIDs[gen.scope]++
-	}
-
-	id := scopeIDs[gen.scope]
-	scope := gen.scope.Children()[id]
-
 	defer gen.setScope(gen.scope)
-	gen.setScope(scope)
+	gen.setScope(gen.nextScope())
 
-	var deferNodes []*ast.Defer
+	// Deferred statements are collected while the block is generated,
+	// so 'return', 'break' and 'continue' can execute them before the jump.
+	frame := len(gen.defers)
+	gen.defers = append(gen.defers, nil)
 
 	if len(list.Nodes) > 0 {
 		lastIdx := len(list.Nodes) - 1
@@ -29,29 +23,25 @@ func (gen *generator) block(list *ast.StmtList, result *checker.Var) {
 
 		for _, stmt := range list.Nodes[:lastIdx] {
 			if deferNode, _ := stmt.(*ast.Defer); deferNode != nil {
-				println("deferred node: " + deferNode.Repr())
-				deferNodes = append(deferNodes, deferNode)
+				gen.defers[frame] = append(gen.defers[frame], gen.deferStmt(deferNode))
 				continue
 			}
 
 			gen.stmt(stmt)
 		}
 
-		if result != nil {
+		if deferNode, _ := lastNode.(*ast.Defer); deferNode != nil {
+			gen.defers[frame] = append(gen.defers[frame], gen.deferStmt(deferNode))
+		} else if _, isReturn := lastNode.(*ast.Return); result != nil && !isReturn {
+			gen.lineDirective(lastNode)
 			gen.assign(gen.name(result), lastNode)
-		} else if deferNode, _ := lastNode.(*ast.Defer); deferNode != nil {
-			println("deferred node: " + deferNode.Repr())
-			deferNodes = append(deferNodes, deferNode)
 		} else {
 			gen.stmt(lastNode)
 		}
 	}
 
-	for i := len(deferNodes) - 1; i >= 0; i-- {
-		gen.linef("L%d:;\n", gen.funcLabelID)
-		gen.funcLabelID++
-		gen.stmt(deferNodes[i].X)
-	}
+	gen.runDefers(frame)
+	gen.defers = gen.defers[:frame]
 
 	delete(scopeIDs, gen.scope)
 	gen.indent--
@@ -70,3 +60,15 @@ func scopePath(scope *checker.Scope) string {
 }
 
 var scopeIDs = map[*checker.Scope]int{}
+
+// Returns the next child scope of the current scope. Child scopes are
+// visited in the same order as the checker creates them.
+func (gen *generator) nextScope() *checker.Scope {
+	if _, ok := scopeIDs[gen.scope]; !ok {
+		scopeIDs[gen.scope] = 0
+	} else {
+		scopeIDs[gen.scope]++
+	}
+
+	return gen.scope.Children()[scopeIDs[gen.scope]]
+}
@@ -85,6 +85,13 @@ func (gen *generator) builtInPrint(call *ast.Call) string {
 		}
 
 	case *types.Enum:
+		if t.IsTagged() {
+			return fmt.Sprintf(
+				`fprintf(stdout, "%%d", (%s).tag)`,
+				gen.exprString(call.Args.Nodes[0]),
+			)
+		}
+
 		return fmt.Sprintf(
 			`fprintf(stdout, "%%d", %s)`,
 			gen.exprString(call.Args.Nodes[0]),
@@ -141,6 +148,13 @@ func (gen *generator) builtInPrintln(call *ast.Call) string {
 		}
 
 	case *types.Enum:
+		if t.IsTagged() {
+			return fmt.Sprintf(
+				`fprintf(stdout, "%%d\n", (%s).tag)`,
+				gen.exprString(call.Args.Nodes[0]),
+			)
+		}
+
 		return fmt.Sprintf(
 			`fprintf(stdout, "%%d\n", %s)`,
 			gen.exprString(call.Args.Nodes[0]),
@@ -1,13 +1,67 @@
 package cgen
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/types"
 )
 
-func (gen *generator) constDecl(_ *checker.Const) {
-	// gen.dataSect.WriteString(fmt.Sprintf(
-	// 	"#define %[1]s %[2]s // constant\n",
-	// 	gen.name(sym),
-	// 	sym.Value(),
-	// ))
+// Scalar constants are inlined, arrays and structs are declared as
+// global variables, so the value is not copied at each use.
+func (gen *generator) constDecl(sym *checker.Const) {
+	if !isAggregateConst(sym) {
+		return
+	}
+
+	t := gen.TypeString(sym.Type())
+	gen.declVarsSect.WriteString(fmt.Sprintf(
+		"%s %s = %s;\n",
+		t,
+		gen.name(sym),
+		gen.constantInit(sym.Value(), sym.Type()),
+	))
+	gen.externVarsSect.WriteString(fmt.Sprintf("extern %s %s;\n", t, gen.name(sym)))
+}
+
+// Returns the initializer of the variable of the specified type.
+func (gen *generator) constantInit(value constant.Value, ty types.Type) string {
+	switch value.Kind() {
+	case constant.Array:
+		tyElem := types.AsArray(ty).ElemType()
+		elems := []string{}
+
+		for _, elem := range *constant.AsArray(value) {
+			elems = append(elems, gen.constantInit(elem, tyElem))
+		}
+
+		return "{" + strings.Join(elems, ", ") + "}"
+
+	case constant.Struct:
+		values := constant.AsStruct(value)
+		fields := []string{}
+
+		for _, field := range types.AsStruct(ty).Fields() {
+			fields = append(fields, fmt.Sprintf(
+				".%s = %s",
+				field.Name,
+				gen.constantInit(values[field.Name], field.Type),
+			))
+		}
+
+		return "{" + strings.Join(fields, ", ") + "}"
+
+	default:
+		return gen.constant(value)
+	}
+}
+
+func isAggregateConst(sym *checker.Const) bool {
+	switch sym.Value().Kind() {
+	case constant.Array, constant.Struct:
+		return true
+	}
+	return false
 }
@@ -6,17 +6,24 @@ import (
 	"github.com/saffage/jet/types"
 )
 
-func (gen *generator) decl(sym checker.Symbol) {
+func (gen *generator) decl(sym *checker.Var) {
 	node := sym.Node().(*ast.Decl)
 	ty := sym.Type()
-	gen.linef("%s %s;\n", gen.TypeString(ty), gen.name(sym))
+
+	if gen.isBoxed(sym) {
+		gen.boxDecl(sym, "")
+	} else {
+		gen.linef("%s %s;\n", gen.TypeString(ty), gen.name(sym))
+	}
 
 	if node.Value != nil {
 		if array := types.AsArray(ty); array != nil {
-			gen.arrayAssign(gen.name(sym), node.Value, array)
+			gen.arrayAssign(gen.varRef(sym), node.Value, array)
 			// gen.line(";\n")
 		} else if _struct := types.AsStruct(ty); _struct != nil {
-			gen.structAssign(gen.name(sym), node.Value, _struct)
+			gen.structAssign(gen.varRef(sym), node.Value, _struct)
+		} else if tuple := types.AsTuple(ty); tuple != nil && !tuple.Equals(types.Unit) {
+			gen.tupleAssign(gen.varRef(sym), node.Value)
 		} else {
 			gen.linef("%s;\n",
 				gen.binary(
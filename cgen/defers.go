@@ -0,0 +1,104 @@
+package cgen
+
+import (
+	"strings"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/types"
+)
+
+// Deferred statements are generated on every exit from the block: at
+// its end, and before 'return', 'break' and 'continue'. Each copy gets
+// its own labels and temporary variables.
+
+// Deferred statement of the block being generated.
+type deferred struct {
+	node    *ast.Defer
+	scope   *checker.Scope // Scope the statement is declared in.
+	scopeID int            // Last child scope visited before the statement, -1 if none.
+}
+
+// Records the deferred statement. It is also generated once where it
+// is declared, with the code discarded, so the scopes inside of it are
+// visited in the same order as the checker visits them and its lambdas
+// are defined.
+func (gen *generator) deferStmt(node *ast.Defer) *deferred {
+	d := &deferred{node: node, scope: gen.scope, scopeID: -1}
+	if id, ok := scopeIDs[gen.scope]; ok {
+		d.scopeID = id
+	}
+
+	codeSect := gen.codeSect
+	gen.codeSect = strings.Builder{}
+	gen.stmt(node.X)
+	gen.codeSect = codeSect
+
+	return d
+}
+
+// Generates the deferred statement at the current position. Its scopes
+// are visited again, starting from where it is declared.
+func (gen *generator) deferredStmt(d *deferred) {
+	scope := gen.scope
+	id, ok := scopeIDs[d.scope]
+
+	defer func() {
+		gen.scope = scope
+		if ok {
+			scopeIDs[d.scope] = id
+		} else {
+			delete(scopeIDs, d.scope)
+		}
+	}()
+
+	gen.scope = d.scope
+	if d.scopeID < 0 {
+		delete(scopeIDs, d.scope)
+	} else {
+		scopeIDs[d.scope] = d.scopeID
+	}
+
+	// Temporary variables of the statement must not conflict when
+	// it is generated more than once in the same block.
+	gen.line("{\n")
+	gen.indent++
+	gen.stmt(d.node.X)
+	gen.indent--
+	gen.line("}\n")
+}
+
+// Generates deferred statements of the blocks starting from the
+// specified one, in reverse order.
+func (gen *generator) runDefers(frame int) {
+	for i := len(gen.defers) - 1; i >= frame; i-- {
+		for j := len(gen.defers[i]) - 1; j >= 0; j-- {
+			gen.deferredStmt(gen.defers[i][j])
+		}
+	}
+}
+
+// Generates 'return'. The result is evaluated before the deferred
+// statements of all blocks of the function are executed.
+func (gen *generator) returnStmt(node *ast.Return) {
+	if node.X != nil {
+		if gen.fnResult != nil {
+			gen.assign(gen.name(gen.fnResult), node.X)
+		} else {
+			gen.stmt(node.X)
+		}
+	}
+
+	gen.runDefers(0)
+
+	switch {
+	case gen.fnIsMain:
+		gen.line("return 0;\n")
+
+	case gen.fnResult == nil || types.IsArray(gen.fnResult.Type()):
+		gen.line("return;\n")
+
+	default:
+		gen.linef("return %s;\n", gen.name(gen.fnResult))
+	}
+}
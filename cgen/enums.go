@@ -1,21 +1,161 @@
 package cgen
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/checker"
 	"github.com/saffage/jet/types"
 )
 
 func (gen *generator) enumDecl(sym *checker.Enum) {
+	if gen.typeDecls[sym] {
+		return
+	}
+
+	gen.typeDecls[sym] = true
+	ty := types.SkipTypeDesc(sym.Type()).(*types.Enum)
+
+	if ty.IsTagged() {
+		gen.taggedEnumDecl(sym, ty)
+		return
+	}
+
 	buf := strings.Builder{}
-	gen.flinef(&buf, "typedef enum %s {\n", sym.Name())
-	gen.indent++
 	enumName := gen.name(sym)
-	for i, field := range types.SkipTypeDesc(sym.Type()).(*types.Enum).Fields() {
+	gen.flinef(&buf, "typedef enum %s {\n", enumName)
+	gen.indent++
+	for i, field := range ty.Fields() {
 		gen.flinef(&buf, "%s = %d,\n", enumName+"__"+field, i)
 	}
 	gen.indent--
 	gen.flinef(&buf, "} %s;\n", enumName)
 	gen.typeSect.WriteString(buf.String())
 }
+
+// Generates the enum with payloads as a struct with the tag and the
+// union of payloads.
+func (gen *generator) taggedEnumDecl(sym *checker.Enum, ty *types.Enum) {
+	enumName := gen.name(sym)
+	gen.forwardTypeSect.WriteString(fmt.Sprintf("typedef struct %[1]s %[1]s;\n", enumName))
+
+	// Types stored by value must be defined first.
+	for _, field := range ty.Fields() {
+		if payload := ty.Payload(field); payload != nil {
+			for _, payloadField := range payload.Fields() {
+				gen.typeDeps(payloadField.Type)
+			}
+		}
+	}
+
+	buf := strings.Builder{}
+	gen.fline(&buf, "enum {\n")
+	gen.indent++
+	for i, field := range ty.Fields() {
+		gen.flinef(&buf, "%s = %d,\n", enumName+"__"+field, i)
+	}
+	gen.indent--
+	gen.fline(&buf, "};\n")
+	gen.flinef(&buf, "struct %s {\n", enumName)
+	gen.indent++
+	gen.fline(&buf, "Ti32 tag;\n")
+	gen.fline(&buf, "union {\n")
+	gen.indent++
+	for _, field := range ty.Fields() {
+		payload := ty.Payload(field)
+		if payload == nil {
+			continue
+		}
+		gen.fline(&buf, "struct {\n")
+		gen.indent++
+		for _, payloadField := range payload.Fields() {
+			gen.flinef(&buf, "%s %s;\n", gen.TypeString(payloadField.Type), payloadField.Name)
+		}
+		gen.indent--
+		gen.flinef(&buf, "} %s;\n", field)
+	}
+	gen.indent--
+	gen.fline(&buf, "} as;\n")
+	gen.indent--
+	gen.fline(&buf, "};\n")
+	gen.typeSect.WriteString(buf.String())
+}
+
+// Returns the value of the enum variant without payload.
+func (gen *generator) variant(ty *types.Enum, field string) string {
+	if ty.IsTagged() {
+		return fmt.Sprintf("((%[1]s){.tag = %[1]s__%[2]s})", gen.TypeString(ty), field)
+	}
+
+	return gen.TypeString(ty) + "__" + field
+}
+
+// Generates 'T.variant(...fields)' and returns the name of the
+// temporary variable with the result.
+func (gen *generator) variantInit(call *ast.Call, dot *ast.Dot, ty *types.Enum) string {
+	tmp := gen.tempVar(ty)
+	gen.linef("%s.tag = %s__%s;\n", gen.name(tmp), gen.TypeString(ty), dot.Y.Name)
+	gen.structInitList(gen.name(tmp)+".as."+dot.Y.Name, call.Args.List, ty.Payload(dot.Y.Name))
+	return gen.name(tmp)
+}
+
+func (gen *generator) match(node *ast.Match, result *checker.Var) {
+	ty := types.AsEnum(gen.TypeOf(node.X))
+	operand := gen.tempVar(ty)
+	gen.assign(gen.name(operand), node.X)
+
+	if ty.IsTagged() {
+		gen.linef("switch (%s.tag)\n", gen.name(operand))
+	} else {
+		gen.linef("switch (%s)\n", gen.name(operand))
+	}
+
+	gen.line("{\n")
+
+	for _, arm := range node.Arms {
+		if arm.Variant.Name == "_" {
+			gen.line("default:\n")
+		} else {
+			gen.linef("case %s__%s:\n", gen.TypeString(ty), arm.Variant.Name)
+		}
+
+		gen.arm(arm, gen.name(operand), result)
+	}
+
+	gen.line("}\n")
+}
+
+func (gen *generator) arm(arm *ast.MatchArm, operand string, result *checker.Var) {
+	defer gen.setScope(gen.scope)
+	gen.setScope(gen.nextScope())
+
+	gen.line("{\n")
+	gen.indent++
+
+	if arm.Names != nil {
+		for _, node := range arm.Names.Nodes {
+			sym := gen.SymbolOf(node.(*ast.Ident))
+			gen.linef(
+				"%s %s = %s.as.%s.%s;\n",
+				gen.TypeString(sym.Type()),
+				gen.name(sym),
+				operand,
+				arm.Variant.Name,
+				sym.Name(),
+			)
+		}
+	}
+
+	if body, _ := arm.Body.(*ast.CurlyList); body != nil {
+		gen.block(body.StmtList, result)
+	} else if result != nil {
+		gen.assign(gen.name(result), arm.Body)
+	} else {
+		gen.stmt(arm.Body)
+	}
+
+	gen.line("break;\n")
+	gen.indent--
+	gen.line("}\n")
+}
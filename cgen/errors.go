@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/report"
 )
 
 type Error struct {
@@ -20,6 +21,14 @@ func newErrorf(sym checker.Symbol, format string, args ...any) *Error {
 
 func (e *Error) Error() string { return e.Message }
 
+func (e *Error) Report() {
+	if e.Sym == nil || e.Sym.Ident() == nil {
+		report.TaggedError("cgen", e.Message)
+		return
+	}
+	report.TaggedErrorAt("cgen", e.Sym.Ident().Pos(), e.Sym.Ident().PosEnd(), e.Message)
+}
+
 func (gen *generator) errorf(sym checker.Symbol, format string, args ...any) {
 	gen.errors = append(gen.errors, newErrorf(sym, format, args...))
 }
@@ -25,10 +25,16 @@ func (gen *generator) exprString(expr ast.Node) string {
 
 	case *ast.Ident:
 		switch sym := gen.SymbolOf(node).(type) {
-		case *checker.Var, *checker.Func:
-			return gen.name(sym)
+		case *checker.Var:
+			return gen.varRef(sym)
+
+		case *checker.Func:
+			return gen.funcValue(sym)
 
 		case *checker.Const:
+			if isAggregateConst(sym) {
+				return gen.name(sym)
+			}
 			return gen.constant(sym.Value())
 
 		case nil:
@@ -50,6 +56,16 @@ func (gen *generator) exprString(expr ast.Node) string {
 		}
 
 	case *ast.Dot:
+		if fn, _ := gen.Uses[node.Y].(*checker.Func); fn != nil {
+			return gen.funcValue(fn)
+		}
+
+		if ident, _ := node.X.(*ast.Ident); ident != nil {
+			if _, isModule := gen.SymbolOf(ident).(*checker.Module); isModule {
+				return gen.exprString(node.Y)
+			}
+		}
+
 		tv := gen.Types[node.X]
 		if tv == nil {
 			// Defined in another module?
@@ -63,13 +79,18 @@ func (gen *generator) exprString(expr ast.Node) string {
 				// if tyY := gen.TypeOf(node.Y); tyY != nil && tyY.Equals(ty) {
 				// 	// Enum field.
 				// }
-				return gen.TypeString(_enum) + "__" + node.Y.Name
+				return gen.variant(_enum, node.Y.Name)
 				// return "ERROR_CGEN__INVALID_ENUM_FIELD"
 			} else {
 				return "ERROR_CGEN__INVALID_MEMBER_ACCESS"
 			}
 		}
 
+		if array := types.AsArray(tv.Type); array != nil {
+			// The only member of an array is its length.
+			return strconv.Itoa(array.Size())
+		}
+
 		return gen.exprString(node.X) + "." + node.Y.Name
 
 	case *ast.Deref:
@@ -113,6 +134,12 @@ func (gen *generator) exprString(expr ast.Node) string {
 			return gen.BuiltInCall(builtIn, node)
 		}
 
+		if dot, _ := node.X.(*ast.Dot); dot != nil && types.IsTypeDesc(gen.TypeOf(dot.X)) {
+			if ty := types.AsEnum(gen.TypeOf(dot)); ty != nil {
+				return gen.variantInit(node, dot, ty)
+			}
+		}
+
 		tv := gen.Types[node.X]
 		if tv == nil {
 			// Defined in another module?
@@ -129,38 +156,59 @@ func (gen *generator) exprString(expr ast.Node) string {
 				return "ERROR_CGEN__INVALID_CALL"
 			}
 		} else if fn := types.AsFunc(tv.Type); fn != nil {
-			buf := strings.Builder{}
-			buf.WriteString(gen.exprString(node.X))
-			buf.WriteByte('(')
-			for i, arg := range node.Args.Nodes {
-				if i != 0 {
-					buf.WriteString(", ")
-				}
-				buf.WriteString(gen.exprString(arg))
+			callee := ""
+			args := []string{}
+
+			if sym := gen.funcSymbol(node.X); sym != nil {
+				callee = gen.name(sym)
+			} else {
+				// Call of the function value.
+				value := gen.operand(node.X, fn)
+				callee = value + ".fn"
+				args = append(args, value+".env")
+			}
+
+			if recv := gen.receiver(node.X, fn); recv != "" {
+				args = append(args, recv)
+			}
+			for _, arg := range node.Args.Nodes {
+				args = append(args, gen.exprString(arg))
 			}
 			if fn.Result().Len() == 1 && types.IsArray(fn.Result().Types()[0]) {
-				if len(node.Args.Nodes) > 0 {
-					buf.WriteString(", ")
-				}
-				buf.WriteString("/*RESULT*/")
+				args = append(args, "/*RESULT*/")
 			}
-			buf.WriteByte(')')
-			return buf.String()
+			return callee + "(" + strings.Join(args, ", ") + ")"
 		}
 
+	case *ast.Function:
+		return gen.lambda(node)
+
 	case *ast.Index:
-		buf := strings.Builder{}
-		buf.WriteString(gen.exprString(node.X))
-		buf.WriteByte('[')
+		if fn := gen.instanceOf(node.X); fn != nil {
+			// Explicit instantiation of the generic function.
+			return gen.funcValue(fn)
+		}
 
 		if len(node.Args.Nodes) != 1 {
 			// Error in the checker
 			panic("invalid arguments count for the index expression")
 		}
 
-		buf.WriteString(gen.exprString(node.Args.Nodes[0]))
-		buf.WriteByte(']')
-		return "(" + buf.String() + ")"
+		if bounds, _ := node.Args.Nodes[0].(*ast.Op); bounds != nil &&
+			(bounds.Kind == ast.OperatorRangeInclusive || bounds.Kind == ast.OperatorRangeExclusive) {
+			return gen.sliceExpr(node, bounds)
+		}
+
+		return gen.index(node)
+
+	case *ast.ParenList:
+		ty := types.AsTuple(types.SkipUntyped(gen.TypeOf(node)))
+		if ty == nil || ty.Equals(types.Unit) {
+			panic("unreachable")
+		}
+		tmpVar := gen.tempVar(ty)
+		gen.tupleAssign(gen.name(tmpVar), node)
+		return gen.name(tmpVar)
 
 	case *ast.BracketList:
 		// NOTE when array is used not in assignment they
@@ -190,6 +238,19 @@ func (gen *generator) exprString(expr ast.Node) string {
 		}
 		return gen.name(tmpVar)
 
+	case *ast.Match:
+		ty := gen.TypeOf(expr)
+		if ty == nil {
+			panic("match expression have no type")
+		}
+
+		tmpVar := gen.tempVar(types.SkipUntyped(ty))
+		gen.match(node, tmpVar)
+		if tmpVar == nil {
+			return ""
+		}
+		return gen.name(tmpVar)
+
 	case *ast.CurlyList:
 		ty := gen.TypeOf(expr)
 		if ty == nil {
@@ -383,6 +444,13 @@ func (gen *generator) assign(dest string, value ast.Node) {
 	case *types.Struct:
 		gen.structAssign(dest, value, ty)
 
+	case *types.Tuple:
+		if ty.Equals(types.Unit) {
+			gen.linef("(void)%s;\n", gen.exprString(value))
+		} else {
+			gen.tupleAssign(dest, value)
+		}
+
 	default:
 		if ty.Equals(types.Unit) {
 			gen.linef("(void)%s;\n", gen.exprString(value))
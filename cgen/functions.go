@@ -2,6 +2,7 @@ package cgen
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/saffage/jet/ast"
@@ -17,11 +18,17 @@ func (gen *generator) fn(sym *checker.Func) {
 	decl := ""
 	tyResult := types.Type(nil)
 
-	if sym.Name() == "main" {
+	if gen.isEntry(sym) {
+		gen.line("\n")
+		gen.lineDirective(sym.Node())
 		gen.line(fnMainHead)
 	} else {
 		decl, tyResult = gen.fnDecl(sym)
 		gen.flinef(&gen.declFnsSect, "%s;\n", decl)
+
+		if !sym.IsExtern() {
+			gen.lineDirective(sym.Node())
+		}
 	}
 
 	if !sym.IsExtern() {
@@ -53,8 +60,8 @@ func (gen *generator) fnDecl(sym *checker.Func) (decl string, tyResult types.Typ
 		}
 		tyResult = ty.Underlying()
 	} else {
-		gen.errorf(sym, "tuples are not supported")
-		buf.WriteString("ERROR_CGEN__FUNC_TUPLE_RESULT")
+		buf.WriteString(gen.TypeString(result))
+		tyResult = result
 	}
 
 	buf.WriteByte(' ')
@@ -91,7 +98,7 @@ func (gen *generator) fnDecl(sym *checker.Func) (decl string, tyResult types.Typ
 
 			buf.WriteString(gen.TypeString(param.Type()))
 			buf.WriteByte(' ')
-			buf.WriteString(gen.name(param))
+			buf.WriteString(gen.paramName(param))
 		}
 
 		if isArrayResult {
@@ -123,21 +130,134 @@ func (gen *generator) fnDef(sym *checker.Func, tyResult types.Type) {
 	gen.indent++
 
 	resultVar := gen.resultVar(tyResult)
+	gen.fnResult, gen.fnIsMain = resultVar, gen.isEntry(sym)
 
-	if sym.Name() == "main" {
-		gen.linef("init%s();\n", gen.Module.Name())
+	if gen.fnIsMain {
+		gen.linef("init%s();\n", ModuleName(gen.Module))
 	}
 
-	if list, _ := value.Body.(*ast.CurlyList); list != nil {
+	gen.boxParams(sym.Params())
+
+	gen.fnBody(value.Body, resultVar, tyResult)
+
+	gen.indent--
+	gen.line("}\n")
+}
+
+// Generates statements of the function body and returns the value of
+// the body as the function result.
+func (gen *generator) fnBody(body ast.Node, resultVar *checker.Var, tyResult types.Type) {
+	if list, _ := body.(*ast.CurlyList); list != nil {
 		gen.block(list.StmtList, resultVar)
 	} else if resultVar != nil {
-		gen.assign("__result", value.Body)
+		gen.assign("__result", body)
 	}
 
 	if resultVar != nil && !types.IsArray(tyResult) {
 		gen.line("return __result;\n")
 	}
+}
 
-	gen.indent--
-	gen.line("}\n")
+// Returns the receiver argument if the callee is a method called as
+// 'x.method(...)'. Otherwise, returns an empty string.
+func (gen *generator) receiver(callee ast.Node, fn *types.Func) string {
+	dot, _ := callee.(*ast.Dot)
+	if dot == nil {
+		return ""
+	}
+
+	if method, _ := gen.Uses[dot.Y].(*checker.Func); method == nil || method.MethodOf() == nil {
+		return ""
+	}
+
+	tRecv := gen.TypeOf(dot.X)
+	if types.IsTypeDesc(tRecv) {
+		return ""
+	}
+
+	switch tParam := fn.Params().Types()[0]; {
+	case types.IsRef(tParam) && !types.IsRef(tRecv):
+		return fmt.Sprintf("(&%s)", gen.exprString(dot.X))
+
+	case !types.IsRef(tParam) && types.IsRef(tRecv):
+		return fmt.Sprintf("(*%s)", gen.exprString(dot.X))
+	}
+
+	return gen.exprString(dot.X)
+}
+
+// Returns the C result type of the function. Arrays are returned via
+// the last parameter, so the type of the parameter is returned too.
+func (gen *generator) funcResult(ty *types.Func) (result string, tyArray types.Type) {
+	switch ty.Result().Len() {
+	case 0:
+		return "void", nil
+
+	case 1:
+		tyResult := ty.Result().Types()[0]
+		if types.IsEnum(tyResult) {
+			gen.typeDeps(tyResult)
+		}
+		if types.IsArray(tyResult) {
+			return "void", tyResult
+		}
+		return gen.TypeString(tyResult), nil
+
+	default:
+		return gen.TypeString(ty.Result()), nil
+	}
+}
+
+// Returns the name of the function value type. Function values consist
+// of the environment of the function and the pointer to the function
+// accepting the environment as the first argument. The typedef is
+// generated on the first use.
+func (gen *generator) funcType(ty *types.Func) string {
+	if s, ok := gen.funcTypes[ty]; ok {
+		return s
+	}
+
+	params := []string{}
+
+	for _, param := range ty.Params().Types() {
+		if types.IsEnum(param) {
+			gen.typeDeps(param)
+		}
+		params = append(params, gen.TypeString(param))
+	}
+
+	result, tyArray := gen.funcResult(ty)
+	if tyArray != nil {
+		params = append(params, gen.TypeString(tyArray))
+	}
+
+	nameParts := slices.Clone(params)
+	if len(nameParts) == 0 {
+		nameParts = append(nameParts, "void")
+	}
+	typeName := strings.ReplaceAll("fn_"+strings.Join(nameParts, "_")+"__"+result, "*", "_ptr")
+
+	params = append([]string{"void *"}, params...)
+	if ty.Variadic() != nil {
+		params = append(params, "...")
+	}
+
+	alreadyDefined := false
+	for _, typeName0 := range gen.funcTypes {
+		if typeName0 == typeName {
+			// Prevent similar typedefs.
+			alreadyDefined = true
+		}
+	}
+	if !alreadyDefined {
+		// The same function type can be defined in headers of other modules.
+		gen.typeSect.WriteString(fmt.Sprintf(
+			"#ifndef JET_FN_%[1]s\n#define JET_FN_%[1]s\ntypedef struct { void *env; %[2]s (*fn)(%[3]s); } %[1]s;\n#endif\n",
+			typeName,
+			result,
+			strings.Join(params, ", "),
+		))
+	}
+	gen.funcTypes[ty] = typeName
+	return typeName
 }
@@ -5,29 +5,48 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/elliotchance/orderedmap/v2"
 	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/config"
 	"github.com/saffage/jet/report"
+	"github.com/saffage/jet/types"
 )
 
 type generator struct {
 	*checker.Module
 
-	scope         *checker.Scope
-	funcTempVarId int
-	funcLabelID   int
-	headers       []string
-	includeSect   strings.Builder
-	typeSect      strings.Builder
-	declVarsSect  strings.Builder
-	declFnsSect   strings.Builder
-	codeSect      strings.Builder
-	out           *bufio.Writer
-	errors        []error
-	indent        int
+	scope           *checker.Scope
+	funcTempVarId   int
+	funcLabelID     int
+	headers         []string
+	includeSect     strings.Builder
+	forwardTypeSect strings.Builder
+	typeSect        strings.Builder
+	declVarsSect    strings.Builder
+	externVarsSect  strings.Builder
+	declFnsSect     strings.Builder
+	arrayTypes      map[types.Type]string
+	sliceTypes      map[types.Type]string
+	funcTypes       map[types.Type]string
+	tupleTypes      map[types.Type]string
+	typeDecls       map[checker.Symbol]bool // Already generated structs and enums.
+	thunks          map[*checker.Func]string
+	lambdas         map[*checker.Lambda]bool // Already generated lambdas.
+	captures        map[*checker.Var]string  // Variables captured by the generated lambda.
+	boxed           map[*checker.Var]bool    // Variables captured by reference, see [generator.isBoxed].
+	loops           []*loop                  // Loops being generated, the innermost is the last.
+	defers          [][]*deferred            // Deferred statements of the blocks being generated.
+	fnResult        *checker.Var             // Result of the function being generated, nil if it has no result.
+	fnIsMain        bool
+	lambdaSect      strings.Builder
+	codeSect        strings.Builder
+	out             *bufio.Writer
+	errors          []error
+	indent          int
 }
 
 func (gen *generator) defs(
@@ -38,7 +57,10 @@ func (gen *generator) defs(
 		def := def.Value
 		_, isImportedModule := def.(*checker.Module)
 
-		if def.Owner() != owner && !isImportedModule {
+		// Instances of generic declarations are owned by the scope
+		// with their type parameters, methods are owned by the scope
+		// with methods of the type.
+		if def.Owner() != owner && !isImportedModule && typeArgs(def) == nil && !isMethod(def) {
 			continue
 		}
 
@@ -67,14 +89,20 @@ func (gen *generator) defs(
 			gen.enumDecl(sym)
 
 		case *checker.Func:
-			if sym.Name() == "main" && mainFunc == nil {
+			if gen.isEntry(sym) {
 				mainFunc = sym
 			} else {
 				gen.fn(sym)
 			}
 
 		case *checker.Module:
-			gen.defs(sym.Defs, sym.Scope)
+			gen.include(sym)
+
+		case *checker.Generic:
+			// Only instances are generated.
+
+		case *checker.TypeAlias:
+			// Aliases are replaced with their base types.
 
 		default:
 			report.Warningf("not implemented (%T)", sym)
@@ -84,11 +112,44 @@ func (gen *generator) defs(
 	return mainFunc
 }
 
+// Reports whether the function is the entry point of the program, that
+// is generated as the C 'main' function. Only 'main' of the main module
+// is the entry point, in other modules it is an ordinary function.
+func (gen *generator) isEntry(sym *checker.Func) bool {
+	return sym.Name() == "main" && sym.Owner() == gen.Scope && gen.FileID() == config.MainFileID
+}
+
+// Includes the header of the imported module.
+func (gen *generator) include(m *checker.Module) {
+	header := fmt.Sprintf("\"%s\"", HeaderName(m))
+
+	if !slices.Contains(gen.headers, header) {
+		gen.headers = append(gen.headers, header)
+		gen.includeSect.WriteString(fmt.Sprintf("\n#include %s", header))
+	}
+}
+
 func (gen *generator) setScope(scope *checker.Scope) {
 	report.TaggedDebugf("cgen", "set scope: %s", scopePath(scope))
 	gen.scope = scope
 }
 
+// Emits the '#line' directive for the node, so the C compiler and
+// debuggers refer to the Jet source instead of the generated file.
+func (gen *generator) lineDirective(node ast.Node) {
+	pos := node.Pos()
+	if !pos.IsValid() {
+		return
+	}
+
+	fileinfo, ok := config.Global.Files[pos.FileID]
+	if !ok || fileinfo.Path == "" {
+		return
+	}
+
+	gen.codeSect.WriteString(fmt.Sprintf("#line %d %s\n", pos.Line, strconv.Quote(fileinfo.Path)))
+}
+
 func (gen *generator) line(s string) {
 	gen.fline(&gen.codeSect, s)
 }
@@ -119,7 +180,11 @@ func (gen *generator) flinef(w io.StringWriter, format string, args ...any) {
 	}
 }
 
-const prelude = `/* GENERATED BY JET COMPILER */
+const generatedComment = "/* GENERATED BY JET COMPILER */\n"
+
+const prelude = `
+#ifndef JET_PRELUDE
+#define JET_PRELUDE
 
 #undef NDEBUG
 #include <assert.h>
@@ -141,6 +206,25 @@ typedef uint64_t Tu64;
 typedef float    Tf32;
 typedef double   Tf64;
 typedef uint8_t  Tbool;
+
+static inline Ti32 jet__check_index(Ti32 index, Ti32 len, const char *loc) {
+	if (index < 0 || index >= len) {
+		fflush(stdout);
+		fprintf(stderr, "%s: index out of bounds: the length is %d but the index is %d\n", loc, (int)len, (int)index);
+		abort();
+	}
+	return index;
+}
+
+static inline void jet__check_slice(Ti32 lo, Ti32 hi, Ti32 len, const char *loc) {
+	if (lo < 0 || lo > hi || hi > len) {
+		fflush(stdout);
+		fprintf(stderr, "%s: slice bounds out of range: %d..<%d with length %d\n", loc, (int)lo, (int)hi, (int)len);
+		abort();
+	}
+}
+
+#endif /* JET_PRELUDE */
 `
 
-const fnMainHead = "\nint main(const int argc, const char *const *const argv)"
+const fnMainHead = "int main(const int argc, const char *const *const argv)"
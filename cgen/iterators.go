@@ -0,0 +1,87 @@
+package cgen
+
+import (
+	"fmt"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/types"
+)
+
+// Generates the loop over an array, slice, string or iterator. The
+// element is declared at the start of each iteration.
+func (gen *generator) forIter(node *ast.For) {
+	loopVars := make([]*checker.Var, 0, len(node.DeclList.Nodes))
+
+	for _, decl := range node.DeclList.Nodes {
+		loopVars = append(loopVars, gen.SymbolOf(decl.(*ast.Decl).Ident).(*checker.Var))
+	}
+
+	elem := loopVars[len(loopVars)-1]
+	index := ""
+
+	if len(loopVars) == 2 && !gen.isBoxed(loopVars[0]) {
+		index = gen.name(loopVars[0])
+	} else {
+		index = fmt.Sprintf("tmp__%d", gen.funcTempVarId)
+		gen.funcTempVarId++
+	}
+
+	ty := gen.TypeOf(node.IterExpr)
+	value := ""
+	loop := gen.pushLoop(node.Label)
+
+	switch {
+	case types.IsArray(ty):
+		x := gen.operand(node.IterExpr, ty)
+		gen.linef("for (Ti32 %[1]s=0; %[1]s<%[2]d; %[1]s+=1)\n", index, types.AsArray(ty).Size())
+		value = fmt.Sprintf("%s[%s]", x, index)
+
+	case types.IsSlice(ty):
+		x := gen.operand(node.IterExpr, ty)
+		gen.linef("for (Ti32 %[1]s=0; %[1]s<%[2]s.len; %[1]s+=1)\n", index, x)
+		value = fmt.Sprintf("%s.ptr[%s]", x, index)
+
+	case ty == types.String:
+		x := gen.operand(node.IterExpr, ty)
+		gen.linef("for (Ti32 %[1]s=0; %[2]s[%[1]s]!='\\0'; %[1]s+=1)\n", index, x)
+		value = fmt.Sprintf("(Tu8)%s[%s]", x, index)
+
+	default:
+		// The iterator is copied, because 'next' changes it.
+		next, valueFn := gen.MethodOf(ty, "next"), gen.MethodOf(ty, "value")
+		iter := gen.tempVar(ty)
+		gen.assign(gen.name(iter), node.IterExpr)
+
+		if len(loopVars) == 2 {
+			gen.linef("for (Ti32 %[1]s=0; %[2]s(&%[3]s); %[1]s+=1)\n", index, gen.name(next), gen.name(iter))
+		} else {
+			gen.linef("while (%s(&%s))\n", gen.name(next), gen.name(iter))
+		}
+
+		if types.IsRef(valueFn.Params()[0].Type()) {
+			value = fmt.Sprintf("%s(&%s)", gen.name(valueFn), gen.name(iter))
+		} else {
+			value = fmt.Sprintf("%s(%s)", gen.name(valueFn), gen.name(iter))
+		}
+	}
+
+	gen.loopBody(loop, func() {
+		// Variables captured by reference are allocated for each
+		// iteration, like in the interpreter.
+		if len(loopVars) == 2 && gen.isBoxed(loopVars[0]) {
+			gen.boxDecl(loopVars[0], index)
+		}
+
+		if gen.isBoxed(elem) {
+			gen.boxDecl(elem, value)
+		} else if types.IsArray(elem.Type()) {
+			gen.line(gen.varDecl(elem))
+			gen.linef("memcpy(%[1]s, %[2]s, sizeof(%[1]s));\n", gen.name(elem), value)
+		} else {
+			gen.linef("%s %s = %s;\n", gen.TypeString(elem.Type()), gen.name(elem), value)
+		}
+
+		gen.block(node.Body.StmtList, nil)
+	})
+}
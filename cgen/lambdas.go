@@ -0,0 +1,324 @@
+package cgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/types"
+)
+
+// Function values are pairs of the environment pointer and the pointer
+// to the function accepting the environment as the first argument (see
+// [generator.funcType]). Declared functions have no environment, they
+// are called through a thunk. Lambdas are lowered to a static function
+// and a struct with captured variables.
+
+// Returns the function value referring to the declared function.
+func (gen *generator) funcValue(sym *checker.Func) string {
+	ty := types.AsFunc(sym.Type())
+	return fmt.Sprintf("((%s){NULL, %s})", gen.funcType(ty), gen.thunk(sym))
+}
+
+// Returns the name of the function forwarding calls to the declared
+// function. The thunk is generated on the first use.
+func (gen *generator) thunk(sym *checker.Func) string {
+	if name, ok := gen.thunks[sym]; ok {
+		return name
+	}
+
+	ty := types.AsFunc(sym.Type())
+	if ty.Variadic() != nil {
+		panic("variadic functions as values is not implemented")
+	}
+
+	name := gen.name(sym) + "__thunk"
+	result, tyArray := gen.funcResult(ty)
+	params := []string{"void *env"}
+	args := []string{}
+
+	for i, param := range ty.Params().Types() {
+		params = append(params, fmt.Sprintf("%s p%d", gen.TypeString(param), i))
+		args = append(args, fmt.Sprintf("p%d", i))
+	}
+
+	if tyArray != nil {
+		params = append(params, gen.TypeString(tyArray)+" __result")
+		args = append(args, "__result")
+	}
+
+	ret := "return "
+	if result == "void" {
+		ret = ""
+	}
+
+	gen.lambdaSect.WriteString(fmt.Sprintf(
+		"static %[1]s %[2]s(%[3]s)\n{\n\t(void)env;\n\t%[4]s%[5]s(%[6]s);\n}\n",
+		result,
+		name,
+		strings.Join(params, ", "),
+		ret,
+		gen.name(sym),
+		strings.Join(args, ", "),
+	))
+	gen.thunks[sym] = name
+	return name
+}
+
+// Returns the declared function called by the expression, or nil if
+// the callee is a function value.
+func (gen *generator) funcSymbol(callee ast.Node) *checker.Func {
+	switch node := callee.(type) {
+	case *ast.Ident:
+		sym, _ := gen.SymbolOf(node).(*checker.Func)
+		return sym
+
+	case *ast.Dot:
+		sym, _ := gen.Uses[node.Y].(*checker.Func)
+		return sym
+
+	case *ast.Index:
+		if ident, _ := node.X.(*ast.Ident); ident != nil {
+			if sym, _ := gen.SymbolOf(ident).(*checker.Func); sym != nil && sym.Generic() != nil {
+				return sym
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the expression of the variable. Inside of the lambda, the
+// captured variables are accessed through the environment.
+func (gen *generator) varRef(sym *checker.Var) string {
+	if ref, ok := gen.captures[sym]; ok {
+		return ref
+	}
+	if gen.isBoxed(sym) {
+		return "(*" + gen.name(sym) + ")"
+	}
+	return gen.name(sym)
+}
+
+// Reports whether the variable is captured by reference. Such variables
+// are allocated on the heap, so the lambdas can outlive the function
+// declaring them. The memory is never freed.
+func (gen *generator) isBoxed(sym *checker.Var) bool {
+	if gen.boxed == nil {
+		gen.boxed = map[*checker.Var]bool{}
+
+		for _, lambda := range gen.Lambdas {
+			for _, capture := range lambda.Captures() {
+				if capture.ByRef {
+					gen.boxed[capture.Var] = true
+				}
+			}
+		}
+	}
+
+	return gen.boxed[sym]
+}
+
+// Declares the variable captured by reference. It is initialized with
+// the value, if the value is not empty.
+func (gen *generator) boxDecl(sym *checker.Var, value string) {
+	gen.linef("%[1]s *%[2]s = malloc(sizeof(%[1]s));\n", gen.TypeString(sym.Type()), gen.name(sym))
+
+	switch {
+	case value == "":
+
+	case types.IsArray(sym.Type()):
+		gen.linef("memcpy(*%[1]s, %[2]s, sizeof(*%[1]s));\n", gen.name(sym), value)
+
+	default:
+		gen.linef("*%s = %s;\n", gen.name(sym), value)
+	}
+}
+
+// Returns the name of the parameter in the function signature. The
+// parameter captured by reference is copied to the heap in the body.
+func (gen *generator) paramName(param *checker.Var) string {
+	if gen.isBoxed(param) {
+		return gen.name(param) + "__arg"
+	}
+	return gen.name(param)
+}
+
+// Copies the parameters captured by reference to the heap.
+func (gen *generator) boxParams(params []*checker.Var) {
+	for _, param := range params {
+		if gen.isBoxed(param) {
+			gen.boxDecl(param, gen.paramName(param))
+		}
+	}
+}
+
+// Generates the lambda and returns the function value referring to it.
+func (gen *generator) lambda(node *ast.Function) string {
+	lambda := gen.Lambdas[node]
+	if lambda == nil {
+		// Error in the checker
+		return "ERROR_CGEN__INVALID_LAMBDA"
+	}
+
+	if gen.scope == lambda.Local().Parent() {
+		// Skip the scope of the lambda, so the blocks after
+		// it will get their own scopes.
+		gen.nextScope()
+	}
+
+	buf := strings.Builder{}
+	gen.namefInternal(&buf, lambda.Owner())
+	buf.WriteString(lambda.Name())
+	name := buf.String()
+
+	env := "NULL"
+
+	// Deferred statements are generated more than once, but their
+	// lambdas are defined only once.
+	isDefined := gen.lambdas[lambda]
+	gen.lambdas[lambda] = true
+
+	if len(lambda.Captures()) > 0 {
+		if !isDefined {
+			gen.lambdaEnvType(lambda, name)
+		}
+
+		// The environment is allocated on the heap, so the lambda can
+		// outlive the function it was created in. It is never freed.
+		// Variables captured by reference are on the heap too.
+		env = fmt.Sprintf("tmp__%d", gen.funcTempVarId)
+		gen.funcTempVarId++
+		gen.linef("%[1]s__env *%[2]s = malloc(sizeof(%[1]s__env));\n", name, env)
+
+		for i, capture := range lambda.Captures() {
+			field := captureField(capture, i)
+
+			switch {
+			case capture.ByRef:
+				gen.linef("%s->%s = &%s;\n", env, field, gen.varRef(capture.Var))
+
+			case types.IsArray(capture.Var.Type()):
+				gen.linef(
+					"memcpy(%[1]s->%[2]s, %[3]s, sizeof(%[1]s->%[2]s));\n",
+					env,
+					field,
+					gen.varRef(capture.Var),
+				)
+
+			default:
+				gen.linef("%s->%s = %s;\n", env, field, gen.varRef(capture.Var))
+			}
+		}
+	}
+
+	if !isDefined {
+		gen.lambdaDef(lambda, name)
+	}
+	return fmt.Sprintf("((%s){%s, %s})", gen.funcType(lambda.Type()), env, name)
+}
+
+func (gen *generator) lambdaEnvType(lambda *checker.Lambda, name string) {
+	buf := strings.Builder{}
+	buf.WriteString("typedef struct {\n")
+
+	for i, capture := range lambda.Captures() {
+		ptr := ""
+		if capture.ByRef {
+			ptr = "*"
+		}
+		buf.WriteString(fmt.Sprintf(
+			"\t%s %s%s;\n",
+			gen.TypeString(capture.Var.Type()),
+			ptr,
+			captureField(capture, i),
+		))
+	}
+
+	buf.WriteString(fmt.Sprintf("} %s__env;\n", name))
+	gen.lambdaSect.WriteString(buf.String())
+}
+
+func (gen *generator) lambdaDef(lambda *checker.Lambda, name string) {
+	defer gen.setScope(gen.scope)
+
+	// The lambda is generated into its own section, the state of the
+	// enclosing function is restored after.
+	codeSect := gen.codeSect
+	funcTempVarId, funcLabelID := gen.funcTempVarId, gen.funcLabelID
+	indent, captures := gen.indent, gen.captures
+	loops, defers := gen.loops, gen.defers
+	fnResult, fnIsMain := gen.fnResult, gen.fnIsMain
+
+	defer func() {
+		gen.lambdaSect.WriteString(gen.codeSect.String())
+		gen.codeSect = codeSect
+		gen.funcTempVarId, gen.funcLabelID = funcTempVarId, funcLabelID
+		gen.indent, gen.captures = indent, captures
+		gen.loops, gen.defers = loops, defers
+		gen.fnResult, gen.fnIsMain = fnResult, fnIsMain
+	}()
+
+	gen.setScope(lambda.Local())
+	gen.codeSect = strings.Builder{}
+	gen.funcTempVarId, gen.funcLabelID = 0, 0
+	gen.indent = 0
+	gen.captures = map[*checker.Var]string{}
+	gen.loops, gen.defers = nil, nil
+
+	for i, capture := range lambda.Captures() {
+		if capture.ByRef {
+			gen.captures[capture.Var] = fmt.Sprintf("(*__env->%s)", captureField(capture, i))
+		} else {
+			gen.captures[capture.Var] = fmt.Sprintf("(__env->%s)", captureField(capture, i))
+		}
+	}
+
+	result, tyArray := gen.funcResult(lambda.Type())
+	params := []string{"void *env"}
+
+	for _, param := range lambda.Params() {
+		params = append(params, gen.TypeString(param.Type())+" "+gen.paramName(param))
+	}
+
+	if tyArray != nil {
+		params = append(params, gen.TypeString(tyArray)+" __result")
+	}
+
+	gen.lineDirective(lambda.Node())
+	gen.linef("static %s %s(%s)\n", result, name, strings.Join(params, ", "))
+	gen.line("{\n")
+	gen.indent++
+
+	if len(lambda.Captures()) > 0 {
+		gen.linef("%[1]s__env *__env = (%[1]s__env *)env;\n", name)
+	} else {
+		gen.line("(void)env;\n")
+	}
+
+	tyResult := types.Type(nil)
+
+	switch tyFuncResult := lambda.Type().Result(); tyFuncResult.Len() {
+	case 0:
+
+	case 1:
+		tyResult = tyFuncResult.Types()[0].Underlying()
+
+	default:
+		tyResult = tyFuncResult
+	}
+
+	resultVar := gen.resultVar(tyResult)
+	gen.fnResult, gen.fnIsMain = resultVar, false
+	gen.boxParams(lambda.Params())
+	gen.fnBody(lambda.Node().Body, resultVar, tyResult)
+
+	gen.indent--
+	gen.line("}\n")
+}
+
+// Returns the name of the environment field for the captured variable.
+func captureField(capture *checker.Capture, index int) string {
+	return fmt.Sprintf("%s_%d", capture.Var.Name(), index)
+}
@@ -0,0 +1,80 @@
+package cgen
+
+import (
+	"github.com/saffage/jet/ast"
+)
+
+// Loop being generated. Loops are exited with 'goto', so 'break' and
+// 'continue' work inside of 'match' (which is a C 'switch') and can
+// refer to the outer loops. Labels are emitted only if they are used.
+type loop struct {
+	label        string // Label of the loop in the source code, optional.
+	breakID      int
+	continueID   int
+	breakUsed    bool
+	continueUsed bool
+	defers       int // Number of blocks with defers outside of the loop.
+}
+
+func (gen *generator) pushLoop(label *ast.Ident) *loop {
+	l := &loop{
+		breakID:    gen.funcLabelID,
+		continueID: gen.funcLabelID + 1,
+		defers:     len(gen.defers),
+	}
+	if label != nil {
+		l.label = label.Name
+	}
+	gen.funcLabelID += 2
+	gen.loops = append(gen.loops, l)
+	return l
+}
+
+// Generates the body of the loop followed by the label for 'continue'.
+// The label for 'break' is emitted after the loop.
+func (gen *generator) loopBody(l *loop, body func()) {
+	gen.line("{\n")
+	gen.indent++
+	body()
+	if l.continueUsed {
+		gen.linef("L%d:;\n", l.continueID)
+	}
+	gen.indent--
+	gen.line("}\n")
+
+	gen.loops = gen.loops[:len(gen.loops)-1]
+	if l.breakUsed {
+		gen.linef("L%d:;\n", l.breakID)
+	}
+}
+
+// Generates 'break' or 'continue'. Deferred statements of the blocks
+// inside of the target loop are executed before the jump.
+func (gen *generator) loopExit(label *ast.Ident, isBreak bool) {
+	if len(gen.loops) == 0 {
+		// Error in the checker
+		gen.line("ERROR_CGEN__LOOP_EXIT_OUTSIDE_OF_LOOP;\n")
+		return
+	}
+
+	target := gen.loops[len(gen.loops)-1]
+
+	if label != nil {
+		for i := len(gen.loops) - 1; i >= 0; i-- {
+			if gen.loops[i].label == label.Name {
+				target = gen.loops[i]
+				break
+			}
+		}
+	}
+
+	gen.runDefers(target.defers)
+
+	if isBreak {
+		target.breakUsed = true
+		gen.linef("goto L%d;\n", target.breakID)
+	} else {
+		target.continueUsed = true
+		gen.linef("goto L%d;\n", target.continueID)
+	}
+}
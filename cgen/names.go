@@ -4,7 +4,10 @@ import (
 	"io"
 	"strings"
 
+	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/types"
 )
 
 var names = map[checker.Symbol]string{}
@@ -22,6 +25,17 @@ func (gen *generator) name(sym checker.Symbol) string {
 
 	switch sym := sym.(type) {
 	case *checker.Var:
+		if config.Global.Flags.DebugInfo && (sym.IsLocal() || sym.IsParam()) {
+			// Keep names of local variables readable in a debugger. The
+			// prefix prevents collisions with C keywords and with the names
+			// referred by the generated code.
+			prefix := "l_"
+			if sym.IsParam() {
+				prefix = "p_"
+			}
+			names[sym] = prefix + sym.Name()
+			return names[sym]
+		}
 		if sym.IsGlobal() {
 			buf.WriteString("g_")
 		}
@@ -36,10 +50,21 @@ func (gen *generator) name(sym checker.Symbol) string {
 			}
 			return sym.Name()
 		}
+
+		if sym.MethodOf() != nil {
+			names[sym] = gen.name(sym.MethodOf()) + "__" + sym.Name()
+			return names[sym]
+		}
 	}
 
 	gen.namefInternal(&buf, sym.Owner())
 	buf.WriteString(sym.Name())
+
+	for _, t := range typeArgs(sym) {
+		buf.WriteString("__")
+		buf.WriteString(gen.typeArgName(t))
+	}
+
 	names[sym] = buf.String()
 	return buf.String()
 }
@@ -55,7 +80,8 @@ func (gen *generator) namefInternal(w io.StringWriter, scope *checker.Scope) {
 
 		switch scopeName[:spaceIndex] {
 		case "module":
-			defer w.WriteString(scopeName[spaceIndex+1:] + "__")
+			// Names of the packages are separated by dots.
+			defer w.WriteString(strings.ReplaceAll(scopeName[spaceIndex+1:], ".", "__") + "__")
 
 		case "func":
 			defer w.WriteString(scopeName[spaceIndex+1:] + "__")
@@ -66,6 +92,9 @@ func (gen *generator) namefInternal(w io.StringWriter, scope *checker.Scope) {
 		case "block":
 			// There must be a block ID I think.
 
+		case "generic":
+			// Instances are distinguished by type arguments.
+
 		case "struct":
 		case "global":
 		default:
@@ -75,3 +104,45 @@ func (gen *generator) namefInternal(w io.StringWriter, scope *checker.Scope) {
 		scope = scope.Parent()
 	}
 }
+
+// Returns type arguments of the instantiated function or struct.
+func typeArgs(sym checker.Symbol) []types.Type {
+	switch sym := sym.(type) {
+	case *checker.Func:
+		return sym.TypeArgs()
+
+	case *checker.Struct:
+		return sym.TypeArgs()
+	}
+
+	return nil
+}
+
+// Returns a part of the instance name for the type argument.
+func (gen *generator) typeArgName(t types.Type) string {
+	return strings.ReplaceAll(gen.TypeString(t), "*", "_ptr")
+}
+
+// Returns the instance of the generic function named 'f' or 'module.f'
+// in the explicit instantiation, or nil.
+func (gen *generator) instanceOf(expr ast.Node) *checker.Func {
+	var sym checker.Symbol
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		sym = gen.SymbolOf(expr)
+
+	case *ast.Dot:
+		sym = gen.Uses[expr.Y]
+	}
+
+	if fn, _ := sym.(*checker.Func); fn != nil && fn.Generic() != nil {
+		return fn
+	}
+	return nil
+}
+
+func isMethod(sym checker.Symbol) bool {
+	fn, _ := sym.(*checker.Func)
+	return fn != nil && fn.MethodOf() != nil
+}
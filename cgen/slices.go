@@ -0,0 +1,125 @@
+package cgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/types"
+)
+
+func (gen *generator) sliceType(ty *types.Slice) string {
+	if s, ok := gen.sliceTypes[ty]; ok {
+		return s
+	}
+	if types.IsEnum(ty.ElemType()) {
+		// Elements are accessed via pointer, but the enum typedef
+		// is not forward declared.
+		gen.typeDeps(ty.ElemType())
+	}
+	elemTypeName := gen.TypeString(ty.ElemType())
+	typeName := strings.TrimRight(elemTypeName, "*") +
+		strings.Repeat("_ptr", strings.Count(elemTypeName, "*")) + "_slice"
+	alreadyDefined := false
+	for _, typeName0 := range gen.sliceTypes {
+		if typeName0 == typeName {
+			// Prevent similar typedefs.
+			alreadyDefined = true
+		}
+	}
+	if !alreadyDefined {
+		// The same slice type can be defined in headers of other modules.
+		gen.typeSect.WriteString(fmt.Sprintf(
+			"#ifndef JET_SLICE_%[2]s\n#define JET_SLICE_%[2]s\ntypedef struct { %[1]s* ptr; Ti32 len; } %[2]s;\n#endif\n",
+			elemTypeName,
+			typeName,
+		))
+	}
+	gen.sliceTypes[ty] = typeName
+	return typeName
+}
+
+// Generates the bounds checked access to the element of an array or
+// a slice. The program is aborted with the location of the expression
+// if the index is out of bounds.
+func (gen *generator) index(node *ast.Index) string {
+	index := gen.exprString(node.Args.Nodes[0])
+	loc := strconv.Quote(node.Pos().String())
+
+	switch ty := gen.TypeOf(node.X).Underlying().(type) {
+	case *types.Array:
+		return fmt.Sprintf(
+			"(%s[jet__check_index(%s, %d, %s)])",
+			gen.exprString(node.X),
+			index,
+			ty.Size(),
+			loc,
+		)
+
+	case *types.Slice:
+		slice := gen.operand(node.X, ty)
+		return fmt.Sprintf(
+			"(%[1]s.ptr[jet__check_index(%[2]s, %[1]s.len, %[3]s)])",
+			slice,
+			index,
+			loc,
+		)
+
+	case *types.Tuple:
+		return fmt.Sprintf("(%s._%s)", gen.exprString(node.X), index)
+
+	default:
+		return fmt.Sprintf("(%s[%s])", gen.exprString(node.X), index)
+	}
+}
+
+// Generates 'x[a..b]' and 'x[a..<b]' where 'x' is an array or a slice.
+func (gen *generator) sliceExpr(node *ast.Index, bounds *ast.Op) string {
+	ty := types.AsSlice(gen.TypeOf(node))
+	loc := strconv.Quote(node.Pos().String())
+
+	var ptr, length string
+
+	switch tyX := gen.TypeOf(node.X).Underlying().(type) {
+	case *types.Array:
+		ptr, length = gen.exprString(node.X), strconv.Itoa(tyX.Size())
+
+	case *types.Slice:
+		slice := gen.operand(node.X, tyX)
+		ptr, length = slice+".ptr", slice+".len"
+
+	default:
+		panic("unreachable")
+	}
+
+	lo := gen.tempVar(types.I32)
+	hi := gen.tempVar(types.I32)
+	gen.linef("%s = %s;\n", gen.name(lo), gen.exprString(bounds.X))
+
+	if bounds.Kind == ast.OperatorRangeInclusive {
+		gen.linef("%s = %s + 1;\n", gen.name(hi), gen.exprString(bounds.Y))
+	} else {
+		gen.linef("%s = %s;\n", gen.name(hi), gen.exprString(bounds.Y))
+	}
+
+	gen.linef("jet__check_slice(%s, %s, %s, %s);\n", gen.name(lo), gen.name(hi), length, loc)
+
+	tmp := gen.tempVar(ty)
+	gen.linef("%s.ptr = %s + %s;\n", gen.name(tmp), ptr, gen.name(lo))
+	gen.linef("%s.len = %s - %s;\n", gen.name(tmp), gen.name(hi), gen.name(lo))
+	return gen.name(tmp)
+}
+
+// Returns the expression of the operand that can be evaluated more
+// than once.
+func (gen *generator) operand(node ast.Node, ty types.Type) string {
+	switch node.(type) {
+	case *ast.Ident, *ast.Dot:
+		return gen.exprString(node)
+	}
+
+	tmp := gen.tempVar(ty)
+	gen.assign(gen.name(tmp), node)
+	return gen.name(tmp)
+}
@@ -2,37 +2,76 @@ package cgen
 
 import (
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/types"
 )
 
 func (gen *generator) stmt(stmt ast.Node) {
 	report.Debugf("stmt = %s", stmt.Repr())
+
+	if _, isEmpty := stmt.(*ast.Empty); !isEmpty {
+		gen.lineDirective(stmt)
+	}
+
 	switch stmt := stmt.(type) {
 	case *ast.Empty:
 		gen.line("\n")
 
 	case *ast.Decl:
-		if !stmt.IsVar {
-			break
-		}
 		sym, _ := gen.Defs.Get(stmt.Ident)
 		if sym == nil {
 			panic("unreachable")
 		}
-		gen.decl(sym)
+		v, _ := sym.(*checker.Var)
+		if v == nil {
+			// Constants are inlined.
+			break
+		}
+		gen.decl(v)
+
+	case *ast.TupleDecl:
+		gen.tupleDecl(stmt)
 
 	case *ast.While:
+		loop := gen.pushLoop(stmt.Label)
 		gen.linef("while (%s)\n", gen.exprString(stmt.Cond))
-		gen.block(stmt.Body.StmtList, nil)
+		gen.loopBody(loop, func() { gen.block(stmt.Body.StmtList, nil) })
 
 	case *ast.For:
+		if iterExpr, _ := stmt.IterExpr.(*ast.Op); iterExpr == nil ||
+			iterExpr.Kind != ast.OperatorRangeExclusive && iterExpr.Kind != ast.OperatorRangeInclusive {
+			gen.forIter(stmt)
+			break
+		}
+
+		loop := gen.pushLoop(stmt.Label)
 		loopVar := gen.SymbolOf(stmt.DeclList.Nodes[0].(*ast.Decl).Ident)
 		iterExpr := stmt.IterExpr.(*ast.Op)
 		cmpOp := ast.OperatorLt
 		if iterExpr.Kind == ast.OperatorRangeInclusive {
 			cmpOp = ast.OperatorLe
 		}
+		if v, _ := loopVar.(*checker.Var); v != nil && gen.isBoxed(v) {
+			// The loop variable captured by reference is allocated
+			// for each iteration, like in the interpreter.
+			counter, cmp := gen.tempVar(v.Type()), "<"
+			if cmpOp == ast.OperatorLe {
+				cmp = "<="
+			}
+			gen.linef(
+				"for (%[1]s=%[2]s; %[1]s%[3]s%[4]s; %[1]s+=1)\n",
+				gen.name(counter),
+				gen.exprString(iterExpr.X),
+				cmp,
+				gen.exprString(iterExpr.Y),
+			)
+			gen.loopBody(loop, func() {
+				gen.boxDecl(v, gen.name(counter))
+				gen.block(stmt.Body.StmtList, nil)
+			})
+			break
+		}
 		gen.linef(
 			"for (%[1]s %[2]s=%[3]s; %[4]s; %[2]s+=1)\n",
 			gen.TypeString(loopVar.Type()),
@@ -40,7 +79,10 @@ func (gen *generator) stmt(stmt ast.Node) {
 			gen.exprString(iterExpr.X),
 			gen.binary(loopVar.Ident(), iterExpr.Y, types.Bool, cmpOp),
 		)
-		gen.block(stmt.Body.StmtList, nil)
+		gen.loopBody(loop, func() { gen.block(stmt.Body.StmtList, nil) })
+
+	case *ast.Match:
+		gen.match(stmt, nil)
 
 	case *ast.If:
 		gen.ifExpr(stmt, nil)
@@ -60,10 +102,13 @@ func (gen *generator) stmt(stmt ast.Node) {
 		gen.block(stmt.StmtList, nil)
 
 	case *ast.Break:
-		gen.line("break;\n")
+		gen.loopExit(stmt.Label, true)
 
 	case *ast.Continue:
-		gen.line("continue;\n")
+		gen.loopExit(stmt.Label, false)
+
+	case *ast.Return:
+		gen.returnStmt(stmt)
 
 	default:
 		expr := gen.exprString(stmt)
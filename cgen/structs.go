@@ -1,6 +1,7 @@
 package cgen
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/saffage/jet/ast"
@@ -9,18 +10,50 @@ import (
 )
 
 func (gen *generator) structDecl(sym *checker.Struct) {
-	buf := strings.Builder{}
+	if gen.typeDecls[sym] {
+		return
+	}
+
+	gen.typeDecls[sym] = true
 	ty := types.AsStruct(types.SkipTypeDesc(sym.Type()))
-	gen.flinef(&buf, "typedef struct %s {\n", sym.Name())
+
+	// Every struct is declared before the definitions, so structs
+	// can refer to each other by pointers.
+	gen.forwardTypeSect.WriteString(fmt.Sprintf("typedef struct %[1]s %[1]s;\n", gen.name(sym)))
+
+	// Types stored by value must be defined first.
+	for _, field := range ty.Fields() {
+		gen.typeDeps(field.Type)
+	}
+
+	buf := strings.Builder{}
+	gen.flinef(&buf, "struct %s {\n", gen.name(sym))
 	gen.indent++
 	for _, field := range ty.Fields() {
 		gen.flinef(&buf, "%s %s;\n", gen.TypeString(field.Type), field.Name)
 	}
 	gen.indent--
-	gen.flinef(&buf, "} %s;\n", gen.name(sym))
+	gen.fline(&buf, "};\n")
 	gen.typeSect.WriteString(buf.String())
 }
 
+// Generates structs and enums of this module that the value of
+// type 't' contains.
+func (gen *generator) typeDeps(t types.Type) {
+	if array := types.AsArray(t); array != nil {
+		gen.typeDeps(array.ElemType())
+		return
+	}
+
+	switch sym := gen.TypeSyms[types.SkipAlias(t)].(type) {
+	case *checker.Struct:
+		gen.structDecl(sym)
+
+	case *checker.Enum:
+		gen.enumDecl(sym)
+	}
+}
+
 func (gen *generator) structInit(prefix string, value ast.Node, ty *types.Struct) {
 	if call, _ := value.(*ast.Call); call != nil {
 		gen.structInitList(prefix, call.Args.List, ty)
@@ -0,0 +1,108 @@
+package cgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/types"
+)
+
+// Returns the name of the struct generated for the tuple. Elements are
+// named by their index: '_0', '_1', etc.
+func (gen *generator) tupleType(ty *types.Tuple) string {
+	if s, ok := gen.tupleTypes[ty]; ok {
+		return s
+	}
+	elemTypeNames := make([]string, ty.Len())
+	for i, elem := range ty.Types() {
+		gen.typeDeps(elem)
+		elemTypeNames[i] = gen.TypeString(elem)
+	}
+	typeName := "tuple_" + strings.ReplaceAll(strings.Join(elemTypeNames, "_"), "*", "_ptr")
+	alreadyDefined := false
+	for _, typeName0 := range gen.tupleTypes {
+		if typeName0 == typeName {
+			// Prevent similar typedefs.
+			alreadyDefined = true
+		}
+	}
+	if !alreadyDefined {
+		// The same tuple type can be defined in headers of other modules.
+		buf := strings.Builder{}
+		for i, elemTypeName := range elemTypeNames {
+			buf.WriteString(fmt.Sprintf(" %s _%d;", elemTypeName, i))
+		}
+		gen.typeSect.WriteString(fmt.Sprintf(
+			"#ifndef JET_TUPLE_%[1]s\n#define JET_TUPLE_%[1]s\ntypedef struct {%[2]s } %[1]s;\n#endif\n",
+			typeName,
+			buf.String(),
+		))
+	}
+	gen.tupleTypes[ty] = typeName
+	return typeName
+}
+
+// Assigns elements of the tuple one by one, so they are converted to
+// the element types of the destination. Tuples of different element
+// types are different C structs, for example '(i32, bool)' of the
+// literal '(1, true)' and '(i64, bool)' of the destination.
+func (gen *generator) tupleAssign(dest string, value ast.Node) {
+	if list, _ := value.(*ast.ParenList); list != nil {
+		for i, elem := range list.Nodes {
+			gen.assign(fmt.Sprintf("%s._%d", dest, i), elem)
+		}
+		return
+	}
+
+	ty := types.AsTuple(types.SkipUntyped(gen.TypeOf(value)))
+	src := gen.exprString(value)
+
+	if _, isIdent := value.(*ast.Ident); !isIdent {
+		tmp := gen.tempVar(ty)
+		gen.linef("%s = %s;\n", gen.name(tmp), src)
+		src = gen.name(tmp)
+	}
+
+	gen.tupleCopy(dest, src, ty)
+}
+
+func (gen *generator) tupleCopy(dest, src string, ty *types.Tuple) {
+	for i, elem := range ty.Types() {
+		destElem := fmt.Sprintf("%s._%d", dest, i)
+		srcElem := fmt.Sprintf("%s._%d", src, i)
+
+		if tuple := types.AsTuple(elem); tuple != nil {
+			gen.tupleCopy(destElem, srcElem, tuple)
+		} else if types.IsArray(elem) {
+			gen.linef("memcpy((void*)%s, (const void*)%s, sizeof(%s));\n", destElem, srcElem, gen.TypeString(elem))
+		} else {
+			gen.linef("%s = %s;\n", destElem, srcElem)
+		}
+	}
+}
+
+// Generates '(a, b) := expr'. The tuple is stored in the temporary
+// variable, then each name is declared with the value of its element.
+func (gen *generator) tupleDecl(node *ast.TupleDecl) {
+	tmp := gen.tempVar(types.SkipUntyped(gen.TypeOf(node.Value)))
+	gen.tupleAssign(gen.name(tmp), node.Value)
+
+	for i, name := range node.Names.Nodes {
+		sym, _ := gen.Defs.Get(name.(*ast.Ident))
+		if sym == nil {
+			// Skipped with '_'.
+			continue
+		}
+
+		v := sym.(*checker.Var)
+		value := fmt.Sprintf("%s._%d", gen.name(tmp), i)
+
+		if gen.isBoxed(v) {
+			gen.boxDecl(v, value)
+		} else {
+			gen.linef("%s %s = %s;\n", gen.TypeString(v.Type()), gen.name(v), value)
+		}
+	}
+}
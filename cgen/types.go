@@ -2,6 +2,7 @@ package cgen
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/elliotchance/orderedmap/v2"
 	"github.com/saffage/jet/ast"
@@ -14,8 +15,6 @@ const (
 	_ErrorMetaType = "ERROR_CGEN__META_TYPE"
 )
 
-var arrayTypes = map[types.Type]string{}
-
 func (gen *generator) TypeString(ty types.Type) string {
 	if ty == nil {
 		panic("can't generate a type string for the nil type")
@@ -84,18 +83,21 @@ func (gen *generator) TypeString(ty types.Type) string {
 		}
 
 	case *types.Func:
-		panic("not implemented")
+		return gen.funcType(ty)
 
 	case *types.Tuple:
 		if ty.Equals(types.Unit) {
 			return "void"
 		}
 
-		panic("not implemented")
+		return gen.tupleType(ty)
 
 	case *types.Array:
 		return gen.arrayType(ty)
 
+	case *types.Slice:
+		return gen.sliceType(ty)
+
 	case *types.Ref:
 		return gen.TypeString(ty.Base()) + "*"
 
@@ -114,24 +116,29 @@ func (gen *generator) TypeString(ty types.Type) string {
 }
 
 func (gen *generator) arrayType(ty *types.Array) string {
-	if s, ok := arrayTypes[ty]; ok {
+	if s, ok := gen.arrayTypes[ty]; ok {
 		return s
 	}
 	elemTypeName := gen.TypeString(ty.ElemType())
-	typeName := fmt.Sprintf("%s_array%d", elemTypeName, ty.Size())
+	typeName := fmt.Sprintf("%s_array%d", strings.TrimRight(elemTypeName, "*")+
+		strings.Repeat("_ptr", strings.Count(elemTypeName, "*")), ty.Size())
 	alreadyDefined := false
-	for _, typeName0 := range arrayTypes {
+	for _, typeName0 := range gen.arrayTypes {
 		if typeName0 == typeName {
 			// Prevent similar typedefs.
 			alreadyDefined = true
 		}
 	}
 	if !alreadyDefined {
-		gen.typeSect.WriteString(
-			fmt.Sprintf("typedef %s %s[%d];\n", elemTypeName, typeName, ty.Size()),
-		)
+		// The same array type can be defined in headers of other modules.
+		gen.typeSect.WriteString(fmt.Sprintf(
+			"#ifndef JET_ARRAY_%[2]s\n#define JET_ARRAY_%[2]s\ntypedef %[1]s %[2]s[%[3]d];\n#endif\n",
+			elemTypeName,
+			typeName,
+			ty.Size(),
+		))
 	}
-	arrayTypes[ty] = typeName
+	gen.arrayTypes[ty] = typeName
 	return typeName
 }
 
@@ -14,6 +14,7 @@ func (gen *generator) globalVarDecl(sym *checker.Var) {
 	}
 	t := gen.TypeString(sym.Type())
 	gen.declVarsSect.WriteString(fmt.Sprintf("%s %s;\n", t, gen.name(sym)))
+	gen.externVarsSect.WriteString(fmt.Sprintf("extern %s %s;\n", t, gen.name(sym)))
 }
 
 func (gen *generator) varDecl(sym *checker.Var) string {
@@ -49,9 +50,20 @@ func (gen *generator) resultVar(ty types.Type) *checker.Var {
 	return sym
 }
 
+// Generates the module initialization function. It initializes imported
+// modules first, then global variables of the module. Each module is
+// initialized only once.
 func (gen *generator) initFunc() {
-	gen.linef("void init%s(void)\n{\n", gen.Module.Name())
+	gen.flinef(&gen.declFnsSect, "void init%s(void);\n", ModuleName(gen.Module))
+	gen.linef("void init%s(void)\n{\n", ModuleName(gen.Module))
 	gen.indent++
+	gen.line("static Tbool initialized = 0;\n")
+	gen.line("if (initialized) return;\n")
+	gen.line("initialized = 1;\n")
+
+	for _, imported := range gen.Imports {
+		gen.linef("init%s();\n", ModuleName(imported))
+	}
 
 	for def := gen.Defs.Front(); def != nil; def = def.Next() {
 		def := def.Value
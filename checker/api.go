@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/fatih/color"
@@ -17,27 +18,95 @@ import (
 
 var ErrorEmptyFileBuf = errors.New("empty file buffer or invalid file ID")
 
-func Check(cfg *config.Config, fileID config.FileID, stmts *ast.StmtList) (*Module, error) {
-	moduleName := cfg.Files[fileID].Name
-	report.Hintf("checking module '%s'", moduleName)
+// Options of the check, they are shared by the module and its imports.
+type Options struct {
+	// Evaluates the compile-time expressions. If it is nil, expressions
+	// that must be evaluated at compile time are reported as errors.
+	Evaluator Evaluator
+
+	// Reports whether the imported module at the absolute path is
+	// unchanged since it was checked without errors, for example by a
+	// previous build. Bodies of the functions of unchanged modules with
+	// the declared result type are checked only if they refer to generic
+	// declarations or when the code of the module is evaluated at compile
+	// time. If it is nil, all modules are checked.
+	Unchanged func(path string, source []byte) bool
+}
+
+func Check(cfg *config.Config, fileID config.FileID, stmts *ast.StmtList, opts Options) (*Module, error) {
+	return newImporter(opts).check(cfg, fileID, stmts, nil, nil)
+}
 
-	module := NewModule(NewScope(Global, "module "+moduleName), moduleName, stmts)
+func CheckFile(cfg *config.Config, fileID config.FileID, opts Options) (*Module, error) {
+	return newImporter(opts).checkFile(cfg, fileID, nil)
+}
+
+// Checks the module, 'node' is the import of the module or nil if the
+// module is not imported. 'parseErr' contains the syntax errors of the
+// module, the code replaced with [ast.BadNode] is skipped.
+func (imp *importer) check(
+	cfg *config.Config,
+	fileID config.FileID,
+	stmts *ast.StmtList,
+	node *ast.Import,
+	parseErr error,
+) (*Module, error) {
+	// The name of an imported module is qualified with its packages.
+	qualifiedName := cfg.Files[fileID].Name
+	moduleName := qualifiedName[strings.LastIndexByte(qualifiedName, '.')+1:]
+	report.Hintf("checking module '%s'", qualifiedName)
+
+	path := absPath(cfg.Files[fileID].Path)
+	imp.stack = append(imp.stack, importFrame{path, qualifiedName, node})
+	defer func() { imp.stack = imp.stack[:len(imp.stack)-1] }()
+
+	module := NewModule(NewScope(Global, "module "+qualifiedName), moduleName, stmts)
+	module.qualifiedName = qualifiedName
+	module.fileID = fileID
 	check := &Checker{
-		module: module,
-		scope:  module.Scope,
-		errors: make([]error, 0),
-		cfg:    cfg,
-		fileID: fileID,
+		module:   module,
+		scope:    module.Scope,
+		errors:   make([]error, 0),
+		importer: imp,
+		cfg:      cfg,
+		fileID:   fileID,
+	}
+
+	if node != nil && imp.opts.Unchanged != nil && imp.opts.Unchanged(path, cfg.Files[fileID].Buf.Bytes()) {
+		report.TaggedDebugf("checker", "module '%s' is unchanged, function bodies are skipped", qualifiedName)
+		check.skipBodies = true
+	}
+
+	// Syntax errors are reported before the errors of the checker.
+	if parseErr != nil {
+		check.addError(parseErr)
 	}
 
+	if path != "" {
+		imp.modules[path] = module
+	}
+
+	check.declareTypes(stmts)
 	visitor := ast.Visitor(check.visit)
 
 	for _, node := range stmts.Nodes {
 		visitor.WalkTopDown(node)
 	}
 
+	if check.skipBodies {
+		imp.checkSkippedGenerics(check)
+	}
+
 	module.completed = true
 
+	// The linter needs the checked bodies, warnings of the unchanged
+	// module were reported when it was checked.
+	if len(check.errors) == 0 && !check.skipBodies {
+		for _, warning := range Lint(module) {
+			warning.Report()
+		}
+	}
+
 	if cfg.Flags.DumpCheckerState {
 		err := os.Mkdir(cfg.Options.CacheDir, os.ModePerm)
 		if err != nil && !os.IsExist(err) {
@@ -57,7 +126,7 @@ func Check(cfg *config.Config, fileID config.FileID, stmts *ast.StmtList) (*Modu
 	return check.module, errors.Join(check.errors...)
 }
 
-func CheckFile(cfg *config.Config, fileID config.FileID) (*Module, error) {
+func (imp *importer) checkFile(cfg *config.Config, fileID config.FileID, node *ast.Import) (*Module, error) {
 	scannerFlags := scanner.SkipWhitespace | scanner.SkipComments
 	parserFlags := parser.DefaultFlags
 
@@ -75,21 +144,28 @@ func CheckFile(cfg *config.Config, fileID config.FileID) (*Module, error) {
 		return nil, err
 	}
 
+	// The parser replaces the invalid code with [ast.BadNode], so the
+	// rest of the file is checked even if there are syntax errors.
 	stmts, err := parser.Parse(tokens, parserFlags)
-	if err != nil {
-		return nil, err
-	}
 	if stmts == nil {
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			// The imported module must be defined even if it is empty.
+			return imp.check(cfg, fileID, &ast.StmtList{}, node, nil)
+		}
+
 		// Empty file, nothing to check.
 		return NewModule(NewScope(nil, "module "+fi.Name), fi.Name, nil), nil
 	}
 
 	if cfg.Flags.ParseAst {
 		printRecreatedAST(stmts)
-		return NewModule(NewScope(nil, "module "+fi.Name), fi.Name, nil), nil
+		return NewModule(NewScope(nil, "module "+fi.Name), fi.Name, nil), err
 	}
 
-	return Check(cfg, fileID, stmts)
+	return imp.check(cfg, fileID, stmts, node, err)
 }
 
 func printRecreatedAST(nodeList *ast.StmtList) {
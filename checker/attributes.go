@@ -3,6 +3,7 @@ package checker
 import (
 	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/types"
 )
 
@@ -43,7 +44,7 @@ func (check *Checker) resolveFuncAttrs(sym *Func) {
 			attrIdent, _ := attr.X.(*ast.Ident)
 
 			if attrIdent == nil {
-				check.errorf(attr.X, "expected identifier")
+				check.errorf(attr.X, diag.InvalidDecl, "expected identifier")
 				continue
 			}
 
@@ -54,8 +55,11 @@ func (check *Checker) resolveFuncAttrs(sym *Func) {
 			case "header":
 				// Unchecked attribute
 
+			case "allow":
+				// Checked by the linter.
+
 			default:
-				check.errorf(attrIdent, "unknown attribute")
+				check.errorf(attrIdent, diag.InvalidAttribute, "unknown attribute")
 			}
 
 		case *ast.Ident:
@@ -64,12 +68,12 @@ func (check *Checker) resolveFuncAttrs(sym *Func) {
 				check.attrExternC(sym, attr)
 
 			default:
-				check.errorf(attr, "unknown attribute")
+				check.errorf(attr, diag.InvalidAttribute, "unknown attribute")
 			}
 
 		default:
 			check.errorf(
-				attr,
+				attr, diag.InvalidAttribute,
 				"Ill-formed AST: unexpected node type %T is attribute list",
 				attr,
 			)
@@ -80,20 +84,20 @@ func (check *Checker) resolveFuncAttrs(sym *Func) {
 	if sym.isExtern {
 		if sym.body != nil {
 			check.errorf(
-				sym.decl.Ident,
+				sym.decl.Ident, diag.InvalidFunction,
 				"functions with @[extern_c] attribute must have no definition",
 			)
 		}
 	} else {
 		if sym.body == nil {
 			check.errorf(
-				sym.decl.Ident,
+				sym.decl.Ident, diag.InvalidFunction,
 				"functions without body is not allowed",
 			)
 		}
 		if sym.ty.Variadic() != nil {
 			check.errorf(
-				sym.decl.Ident,
+				sym.decl.Ident, diag.InvalidFunction,
 				"only a function with the attribute @[extern_c] can be variadic",
 			)
 		}
@@ -129,7 +133,7 @@ func (check *Checker) attrExternC(sym Symbol, node ast.Node) {
 			if idx < len(node.Args.Nodes) {
 				n = node.Args.Nodes[idx]
 			}
-			check.errorf(n, err.Error())
+			check.errorf(n, argsErrorCode(err), err.Error())
 			return
 		}
 
@@ -143,7 +147,7 @@ func (check *Checker) attrExternC(sym Symbol, node ast.Node) {
 
 	default:
 		check.errorf(
-			sym.Ident(),
+			sym.Ident(), diag.InvalidAttribute,
 			"expected function for @[extern_c] attribute",
 		)
 	}
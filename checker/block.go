@@ -4,6 +4,7 @@ import (
 	"unicode"
 
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/types"
 )
 
@@ -18,9 +19,14 @@ func NewBlock(scope *Scope) *Block {
 
 func (check *Checker) visitBlock(expr *Block) ast.Visitor {
 	return func(node ast.Node) ast.Visitor {
+		if check.skipBadStmt(node) {
+			expr.t = nil
+			return nil
+		}
+
 		if decl, _ := node.(*ast.Decl); decl != nil {
 			if unicode.IsUpper([]rune(decl.Ident.Name)[0]) || FindAttr(decl.Attrs, "comptime") != nil {
-				check.errorf(decl, "local constants are not supported")
+				check.errorf(decl, diag.InvalidDecl, "local constants are not supported")
 				return nil
 			}
 
@@ -29,12 +35,15 @@ func (check *Checker) visitBlock(expr *Block) ast.Visitor {
 			return nil
 		}
 
-		t := check.typeOf(node)
-		if t == nil {
+		if decl, _ := node.(*ast.TupleDecl); decl != nil {
+			check.resolveTupleDecl(decl)
+			expr.t = types.Unit
 			return nil
 		}
 
-		expr.t = t
+		// The type of the block is unknown if its last statement has
+		// an error.
+		expr.t = check.typeOf(node)
 		return nil
 	}
 }
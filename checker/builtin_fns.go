@@ -3,6 +3,7 @@ package checker
 import (
 	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/types"
 )
 
@@ -56,7 +57,7 @@ func builtInBuiltin(node *ast.ParenList, args []*TypedValue) (*TypedValue, error
 		return &TypedValue{types.NewTypeDesc(types.String), nil}, nil
 
 	default:
-		return nil, newErrorf(node.Nodes[0], "unknown built-in '%s'", *strval)
+		return nil, newErrorf(node.Nodes[0], diag.UnknownBuiltIn, "unknown built-in '%s'", *strval)
 	}
 }
 
@@ -13,8 +13,14 @@ type Checker struct {
 	scope  *Scope
 	errors []error
 
-	cfg    *config.Config
-	fileID config.FileID
+	lambdas []*Lambda // Lambdas being checked, the innermost is the last.
+	loops   []loop    // Loops being checked, the innermost is the last.
+	funcs   []*funcContext
+
+	importer   *importer
+	cfg        *config.Config
+	fileID     config.FileID
+	skipBodies bool // Whether the function bodies are skipped, see [Options.Unchanged].
 }
 
 // Type checks 'expr' and returns its type.
@@ -118,4 +124,8 @@ func (check *Checker) newUse(ident *ast.Ident, sym Symbol) {
 		sym.Ident(),
 	)
 	check.module.Uses[ident] = sym
+
+	if v, _ := sym.(*Var); v != nil {
+		check.capture(v)
+	}
 }
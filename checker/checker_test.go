@@ -0,0 +1,69 @@
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/report"
+)
+
+// Checks the source as the main module '<name>.jet' in the directory
+// 'dir', or in a temporary directory if it is empty. Imported modules
+// are searched in the same directory. Returns the module, the messages
+// reported during the check (including the errors of the check) and
+// the error of the check.
+func testCheck(t *testing.T, dir, name, input string, opts Options) (*Module, []report.Diagnostic, error) {
+	t.Helper()
+
+	libDir, err := filepath.Abs("../lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dir == "" {
+		dir = t.TempDir()
+	}
+
+	cfg := &config.Config{
+		Files: map[config.FileID]config.FileInfo{
+			config.MainFileID: {
+				Name: name,
+				Path: filepath.Join(dir, name+".jet"),
+				Buf:  bytes.NewBufferString(input),
+			},
+		},
+		Options: config.Options{CoreLibPath: libDir},
+	}
+	config.Global = cfg
+
+	diagnostics := []report.Diagnostic{}
+	report.Handler = func(d report.Diagnostic) {
+		if d.Kind >= report.KindWarning {
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	defer func() { report.Handler = nil }()
+
+	if err := CheckBuiltInPkgs(cfg); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	m, err := CheckFile(cfg, config.MainFileID, opts)
+	if err != nil {
+		report.Errors(err)
+	}
+
+	return m, diagnostics, err
+}
+
+// Formats the messages as 'line:char: code message'.
+func diagnosticStrings(diagnostics []report.Diagnostic) []string {
+	strs := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		strs[i] = fmt.Sprintf("%d:%d: %s %s", d.Start.Line, d.Start.Char, d.Code, d.Message)
+	}
+	return strs
+}
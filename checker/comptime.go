@@ -0,0 +1,55 @@
+package checker
+
+import (
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// Evaluator executes the type checked expression of the module at
+// compile time, so the values of constants can be computed by ordinary
+// functions:
+//
+//	@[comptime]
+//	TABLE := build_table()
+//
+// The expression is evaluated by the interpreter of the package 'interp'
+// ([interp.Comptime]), which is passed in [Options], the checker cannot
+// import it itself. Errors of the evaluation are returned as [*Error].
+type Evaluator func(m *Module, expr ast.Node) (constant.Value, error)
+
+// Evaluates the type checked expression at compile time and records its
+// value. Reports an error and returns nil if the expression cannot be
+// evaluated.
+func (check *Checker) comptimeValueOf(expr ast.Node, ty types.Type) *TypedValue {
+	evaluator := check.importer.opts.Evaluator
+	if evaluator == nil {
+		check.errorf(expr, diag.NotConstant, "compile-time evaluation is not available")
+		return nil
+	}
+
+	// The expression can call functions of the unchanged modules.
+	check.importer.checkSkipped()
+	value, err := evaluator(check.module, expr)
+	if err != nil {
+		evalErr, _ := err.(*Error)
+		if evalErr == nil {
+			evalErr = newErrorf(expr, diag.ComptimeFailed, "%s", err)
+		}
+		evalErr.Notes = append(evalErr.Notes, &Error{
+			Message: "while evaluating the expression at compile time",
+			Node:    expr,
+		})
+		check.addError(evalErr)
+		return nil
+	}
+	if value == nil {
+		check.errorf(expr, diag.NotConstant, "value is not a constant expression")
+		return nil
+	}
+
+	tv := &TypedValue{Type: types.SkipUntyped(ty), Value: value}
+	check.setValue(expr, tv)
+	return tv
+}
@@ -3,6 +3,7 @@ package checker
 import (
 	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/types"
 )
@@ -31,7 +32,7 @@ func (v *Const) Node() ast.Node        { return nil }
 func (check *Checker) resolveConstDecl(decl *ast.Decl) {
 	value := check.valueOf(decl.Value)
 	if value == nil {
-		check.errorf(decl.Value, "value is not a constant expression")
+		check.errorf(decl.Value, diag.NotConstant, "value is not a constant expression")
 		return
 	}
 
@@ -48,7 +49,7 @@ func (check *Checker) resolveConstDecl(decl *ast.Decl) {
 
 	if value.Type != nil && !value.Type.Equals(tType) {
 		check.errorf(
-			decl.Ident,
+			decl.Ident, diag.TypeMismatch,
 			"type mismatch, expected '%s', got '%s'",
 			tType,
 			value.Type,
@@ -4,6 +4,7 @@ import (
 	"unicode"
 
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/types"
 )
 
@@ -12,7 +13,10 @@ func (check *Checker) resolveDecl(decl *ast.Decl) {
 	case decl.Ident.Name == "_":
 		// TODO do not introduce a new symbol and do
 		// type checking instead of the error
-		check.errorf(decl.Ident, "attempt to declare an empty identifier")
+		check.errorf(decl.Ident, diag.InvalidDecl, "attempt to declare an empty identifier")
+
+	case typeParamsOf(decl.Value) != nil:
+		check.resolveGenericDecl(decl, typeParamsOf(decl.Value))
 
 	case unicode.IsUpper([]rune(decl.Ident.Name)[0]), FindAttr(decl.Attrs, "comptime") != nil:
 		switch expr := decl.Value.(type) {
@@ -34,8 +38,8 @@ func (check *Checker) resolveDecl(decl *ast.Decl) {
 				if ty := check.typeOf(expr); ty != nil &&
 					(types.IsTypeDesc(ty) || ty.Equals(types.Unit)) {
 					check.resolveTypeAliasDecl(decl)
-				} else {
-					check.errorf(expr, "value is not a constant expression")
+				} else if ty != nil && check.comptimeValueOf(expr, ty) != nil {
+					check.resolveConstDecl(decl)
 				}
 			} else if value.Value == nil {
 				check.resolveTypeAliasDecl(decl)
@@ -45,7 +49,7 @@ func (check *Checker) resolveDecl(decl *ast.Decl) {
 		}
 
 	case decl.Mut.IsValid():
-		check.errorf(decl.Ident, "mutable compile-time variables are not supported")
+		check.errorf(decl.Ident, diag.InvalidDecl, "mutable compile-time variables are not supported")
 
 	default:
 		if fn, _ := decl.Value.(*ast.Function); fn != nil {
@@ -61,3 +65,29 @@ func (check *Checker) resolveDecl(decl *ast.Decl) {
 		}
 	}
 }
+
+// Declares structs and enums of the module before other declarations
+// are resolved, so they can refer to each other regardless of the
+// declaration order.
+func (check *Checker) declareTypes(stmts *ast.StmtList) {
+	for _, node := range stmts.Nodes {
+		decl, _ := node.(*ast.Decl)
+		if decl == nil || decl.Ident.Name == "_" || hasBadNode(decl) {
+			continue
+		}
+
+		if !unicode.IsUpper([]rune(decl.Ident.Name)[0]) && FindAttr(decl.Attrs, "comptime") == nil {
+			continue
+		}
+
+		switch value := decl.Value.(type) {
+		case *ast.StructType:
+			if value.TypeParams == nil {
+				check.declareStruct(decl)
+			}
+
+		case *ast.EnumType:
+			check.declareEnum(decl, value)
+		}
+	}
+}
@@ -4,14 +4,16 @@ import (
 	"slices"
 
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/types"
 )
 
 type Enum struct {
-	owner *Scope
-	body  *Scope
-	t     *types.TypeDesc
-	decl  *ast.Decl
+	owner   *Scope
+	body    *Scope
+	methods *Scope
+	t       *types.TypeDesc
+	decl    *ast.Decl
 }
 
 func NewEnum(owner *Scope, body *Scope, t *types.TypeDesc, decl *ast.Decl) *Enum {
@@ -21,7 +23,7 @@ func NewEnum(owner *Scope, body *Scope, t *types.TypeDesc, decl *ast.Decl) *Enum
 	if body.Parent() != owner {
 		panic("invalid local scope parent")
 	}
-	return &Enum{owner, body, t, decl}
+	return &Enum{owner, body, NewScope(owner, "methods "+decl.Ident.Name), t, decl}
 }
 
 func (sym *Enum) Owner() *Scope     { return sym.owner }
@@ -29,29 +31,156 @@ func (sym *Enum) Type() types.Type  { return sym.t }
 func (sym *Enum) Name() string      { return sym.decl.Ident.Name }
 func (sym *Enum) Ident() *ast.Ident { return sym.decl.Ident }
 func (sym *Enum) Node() ast.Node    { return sym.decl }
+func (sym *Enum) Methods() *Scope   { return sym.methods }
+
+// Declares the enum before payloads of its variants are resolved, so
+// the enum can be referred by payloads. Returns nil if the name is
+// already defined.
+func (check *Checker) declareEnum(decl *ast.Decl, value *ast.EnumType) *Enum {
+	if check.scope.LookupLocal(decl.Ident.Name) != nil {
+		return nil
+	}
+
+	fields := make([]string, 0, len(value.Variants))
+
+	for _, variant := range value.Variants {
+		fields = append(fields, variant.Ident.Name)
+	}
+
+	local := NewScope(check.scope, "enum "+decl.Ident.Name)
+	sym := NewEnum(check.scope, local, types.NewTypeDesc(types.NewEnum(fields...)), decl)
+	check.scope.Define(sym)
+	check.module.TypeSyms[types.SkipTypeDesc(sym.Type())] = sym
+	return sym
+}
 
 func (check *Checker) resolveEnumDecl(decl *ast.Decl, value *ast.EnumType) {
-	bodyScope := NewScope(check.scope, "enum "+decl.Ident.Name)
-	fields := make([]string, 0, len(value.Fields))
+	sym, _ := check.scope.LookupLocal(decl.Ident.Name).(*Enum)
 
-	for _, ident := range value.Fields {
-		fields = append(fields, ident.Name)
+	if sym == nil || sym.decl != decl {
+		if sym = check.declareEnum(decl, value); sym == nil {
+			defined := check.scope.LookupLocal(decl.Ident.Name)
+			check.addError(errorAlreadyDefined(decl.Ident, defined.Ident()))
+			return
+		}
 	}
 
-	ty := types.NewTypeDesc(types.NewEnum(fields...))
-	sym := NewEnum(check.scope, bodyScope, ty, decl)
+	t := types.AsEnum(types.SkipTypeDesc(sym.Type()))
+	payloads := make([]*types.Struct, len(value.Variants))
+
+	for i, variant := range value.Variants {
+		if j := slices.IndexFunc(value.Variants[:i], func(prev *ast.Variant) bool {
+			return prev.Ident.Name == variant.Ident.Name
+		}); j != -1 {
+			err := newErrorf(variant.Ident, diag.AlreadyDefined, "duplicate variant '%s'", variant.Ident.Name)
+			err.Notes = append(err.Notes, &Error{
+				Message: "variant was defined here",
+				Node:    value.Variants[j].Ident,
+			})
+			check.addError(err)
+			return
+		}
 
-	if defined := check.scope.Define(sym); defined != nil {
-		check.addError(errorAlreadyDefined(sym.Ident(), defined.Ident()))
-		return
+		if variant.Fields != nil {
+			if payloads[i] = check.resolvePayload(variant, t); payloads[i] == nil {
+				return
+			}
+		}
 	}
+
+	t.SetPayloads(payloads...)
 	check.newDef(decl.Ident, sym)
+	check.resolveMethods(sym, sym.methods, value.Methods)
+}
+
+// Resolves fields of the variant as a struct.
+func (check *Checker) resolvePayload(variant *ast.Variant, t *types.Enum) *types.Struct {
+	if len(variant.Fields.Nodes) == 0 {
+		check.errorf(variant.Fields, diag.InvalidVariant, "expected at least 1 field in the payload")
+		return nil
+	}
+
+	fields := make([]types.StructField, 0, len(variant.Fields.Nodes))
+
+	for _, node := range variant.Fields.Nodes {
+		field, _ := node.(*ast.Decl)
+		if field == nil || field.Type == nil || field.Value != nil {
+			check.errorf(node, diag.InvalidDecl, "expected field declaration in the form 'name: T'")
+			return nil
+		}
+
+		tField := check.typeOf(field.Type)
+		if tField == nil {
+			return nil
+		}
+
+		if !types.IsTypeDesc(tField) {
+			check.errorf(field.Type, diag.TypeMismatch, "expected field type, got (%s) instead", tField)
+			return nil
+		}
+
+		tBase := types.AsTypeDesc(tField).Base()
+
+		if containsType(tBase, t) {
+			check.errorf(
+				field.Type, diag.RecursiveType,
+				"invalid recursive type, field '%s' must be a pointer",
+				field.Ident.Name,
+			)
+			return nil
+		}
+
+		if slices.ContainsFunc(fields, func(prev types.StructField) bool {
+			return prev.Name == field.Ident.Name
+		}) {
+			check.errorf(field.Ident, diag.AlreadyDefined, "duplicate field '%s'", field.Ident.Name)
+			return nil
+		}
+
+		fields = append(fields, types.StructField{Name: field.Ident.Name, Type: tBase})
+	}
+
+	return types.NewStruct(fields...)
 }
 
 func (check *Checker) enumMember(node *ast.Dot, t *types.Enum) types.Type {
 	idx := slices.Index(t.Fields(), node.Y.Name)
 	if idx == -1 {
-		check.errorf(node.Y, "type has no member named '%s'", node.Y.Name)
+		err := newErrorf(node.Y, diag.UnknownMember, "type has no member named '%s'", node.Y.Name)
+		err.suggestName(node.Y, t.Fields())
+		check.addError(err)
+	} else if t.Payload(node.Y.Name) != nil {
+		check.errorf(node.Y, diag.InvalidVariant, "variant '%s' has a payload and must be initialized", node.Y.Name)
+		return nil
 	}
 	return t
 }
+
+// Type checks 'T.variant(...fields)', where 'T' is an enum. Reports
+// false if the call is not a variant initialization.
+func (check *Checker) typeOfVariantInit(node *ast.Call, dot *ast.Dot) (types.Type, bool) {
+	t := check.typeOf(dot.X)
+	if t == nil {
+		return nil, true
+	}
+
+	typedesc := types.AsTypeDesc(t)
+	if typedesc == nil {
+		return nil, false
+	}
+
+	tEnum := types.AsEnum(typedesc.Base())
+	if tEnum == nil || !slices.Contains(tEnum.Fields(), dot.Y.Name) {
+		return nil, false
+	}
+
+	payload := tEnum.Payload(dot.Y.Name)
+	if payload == nil {
+		check.errorf(node.Args, diag.InvalidVariant, "variant '%s' has no payload", dot.Y.Name)
+		return nil, true
+	}
+
+	check.structInit(node.Args, payload)
+	check.setType(dot, tEnum)
+	return tEnum, true
+}
@@ -1,23 +1,29 @@
 package checker
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
-	"github.com/saffage/jet/token"
+	"github.com/saffage/jet/types"
 )
 
 type Error struct {
 	Message string
 	Node    ast.Node
-	Notes   []*Error // TODO make a distinct type for the notes.
+	Code    diag.Code // Empty for the notes.
+	Notes   []*Error  // TODO make a distinct type for the notes.
+	Fixes   []*Fix
 }
 
-func newErrorf(node ast.Node, format string, args ...any) *Error {
+func newErrorf(node ast.Node, code diag.Code, format string, args ...any) *Error {
 	return &Error{
 		Message: fmt.Sprintf(format, args...),
 		Node:    node,
+		Code:    code,
 	}
 }
 
@@ -26,26 +32,82 @@ func (err *Error) Error() string {
 }
 
 func (err *Error) Report() {
-	var start, end token.Pos
+	report.Emit(err.diagnostic(report.KindError))
+}
+
+func (err *Error) diagnostic(kind report.Kind) report.Diagnostic {
+	d := report.Diagnostic{
+		Kind:    kind,
+		Tag:     "checker",
+		Message: err.Message,
+		Code:    string(err.Code),
+	}
 	if err.Node != nil {
-		start, end = err.Node.Pos(), err.Node.PosEnd()
+		d.Start, d.End = err.Node.Pos(), err.Node.PosEnd()
 	}
-	report.TaggedErrorAt("checker", start, end, err.Message)
 
 	for _, note := range err.Notes {
-		var start, end token.Pos
-		if note.Node != nil {
-			start, end = note.Node.Pos(), note.Node.PosEnd()
-		}
-		report.TaggedNoteAt("checker", start, end, note.Message)
+		d.Notes = append(d.Notes, note.diagnostic(report.KindNote))
+	}
+
+	for _, fix := range err.Fixes {
+		d.Fixes = append(d.Fixes, report.Fix{
+			Message: fix.Message,
+			Start:   fix.Node.Pos(),
+			End:     fix.Node.PosEnd(),
+			NewText: fix.NewText,
+		})
 	}
+
+	return d
 }
 
-func (check *Checker) errorf(node ast.Node, format string, args ...any) {
-	err := newErrorf(node, format, args...)
+func (check *Checker) errorf(node ast.Node, code diag.Code, format string, args ...any) {
+	err := newErrorf(node, code, format, args...)
 	check.addError(err)
 }
 
+// Returns the code of the error returned by [types.Func.CheckArgs].
+func argsErrorCode(err error) diag.Code {
+	if errors.Is(err, types.ErrTooManyArgs) || errors.Is(err, types.ErrNotEnoughArgs) {
+		return diag.ArgumentCount
+	}
+	return diag.TypeMismatch
+}
+
+// Returns the error of [types.Func.CheckArgs] for the arguments of the
+// call. If the argument has a wrong numeric type, the conversion is
+// suggested.
+func newArgsError(args *ast.ParenList, fn *types.Func, tArgs *types.Tuple, idx int, err error) *Error {
+	node := ast.Node(args)
+	if idx < len(args.Nodes) {
+		node = args.Nodes[idx]
+	}
+
+	argsErr := newErrorf(node, argsErrorCode(err), "%s", err.Error())
+
+	if argsErr.Code == diag.TypeMismatch && idx < tArgs.Len() {
+		expected := fn.Variadic()
+		if idx < fn.Params().Len() {
+			expected = fn.Params().Types()[idx]
+		}
+		argsErr.suggestCast(node, tArgs.Types()[idx], expected)
+	}
+
+	return argsErr
+}
+
 func (check *Checker) addError(err error) {
+	// Some expressions are checked more than once (for example, the
+	// arguments of the built-in functions), their errors are the same.
+	if err, _ := err.(*Error); err != nil && slices.ContainsFunc(check.errors, err.sameAs) {
+		return
+	}
+
 	check.errors = append(check.errors, err)
 }
+
+func (err *Error) sameAs(other error) bool {
+	otherErr, _ := other.(*Error)
+	return otherErr != nil && otherErr.Node == err.Node && otherErr.Message == err.Message
+}
@@ -0,0 +1,151 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/types"
+)
+
+// Fix is a suggested change of the code attached to the [Error]: the
+// node is replaced with the new text.
+type Fix struct {
+	Message string
+	Node    ast.Node
+	NewText string
+}
+
+func (err *Error) addFix(node ast.Node, newText, format string, args ...any) {
+	err.Fixes = append(err.Fixes, &Fix{
+		Message: fmt.Sprintf(format, args...),
+		Node:    node,
+		NewText: newText,
+	})
+}
+
+// Suggests the closest of the names to replace the identifier.
+func (err *Error) suggestName(ident *ast.Ident, names []string) {
+	if name := closestName(ident.Name, names); name != "" {
+		err.addFix(ident, name, "did you mean '%s'?", name)
+	}
+}
+
+// Suggests to convert the value to the expected type with 'as'. Only
+// numbers can be converted. Aliases are replaced with the actual type,
+// because they may be not visible where the value is (for example, type
+// parameters of the generic function).
+func (err *Error) suggestCast(value ast.Node, from, to types.Type) {
+	if !types.IsNumeric(from) || !types.IsNumeric(to) || types.IsUntyped(to) {
+		return
+	}
+
+	to = types.SkipAlias(to)
+
+	text := value.Repr()
+	if _, isOp := value.(*ast.Op); isOp {
+		text = "(" + text + ")"
+	}
+
+	err.addFix(value, fmt.Sprintf("%s as %s", text, to), "convert the value to '%s'", to)
+}
+
+// Suggests to declare the variable with 'mut'.
+func (err *Error) suggestMut(v *Var) {
+	if decl, _ := v.Node().(*ast.Decl); decl != nil && !decl.Mut.IsValid() {
+		err.addFix(decl.Ident, "mut "+decl.Ident.Name, "declare the variable with 'mut'")
+	}
+}
+
+// Returns the names of the symbols defined in the scope.
+func localNames(scope *Scope) []string {
+	names := []string{}
+	for _, sym := range scope.Symbols() {
+		names = append(names, sym.Name())
+	}
+	return names
+}
+
+// Returns the names of the symbols visible in the scope.
+func visibleNames(scope *Scope) []string {
+	names := []string{}
+	for ; scope != nil; scope = scope.parent {
+		names = append(names, localNames(scope)...)
+	}
+	return names
+}
+
+// Returns the names of the methods of the type.
+func (check *Checker) methodNames(t types.Type) []string {
+	switch sym := check.module.TypeSyms[types.SkipAlias(t)].(type) {
+	case *Struct:
+		return localNames(sym.methods)
+
+	case *Enum:
+		return localNames(sym.methods)
+	}
+	return nil
+}
+
+// Returns the names of the fields and the methods of the struct type.
+func (check *Checker) memberNames(t *types.Struct) []string {
+	names := []string{}
+	for _, field := range t.Fields() {
+		names = append(names, field.Name)
+	}
+	return append(names, check.methodNames(t)...)
+}
+
+// Returns the name that is the closest to the specified name by the
+// edit distance, or an empty string if every name is too different.
+// A third of the name can be misspelled, so nothing is suggested for
+// names shorter than 3 characters.
+func closestName(name string, names []string) string {
+	closest, best := "", len(name)/3+1
+
+	for _, candidate := range names {
+		if candidate == name || candidate == "_" {
+			continue
+		}
+
+		if distance := editDistance(name, candidate); distance < best {
+			closest, best = candidate, distance
+		}
+	}
+
+	return closest
+}
+
+// Returns the edit distance between the strings, where an insertion,
+// a deletion, a substitution and a transposition of two adjacent
+// characters are single edits.
+func editDistance(a, b string) int {
+	// Rows of the distances for the prefixes of 'a' of the length i-2,
+	// i-1 and i.
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(b)]
+}
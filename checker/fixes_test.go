@@ -0,0 +1,23 @@
+package checker
+
+import "testing"
+
+func TestClosestName(t *testing.T) {
+	names := []string{"count", "len", "println", "x", "_"}
+
+	for name, expected := range map[string]string{
+		"cuont":   "count",
+		"coutn":   "count",
+		"lne":     "len",
+		"printn":  "println",
+		"y":       "",
+		"xy":      "",
+		"counter": "count",
+		"abc":     "",
+		"count":   "",
+	} {
+		if actual := closestName(name, names); actual != expected {
+			t.Errorf("closestName(%q): expected %q, got %q", name, expected, actual)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/types"
 )
@@ -17,22 +18,28 @@ type Func struct {
 	body       ast.Node
 	isExtern   bool
 	externName string
+	generic    *Generic     // Declaration from which the function was instantiated.
+	typeArgs   []types.Type // Type arguments of the instance.
+	methodOf   Symbol       // Struct or enum in which the method is declared.
 }
 
 func NewFunc(owner *Scope, local *Scope, t *types.Func, decl *ast.Decl) *Func {
-	return &Func{owner, local, nil, t, decl, nil, false, ""}
+	return &Func{owner, local, nil, t, decl, nil, false, "", nil, nil, nil}
 }
 
-func (sym *Func) Owner() *Scope        { return sym.owner }
-func (sym *Func) Type() types.Type     { return sym.ty }
-func (sym *Func) Name() string         { return sym.decl.Ident.Name }
-func (sym *Func) Ident() *ast.Ident    { return sym.decl.Ident }
-func (sym *Func) Node() ast.Node       { return sym.decl }
-func (sym *Func) Local() *Scope        { return sym.local }
-func (sym *Func) Params() []*Var       { return sym.params }
-func (sym *Func) IsExtern() bool       { return sym.isExtern }
-func (sym *Func) ExternName() string   { return sym.externName }
-func (sym *Func) Variadic() types.Type { return sym.ty.Variadic() }
+func (sym *Func) Owner() *Scope          { return sym.owner }
+func (sym *Func) Type() types.Type       { return sym.ty }
+func (sym *Func) Name() string           { return sym.decl.Ident.Name }
+func (sym *Func) Ident() *ast.Ident      { return sym.decl.Ident }
+func (sym *Func) Node() ast.Node         { return sym.decl }
+func (sym *Func) Local() *Scope          { return sym.local }
+func (sym *Func) Params() []*Var         { return sym.params }
+func (sym *Func) IsExtern() bool         { return sym.isExtern }
+func (sym *Func) ExternName() string     { return sym.externName }
+func (sym *Func) Variadic() types.Type   { return sym.ty.Variadic() }
+func (sym *Func) Generic() *Generic      { return sym.generic }
+func (sym *Func) TypeArgs() []types.Type { return sym.typeArgs }
+func (sym *Func) MethodOf() Symbol       { return sym.methodOf }
 
 func (check *Checker) resolveFuncDecl(decl *ast.Decl, value *ast.Function) {
 	var (
@@ -53,7 +60,12 @@ func (check *Checker) resolveFuncDecl(decl *ast.Decl, value *ast.Function) {
 		}
 	}
 
-	sym.ty = check.resolveFuncBody(decl, value.Body, ty, local, hasResult)
+	if check.skipBodies && hasResult && value.Body != nil {
+		check.skipBody(decl, value.Body, ty, local)
+	} else {
+		sym.ty = check.resolveFuncBody(decl, value.Body, ty, local, hasResult)
+	}
+
 	if sym.ty == nil {
 		// TODO error message?
 		sym.ty = ty
@@ -93,7 +105,7 @@ func (check *Checker) resolveFuncSignature(
 		}
 		if param.Value != nil {
 			check.errorf(
-				param,
+				param, diag.UnsupportedFeature,
 				"parameters with a default value is not supported",
 			)
 			wasError = true
@@ -112,6 +124,9 @@ func (check *Checker) resolveFuncSignature(
 			}
 			variadic = types.SkipTypeDesc(variadic)
 		} else if tyParam = check.typeOf(param.Type); tyParam == nil {
+			// The type error is already reported, uses of the parameter
+			// must not be reported as undefined.
+			scope.markInvalid(param.Ident.Name)
 			wasError = true
 			continue
 		}
@@ -119,7 +134,7 @@ func (check *Checker) resolveFuncSignature(
 		if variadic != nil {
 			if i != len(sig.Params.Nodes)-1 {
 				check.errorf(
-					param.Ident,
+					param.Ident, diag.InvalidFunction,
 					"parameter with ... can only be the last in the list",
 				)
 				wasError = true
@@ -139,11 +154,11 @@ func (check *Checker) resolveFuncSignature(
 
 		if defined := scope.Define(paramSym); defined != nil {
 			check.errorf(
-				param,
+				param, diag.AlreadyDefined,
 				"parameter with the same name was already defined",
 			)
 			wasError = true
-			return
+			continue
 		}
 
 		params = append(params, paramSym)
@@ -166,6 +181,62 @@ func (check *Checker) resolveFuncSignature(
 	return
 }
 
+// Function body which check is deferred, see [Options.Unchanged].
+type skippedBody struct {
+	check *Checker
+	decl  *ast.Decl
+	body  ast.Node
+	ty    *types.Func
+	scope *Scope
+}
+
+// Defers the check of the function body until the code of the module is
+// evaluated at compile time. The body of the unchanged module has no
+// errors, so the function type is known from its signature.
+func (check *Checker) skipBody(decl *ast.Decl, body ast.Node, tyFunc *types.Func, scope *Scope) {
+	check.importer.skipped = append(check.importer.skipped, &skippedBody{check, decl, body, tyFunc, scope})
+}
+
+// Checks the skipped body. Errors found after the module check is
+// finished are reported immediately.
+func (b *skippedBody) resolve() {
+	errorsLenBefore := len(b.check.errors)
+	b.check.resolveFuncBody(b.decl, b.body, b.ty, b.scope, true)
+
+	if b.check.module.completed && len(b.check.errors) > errorsLenBefore {
+		report.Errors(b.check.errors[errorsLenBefore:]...)
+	}
+}
+
+// Reports whether the body can refer to a generic declaration. Instances
+// are generated in the module that requires them first, so such bodies
+// are not skipped to keep the instances in the same modules.
+func (b *skippedBody) mayInstantiate() bool {
+	found := false
+	scopes := []*Scope{b.check.module.Scope}
+	for _, m := range b.check.module.Imports {
+		scopes = append(scopes, m.Scope)
+	}
+
+	var visit ast.Visitor
+	visit = func(node ast.Node) ast.Visitor {
+		if ident, _ := node.(*ast.Ident); ident != nil {
+			for _, scope := range scopes {
+				if _, isGeneric := scope.LookupLocal(ident.Name).(*Generic); isGeneric {
+					found = true
+				}
+			}
+		}
+		if found {
+			return nil
+		}
+		return visit
+	}
+
+	visit.WalkTopDown(b.body)
+	return found
+}
+
 func (check *Checker) resolveFuncBody(
 	decl *ast.Decl,
 	body ast.Node,
@@ -175,7 +246,7 @@ func (check *Checker) resolveFuncBody(
 ) *types.Func {
 	if body == nil {
 		if !hasResult {
-			check.errorf(decl.Ident, "cannot infer a type of the function result")
+			check.errorf(decl.Ident, diag.NoType, "cannot infer a type of the function result")
 		}
 		return nil
 	}
@@ -186,6 +257,14 @@ func (check *Checker) resolveFuncBody(
 	defer check.setScope(check.scope)
 	check.scope = scope
 
+	fn := &funcContext{}
+	if hasResult {
+		fn.result = tyFunc.Result()
+	}
+
+	check.funcs = append(check.funcs, fn)
+	defer func() { check.funcs = check.funcs[:len(check.funcs)-1] }()
+
 	// For a note about recursion
 	errorsLenBefore := len(check.errors)
 
@@ -214,10 +293,11 @@ func (check *Checker) resolveFuncBody(
 	}
 
 	if !hasResult {
+		check.checkInferredReturns(fn, tyBody)
 		return types.NewFunc(tyFunc.Params(), tyBody, tyFunc.Variadic())
 	}
 
-	if !tyBody.Equals(tyFunc.Result()) {
+	if !endsWithReturn(body) && !tyBody.Equals(tyFunc.Result()) {
 		var resultNode ast.Node
 
 		if list, _ := body.(*ast.CurlyList); list != nil {
@@ -231,7 +311,7 @@ func (check *Checker) resolveFuncBody(
 		}
 
 		check.errorf(
-			resultNode,
+			resultNode, diag.TypeMismatch,
 			"expected expression of type '%s' for function result, got '%s' instead",
 			tyFunc.Result(),
 			tyBody,
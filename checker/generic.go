@@ -0,0 +1,367 @@
+package checker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// Generic is a function or a struct declared with type parameters.
+//
+// The declaration itself is not checked. Instead, every instantiation
+// checks a copy of the declaration in a separate scope, where the type
+// parameters are aliases to the type arguments. The instantiated
+// symbol is a regular [Func] or [Struct].
+type Generic struct {
+	owner     *Scope
+	decl      *ast.Decl
+	params    []*ast.Ident
+	instances []*instance
+}
+
+type instance struct {
+	args  []types.Type
+	scope *Scope // Contains type parameters and the instantiated symbol.
+}
+
+func (sym *Generic) Owner() *Scope     { return sym.owner }
+func (sym *Generic) Type() types.Type  { return nil }
+func (sym *Generic) Name() string      { return sym.decl.Ident.Name }
+func (sym *Generic) Ident() *ast.Ident { return sym.decl.Ident }
+func (sym *Generic) Node() ast.Node    { return sym.decl }
+
+func (sym *Generic) TypeParams() []*ast.Ident { return sym.params }
+
+// Returns type parameters of the function or struct, or nil if the
+// expression is not generic.
+func typeParamsOf(expr ast.Node) *ast.BracketList {
+	switch expr := expr.(type) {
+	case *ast.Function:
+		return expr.TypeParams
+
+	case *ast.Signature:
+		return expr.TypeParams
+
+	case *ast.StructType:
+		return expr.TypeParams
+	}
+
+	return nil
+}
+
+func (check *Checker) resolveGenericDecl(decl *ast.Decl, typeParams *ast.BracketList) {
+	if _, isSignature := decl.Value.(*ast.Signature); isSignature || decl.Value == nil {
+		check.errorf(typeParams, diag.InvalidGeneric, "generic function must have a body")
+		return
+	}
+
+	if len(typeParams.Nodes) == 0 {
+		check.errorf(typeParams, diag.InvalidGeneric, "expected at least 1 type parameter")
+		return
+	}
+
+	params := make([]*ast.Ident, 0, len(typeParams.Nodes))
+
+	for _, node := range typeParams.Nodes {
+		param, _ := node.(*ast.Ident)
+		if param == nil {
+			check.errorf(node, diag.InvalidDecl, "expected identifier for a type parameter")
+			return
+		}
+
+		if i := slices.IndexFunc(params, func(ident *ast.Ident) bool {
+			return ident.Name == param.Name
+		}); i != -1 {
+			check.addError(errorAlreadyDefined(param, params[i]))
+			return
+		}
+
+		params = append(params, param)
+	}
+
+	sym := &Generic{
+		owner:  check.scope,
+		decl:   decl,
+		params: params,
+	}
+
+	if defined := check.scope.Define(sym); defined != nil {
+		check.addError(errorAlreadyDefined(sym.Ident(), defined.Ident()))
+		return
+	}
+
+	check.newDef(decl.Ident, sym)
+}
+
+// Returns the generic declaration if the expression refers to it or
+// to one of its instances, the declaration can be qualified with the
+// name of the imported module. Otherwise, returns nil.
+func (check *Checker) genericOf(expr ast.Node) *Generic {
+	var sym Symbol
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		sym = check.symbolOf(expr)
+
+	case *ast.Dot:
+		ident, _ := expr.X.(*ast.Ident)
+		if ident == nil {
+			return nil
+		}
+
+		m, _ := check.symbolOf(ident).(*Module)
+		if m == nil {
+			return nil
+		}
+
+		if sym = m.Scope.LookupLocal(expr.Y.Name); sym != nil {
+			check.newUse(ident, m)
+		}
+	}
+
+	switch sym := sym.(type) {
+	case *Generic:
+		return sym
+
+	case *Func:
+		return sym.generic
+
+	case *Struct:
+		return sym.generic
+	}
+
+	return nil
+}
+
+// Returns the identifier that names the generic declaration in the
+// expression accepted by [Checker.genericOf].
+func genericIdent(expr ast.Node) *ast.Ident {
+	if dot, _ := expr.(*ast.Dot); dot != nil {
+		return dot.Y
+	}
+	return expr.(*ast.Ident)
+}
+
+// Returns the instance of the generic declaration for the specified type
+// arguments. The declaration is checked only once for the same types.
+func (check *Checker) instantiate(sym *Generic, node ast.Node, args []types.Type) Symbol {
+	assert(len(args) == len(sym.params))
+
+	name := instanceName(sym, args)
+
+	for _, inst := range sym.instances {
+		if slices.EqualFunc(inst.args, args, types.Type.Equals) {
+			if instSym := inst.scope.LookupLocal(sym.Name()); instSym != nil {
+				return instSym
+			}
+
+			check.errorf(node, diag.InvalidGeneric, "cannot infer a type of the recursive instantiation '%s'", name)
+			return nil
+		}
+	}
+
+	scope := NewScope(sym.owner, "generic "+sym.Name())
+	sym.instances = append(sym.instances, &instance{args, scope})
+	decl := ast.Clone(sym.decl)
+
+	for i, node := range typeParamsOf(decl.Value).Nodes {
+		param := node.(*ast.Ident)
+		alias := NewTypeAlias(scope, types.NewTypeDesc(args[i]), &ast.Decl{
+			Ident: param,
+			Value: param,
+		})
+		scope.Define(alias)
+		check.newDef(param, alias)
+	}
+
+	defer check.setScope(check.scope)
+	check.scope = scope
+	errorsLenBefore := len(check.errors)
+
+	// Instances are generated in the modules that require them, so
+	// their bodies are always checked.
+	defer func(skipBodies bool) { check.skipBodies = skipBodies }(check.skipBodies)
+	check.skipBodies = false
+
+	switch value := decl.Value.(type) {
+	case *ast.Function:
+		check.resolveFuncDecl(decl, value)
+
+	case *ast.StructType:
+		// Methods of the struct can refer to the instance by the
+		// name of the generic.
+		if instSym := check.declareStruct(decl); instSym != nil {
+			instSym.generic, instSym.typeArgs = sym, args
+		}
+
+		check.resolveStructDecl(decl, value)
+
+	default:
+		panic("unreachable")
+	}
+
+	for _, err := range check.errors[errorsLenBefore:] {
+		if err, _ := err.(*Error); err != nil {
+			err.Notes = append(err.Notes, &Error{
+				Message: fmt.Sprintf("in the instance '%s' required here", name),
+				Node:    node,
+			})
+		}
+	}
+
+	switch instSym := scope.LookupLocal(sym.Name()).(type) {
+	case *Func:
+		instSym.generic, instSym.typeArgs = sym, args
+		return instSym
+
+	case *Struct:
+		instSym.generic, instSym.typeArgs = sym, args
+		return instSym
+	}
+
+	return nil
+}
+
+// Type checks 'x[...types]', where 'x' is a generic declaration.
+func (check *Checker) typeOfInstance(sym *Generic, node *ast.Index) types.Type {
+	if len(node.Args.Nodes) != len(sym.params) {
+		check.errorf(
+			node.Args, diag.ArgumentCount,
+			"expected %d type arguments for '%s', got %d",
+			len(sym.params),
+			sym.Name(),
+			len(node.Args.Nodes),
+		)
+		return nil
+	}
+
+	args := make([]types.Type, 0, len(node.Args.Nodes))
+
+	for _, arg := range node.Args.Nodes {
+		t := check.typeOf(arg)
+		if t == nil {
+			return nil
+		}
+
+		if !types.IsTypeDesc(t) {
+			check.errorf(arg, diag.ExpectedTypeExpr, "expected type, got value of type '%s' instead", t)
+			return nil
+		}
+
+		args = append(args, types.SkipTypeDesc(t))
+	}
+
+	instSym := check.instantiate(sym, node, args)
+	if instSym == nil {
+		return nil
+	}
+
+	check.newUse(genericIdent(node.X), instSym)
+	return instSym.Type()
+}
+
+// Infers type arguments of the generic function from the call arguments
+// and returns the instance.
+func (check *Checker) inferInstance(sym *Generic, node *ast.Call) Symbol {
+	fn, _ := sym.decl.Value.(*ast.Function)
+	if fn == nil {
+		check.errorf(node.X, diag.InvalidGeneric, "cannot infer type arguments of '%s', they must be specified explicitly", sym.Name())
+		return nil
+	}
+
+	tArgs := types.AsTuple(check.typeOfParenList(node.Args))
+	if tArgs == nil {
+		return nil
+	}
+
+	if tArgs.Len() != len(fn.Params.Nodes) {
+		check.errorf(node.Args, diag.ArgumentCount, "expected %d arguments, got %d", len(fn.Params.Nodes), tArgs.Len())
+		return nil
+	}
+
+	bindings := map[string]types.Type{}
+
+	for i, param := range fn.Params.Nodes {
+		if param, _ := param.(*ast.Decl); param != nil && param.Type != nil {
+			check.infer(sym, param.Type, types.SkipUntyped(tArgs.Types()[i]), bindings)
+		}
+	}
+
+	args := make([]types.Type, len(sym.params))
+
+	for i, param := range sym.params {
+		t, ok := bindings[param.Name]
+		if !ok {
+			check.errorf(node.X, diag.InvalidGeneric, "cannot infer the type parameter '%s' of '%s'", param.Name, sym.Name())
+			return nil
+		}
+
+		args[i] = t
+	}
+
+	instSym := check.instantiate(sym, node, args)
+	if instSym == nil {
+		return nil
+	}
+
+	check.newUse(genericIdent(node.X), instSym)
+	return instSym
+}
+
+// Binds type parameters used in the type expression to the parts of the
+// type 't'. The first binding of the parameter wins, mismatches are
+// reported later when the arguments are checked.
+func (check *Checker) infer(sym *Generic, expr ast.Node, t types.Type, bindings map[string]types.Type) {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if slices.ContainsFunc(sym.params, func(param *ast.Ident) bool {
+			return param.Name == expr.Name
+		}) {
+			if _, bound := bindings[expr.Name]; !bound {
+				bindings[expr.Name] = t
+			}
+		}
+
+	case *ast.Op:
+		if expr.Kind == ast.OperatorPtr || expr.Kind == ast.OperatorMutPtr {
+			if ref := types.AsRef(t); ref != nil {
+				check.infer(sym, expr.Y, ref.Base(), bindings)
+			}
+		}
+
+	case *ast.ArrayType:
+		if array := types.AsArray(t); array != nil {
+			check.infer(sym, expr.X, array.ElemType(), bindings)
+		}
+
+	case *ast.Index:
+		if structSym, _ := check.module.TypeSyms[types.SkipAlias(t)].(*Struct); structSym != nil &&
+			structSym.generic != nil &&
+			len(structSym.typeArgs) == len(expr.Args.Nodes) {
+			for i, arg := range expr.Args.Nodes {
+				check.infer(sym, arg, structSym.typeArgs[i], bindings)
+			}
+		}
+	}
+}
+
+func instanceName(sym *Generic, args []types.Type) string {
+	buf := strings.Builder{}
+	buf.WriteString(sym.Name())
+	buf.WriteByte('[')
+
+	for i, arg := range args {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+
+		buf.WriteString(arg.String())
+	}
+
+	buf.WriteByte(']')
+	return buf.String()
+}
@@ -0,0 +1,76 @@
+package checker
+
+import (
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// Arrays, slices and strings can be iterated by the 'for' loop. A struct
+// type is an iterator if it has the following methods:
+//
+//	next := (self: *T) -> bool // Advances the iterator, reports whether there is an element.
+//	value := (self: T) -> E    // Returns the current element, 'self' can also be '*T'.
+//
+// The loop copies the iterator and calls 'next' before each iteration.
+
+// Returns the methods of the iterator type, or nil if the type is not an
+// iterator. Reports an error if the methods have an invalid signature.
+func (check *Checker) iteratorMethods(node ast.Node, t types.Type) (next, value *Func) {
+	next, value = check.methodOf(t, "next"), check.methodOf(t, "value")
+	if next == nil || value == nil {
+		return nil, nil
+	}
+
+	tyNext := types.AsFunc(next.Type())
+	if tyNext.Params().Len() != 1 ||
+		!tyNext.Params().Types()[0].Equals(types.NewRef(t)) ||
+		!tyNext.Result().Equals(types.NewTuple(types.Bool)) {
+		check.errorf(node, diag.InvalidLoop, "method 'next' of the iterator '%s' must have the type '(*%[1]s) -> bool'", t)
+		return nil, nil
+	}
+
+	tyValue := types.AsFunc(value.Type())
+	if tyValue.Params().Len() != 1 ||
+		!tyValue.Params().Types()[0].Equals(t) && !tyValue.Params().Types()[0].Equals(types.NewRef(t)) ||
+		tyValue.Result().Len() != 1 {
+		check.errorf(node, diag.InvalidLoop, "method 'value' of the iterator '%s' must have the type '(%[1]s) -> E'", t)
+		return nil, nil
+	}
+
+	return next, value
+}
+
+// Returns the type of elements produced by iterating the expression.
+func (check *Checker) typeOfIterElem(node ast.Node) types.Type {
+	t := check.typeOf(node)
+	if t == nil {
+		return nil
+	}
+
+	if types.IsUntyped(t) {
+		t = types.SkipUntyped(t)
+		check.setType(node, t)
+	}
+
+	switch {
+	case types.IsArray(t):
+		return types.AsArray(t).ElemType()
+
+	case types.IsSlice(t):
+		return types.AsSlice(t).ElemType()
+
+	case t == types.String:
+		return types.U8
+
+	case types.IsStruct(t) && check.methodOf(t, "next") != nil && check.methodOf(t, "value") != nil:
+		_, value := check.iteratorMethods(node, t)
+		if value == nil {
+			return nil
+		}
+		return types.AsFunc(value.Type()).Result().Types()[0]
+	}
+
+	check.errorf(node, diag.InvalidLoop, "expression of type '%s' is not iterable", t)
+	return nil
+}
@@ -0,0 +1,146 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/report"
+	"github.com/saffage/jet/types"
+)
+
+// Lambda is an anonymous function used as an expression. Unlike
+// [Func], it can refer to local variables of the enclosing functions,
+// such variables are captured by the lambda.
+type Lambda struct {
+	owner    *Scope
+	local    *Scope
+	params   []*Var
+	ty       *types.Func
+	node     *ast.Function
+	captures []*Capture
+}
+
+// Capture is a variable of the enclosing function used in the lambda.
+// Mutable variables are captured by reference, so the lambda and the
+// enclosing function see the same variable. Other variables are copied
+// into the lambda environment when the lambda is created.
+type Capture struct {
+	Var   *Var
+	ByRef bool
+}
+
+func (l *Lambda) Owner() *Scope         { return l.owner }
+func (l *Lambda) Local() *Scope         { return l.local }
+func (l *Lambda) Params() []*Var        { return l.params }
+func (l *Lambda) Type() *types.Func     { return l.ty }
+func (l *Lambda) Node() *ast.Function   { return l.node }
+func (l *Lambda) Captures() []*Capture  { return l.captures }
+func (l *Lambda) Name() string          { return l.local.Name()[len("func "):] }
+func (l *Lambda) captureOf(v *Var) bool { return l.findCapture(v) != nil }
+
+func (l *Lambda) findCapture(v *Var) *Capture {
+	for _, capture := range l.captures {
+		if capture.Var == v {
+			return capture
+		}
+	}
+	return nil
+}
+
+// Reports whether the variable is declared outside of the lambda.
+func (l *Lambda) isOuter(v *Var) bool {
+	for scope := v.Owner(); scope != nil; scope = scope.Parent() {
+		if scope == l.local {
+			return false
+		}
+	}
+	return true
+}
+
+func (check *Checker) typeOfFunction(node *ast.Function) types.Type {
+	if lambda := check.module.Lambdas[node]; lambda != nil {
+		return lambda.ty
+	}
+
+	if node.Body == nil {
+		check.errorf(node, diag.InvalidFunction, "function literal must have a body")
+		return nil
+	}
+
+	var (
+		name                            = fmt.Sprintf("lambda__%d", len(check.module.Lambdas))
+		local                           = NewScope(check.scope, "func "+name)
+		ty, params, hasResult, wasError = check.resolveFuncSignature(node.Signature, local)
+	)
+	if wasError {
+		return nil
+	}
+
+	lambda := &Lambda{
+		owner:  check.scope,
+		local:  local,
+		params: params,
+		ty:     ty,
+		node:   node,
+	}
+	check.module.Lambdas[node] = lambda
+
+	// Loops of the enclosing function cannot be exited from the lambda.
+	loops := check.loops
+	check.loops = nil
+	check.lambdas = append(check.lambdas, lambda)
+
+	defer func() {
+		check.lambdas = check.lambdas[:len(check.lambdas)-1]
+		check.loops = loops
+	}()
+
+	decl := &ast.Decl{Ident: &ast.Ident{Name: name, Start: node.Pos(), End: node.Pos()}}
+	if tyFunc := check.resolveFuncBody(decl, node.Body, ty, local, hasResult); tyFunc != nil {
+		lambda.ty = tyFunc
+	} else {
+		return nil
+	}
+
+	report.TaggedDebugf("checker", "lambda: set type: %s", lambda.ty)
+	return lambda.ty
+}
+
+// Records the variable as captured by every lambda being checked that
+// is declared inside the scope of the variable.
+func (check *Checker) capture(v *Var) {
+	if v.IsGlobal() || v.IsField() {
+		return
+	}
+
+	for i := len(check.lambdas) - 1; i >= 0; i-- {
+		lambda := check.lambdas[i]
+		if !lambda.isOuter(v) {
+			break
+		}
+		if !lambda.captureOf(v) {
+			byRef := v.decl.Mut.IsValid()
+			lambda.captures = append(lambda.captures, &Capture{v, byRef})
+			report.TaggedDebugf("checker", "lambda: capture '%s' (by reference: %t)", v.Name(), byRef)
+		}
+	}
+}
+
+// Reports an error if the variable is captured by value by the lambda
+// being checked, because an assignment would only change the copy.
+func (check *Checker) checkCaptureAssign(node *ast.Ident, v *Var) {
+	if len(check.lambdas) == 0 {
+		return
+	}
+
+	if capture := check.lambdas[len(check.lambdas)-1].findCapture(v); capture != nil && !capture.ByRef {
+		err := newErrorf(node, diag.CapturedByValue, "cannot assign to '%s', it is captured by value", v.Name())
+		err.Notes = append(err.Notes, &Error{
+			Message: "declare the variable with 'mut' to capture it by reference",
+			Node:    v.Ident(),
+		})
+		err.suggestMut(v)
+		check.errors = append(check.errors, err)
+	}
+}
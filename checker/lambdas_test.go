@@ -0,0 +1,40 @@
+package checker
+
+import (
+	"slices"
+	"testing"
+)
+
+// Captured variables can be read, indexed and sliced by a lambda, only
+// the assignment to a variable captured by value is an error.
+func TestCaptures(t *testing.T) {
+	input := `main := () {
+	arr := [1, 2, 3]
+	get := (i: i32) -> i32 { arr[i] }
+	sum := () -> i32 {
+		mut total := 0
+		for x in arr[0..<2] { total += x }
+		total
+	}
+	n := 1
+	set := () { n = 2 }
+	mut m := 1
+	inc := () { m += 1 }
+	set()
+	inc()
+	$println(get(1) + sum() + m)
+}
+`
+	expected := []string{
+		"10:14: E0205 cannot assign to 'n', it is captured by value",
+	}
+
+	_, diagnostics, err := testCheck(t, "", "captures", input, Options{})
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+
+	if actual := diagnosticStrings(diagnostics); !slices.Equal(actual, expected) {
+		t.Errorf("unexpected diagnostics\nexpect: %q\nactual: %q", expected, actual)
+	}
+}
@@ -0,0 +1,239 @@
+package checker
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/report"
+	"github.com/saffage/jet/token"
+)
+
+// Names of the lints that can be silenced with the attribute
+// '@[allow(...)]'. The attribute silences the warnings about the
+// declaration and the code inside of it.
+const (
+	LintUnused      = "unused"
+	LintUnreachable = "unreachable"
+)
+
+var lints = []string{LintUnused, LintUnreachable}
+
+// Warning is reported by [Lint]. Unlike [Error], it does not prevent
+// the program from being compiled.
+type Warning struct {
+	Message string
+	Node    ast.Node
+	Code    diag.Code
+}
+
+func (w *Warning) Error() string {
+	return w.Message
+}
+
+func (w *Warning) Report() {
+	d := report.Diagnostic{
+		Kind:    report.KindWarning,
+		Tag:     "lint",
+		Message: w.Message,
+		Code:    string(w.Code),
+	}
+	if w.Node != nil {
+		d.Start, d.End = w.Node.Pos(), w.Node.PosEnd()
+	}
+	report.Emit(d)
+}
+
+// Returns the warnings about the module:
+//
+//   - unused local variables and parameters;
+//   - unused imports;
+//   - unused functions of the main module, except 'main', because no
+//     module imports the main module;
+//   - statements after 'return', 'break' or 'continue' in a block.
+//
+// The module must be checked without errors, otherwise the symbols
+// used in the erroneous code can be reported as unused.
+func Lint(m *Module) []*Warning {
+	l := &linter{
+		m:       m,
+		used:    map[*ast.Ident]bool{},
+		modules: map[*Module]bool{},
+		params:  map[*ast.Ident]bool{},
+	}
+
+	for _, sym := range m.Uses {
+		if module, _ := sym.(*Module); module != nil {
+			l.modules[module] = true
+		} else if ident := sym.Ident(); ident != nil {
+			// Instances of the generic functions have distinct symbols
+			// for the same declaration, so the identifiers are compared.
+			l.used[ident] = true
+		}
+	}
+
+	for def := m.Defs.Front(); def != nil; def = def.Next() {
+		if fn, _ := def.Value.(*Func); fn != nil && !fn.isExtern && fn.body != nil {
+			params := fn.params
+			if fn.methodOf != nil && len(params) > 0 {
+				// The receiver of the method.
+				params = params[1:]
+			}
+			l.addParams(params)
+		}
+	}
+
+	for _, lambda := range m.Lambdas {
+		l.addParams(lambda.params)
+	}
+
+	if m.stmts != nil {
+		ast.Visitor(l.visit(nil)).WalkTopDown(m.stmts)
+	}
+
+	slices.SortStableFunc(l.warnings, func(a, b *Warning) int {
+		return comparePos(a.Node.Pos(), b.Node.Pos())
+	})
+
+	return l.warnings
+}
+
+type linter struct {
+	m        *Module
+	used     map[*ast.Ident]bool // Declarations used in the module.
+	modules  map[*Module]bool    // Imported modules used in the module.
+	params   map[*ast.Ident]bool // Parameters of the functions with a body.
+	warnings []*Warning
+}
+
+func (l *linter) addParams(params []*Var) {
+	for _, param := range params {
+		l.params[param.Ident()] = true
+	}
+}
+
+func (l *linter) warnf(node ast.Node, code diag.Code, format string, args ...any) {
+	l.warnings = append(l.warnings, &Warning{
+		Message: fmt.Sprintf(format, args...),
+		Node:    node,
+		Code:    code,
+	})
+}
+
+// Returns the visitor that reports the warnings except the lints
+// from the 'allowed' list.
+func (l *linter) visit(allowed []string) ast.Visitor {
+	return func(node ast.Node) ast.Visitor {
+		switch node := node.(type) {
+		case *ast.Decl:
+			return l.visit(l.allow(allowed, node.Attrs))
+
+		case *ast.Import:
+			allowed := l.allow(allowed, node.Attrs)
+			m, _ := l.m.SymbolOf(node.Module()).(*Module)
+
+			if m != nil && !l.modules[m] && !slices.Contains(allowed, LintUnused) {
+				l.warnf(node, diag.Unused, "unused import '%s'", m.QualifiedName())
+			}
+
+			return nil
+
+		case *ast.Ident:
+			if !slices.Contains(allowed, LintUnused) {
+				l.checkUnused(node)
+			}
+
+		case *ast.CurlyList:
+			if !slices.Contains(allowed, LintUnreachable) {
+				l.checkUnreachable(node.StmtList)
+			}
+		}
+
+		return l.visit(allowed)
+	}
+}
+
+// Returns the list of lints allowed by the attribute '@[allow(...)]'
+// in addition to the 'allowed' lints.
+func (l *linter) allow(allowed []string, attrs *ast.AttributeList) []string {
+	attr, _ := FindAttr(attrs, "allow").(*ast.Call)
+	if attr == nil {
+		return allowed
+	}
+
+	names := make([]string, 0, len(attr.Args.Nodes))
+
+	for _, arg := range attr.Args.Nodes {
+		ident, _ := arg.(*ast.Ident)
+
+		switch {
+		case ident == nil:
+			l.warnf(arg, diag.UnknownLint, "expected the name of a lint")
+
+		case !slices.Contains(lints, ident.Name):
+			l.warnf(ident, diag.UnknownLint, "unknown lint '%s' (expected one of: %s)", ident.Name, strings.Join(lints, ", "))
+
+		default:
+			names = append(names, ident.Name)
+		}
+	}
+
+	return slices.Concat(allowed, names)
+}
+
+func (l *linter) checkUnused(ident *ast.Ident) {
+	sym, _ := l.m.Defs.Get(ident)
+	if sym == nil || l.used[ident] || ident.Name == "_" {
+		return
+	}
+
+	switch sym := sym.(type) {
+	case *Var:
+		if sym.IsLocal() {
+			l.warnf(ident, diag.Unused, "unused variable '%s'", ident.Name)
+		} else if sym.IsParam() && l.params[ident] {
+			l.warnf(ident, diag.Unused, "unused parameter '%s'", ident.Name)
+		}
+
+	case *Func:
+		if l.m.fileID == config.MainFileID && sym.methodOf == nil && !sym.isExtern && ident.Name != "main" {
+			l.warnf(ident, diag.Unused, "unused function '%s'", ident.Name)
+		}
+	}
+}
+
+// Reports the first statement after 'return', 'break' or 'continue'.
+func (l *linter) checkUnreachable(list *ast.StmtList) {
+	if list == nil {
+		return
+	}
+
+	terminated := false
+
+	for _, node := range list.Nodes {
+		if _, isEmpty := node.(*ast.Empty); isEmpty {
+			continue
+		}
+
+		if terminated {
+			l.warnf(node, diag.Unreachable, "unreachable code")
+			return
+		}
+
+		switch node.(type) {
+		case *ast.Return, *ast.Break, *ast.Continue:
+			terminated = true
+		}
+	}
+}
+
+func comparePos(a, b token.Pos) int {
+	if a.Line != b.Line {
+		return cmp.Compare(a.Line, b.Line)
+	}
+	return cmp.Compare(a.Char, b.Char)
+}
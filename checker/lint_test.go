@@ -0,0 +1,84 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/saffage/jet/report"
+)
+
+func TestLint(t *testing.T) {
+	input := `@[extern_c]
+exit: (code: i32) -> ()
+
+helper := () -> i32 { 1 }
+
+@[allow(unused)]
+silenced := () -> i32 { 2 }
+
+f := (a: i32, b: i32) -> i32 {
+	x := 1
+	@[allow(unused)] y := 2
+	return b
+	b
+}
+
+S := struct {
+	v: i32
+	get := (self: S, n: i32) -> i32 { self.v }
+}
+
+@[allow(unreachable, bogus)]
+g := () -> i32 {
+	for i in 0..<3 {
+		break
+		$println(i)
+	}
+	return 1
+	2
+}
+
+main := () {
+	l := (p: i32) -> i32 { 3 }
+	$println(f(1, 2) + g() + l(1) + S(v = 1).get(2))
+}
+
+import util
+`
+	expected := []string{
+		"4:1: unused function 'helper'",
+		"9:7: unused parameter 'a'",
+		"10:2: unused variable 'x'",
+		"13:2: unreachable code",
+		"18:19: unused parameter 'n'",
+		"21:22: unknown lint 'bogus' (expected one of: unused, unreachable)",
+		"32:8: unused parameter 'p'",
+		"36:1: unused import 'util'",
+	}
+
+	// The imported module is placed next to the main file. Its unused
+	// functions are not reported, they can be used by other modules.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "util.jet"), []byte("x := 1\nhelper := () -> i32 { x }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diagnostics, err := testCheck(t, dir, "lint", input, Options{})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	warnings := []string{}
+	for _, d := range diagnostics {
+		if d.Kind == report.KindWarning && d.Tag == "lint" {
+			warnings = append(warnings, fmt.Sprintf("%d:%d: %s", d.Start.Line, d.Start.Char, d.Message))
+		}
+	}
+
+	if !slices.Equal(warnings, expected) {
+		t.Errorf("unexpected warnings\nexpect: %q\nactual: %q", expected, warnings)
+	}
+}
@@ -0,0 +1,61 @@
+package checker
+
+import (
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// A 'while' or 'for' loop being checked.
+type loop struct {
+	node  ast.Node
+	label *ast.Ident // optional
+}
+
+func (check *Checker) pushLoop(node ast.Node, label *ast.Ident) {
+	if label != nil {
+		for _, outer := range check.loops {
+			if outer.label != nil && outer.label.Name == label.Name {
+				err := newErrorf(label, diag.InvalidLabel, "label '%s' is already used by an enclosing loop", label.Name)
+				err.Notes = append(err.Notes, &Error{
+					Message: "enclosing loop is labeled here",
+					Node:    outer.label,
+				})
+				check.addError(err)
+				break
+			}
+		}
+	}
+
+	check.loops = append(check.loops, loop{node, label})
+}
+
+func (check *Checker) popLoop() {
+	check.loops = check.loops[:len(check.loops)-1]
+}
+
+// Checks that 'break' or 'continue' refers to the enclosing loop. The
+// label selects the loop, otherwise the innermost loop is used.
+func (check *Checker) checkLoopExit(node ast.Node, keyword string, label *ast.Ident) types.Type {
+	if len(check.loops) == 0 {
+		if len(check.funcs) > 0 && check.funcs[len(check.funcs)-1].defers > 0 {
+			check.errorf(node, diag.InvalidLoop, "'%s' cannot exit a deferred statement", keyword)
+		} else {
+			check.errorf(node, diag.InvalidLoop, "'%s' outside of a loop", keyword)
+		}
+		return types.Unit
+	}
+
+	if label == nil {
+		return types.Unit
+	}
+
+	for i := len(check.loops) - 1; i >= 0; i-- {
+		if outer := check.loops[i].label; outer != nil && outer.Name == label.Name {
+			return types.Unit
+		}
+	}
+
+	check.errorf(label, diag.InvalidLabel, "label '%s' does not refer to an enclosing loop", label.Name)
+	return types.Unit
+}
@@ -0,0 +1,150 @@
+package checker
+
+import (
+	"slices"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// Resolves functions declared in the body of the struct or enum.
+//
+// Methods are defined in the separate scope, so the method body
+// cannot refer to fields without the receiver. A method whose first
+// parameter has the type 'T' or '*T' can be called as 'x.method(...)',
+// any method can be called as 'T.method(x, ...)'.
+func (check *Checker) resolveMethods(sym Symbol, scope *Scope, methods []*ast.Decl) {
+	defer check.setScope(check.scope)
+	check.scope = scope
+
+	for _, decl := range methods {
+		fn, _ := decl.Value.(*ast.Function)
+		if fn == nil {
+			check.errorf(decl.Value, diag.InvalidMethod, "expected a function declaration for the method '%s'", decl.Ident.Name)
+			continue
+		}
+
+		if fn.TypeParams != nil {
+			check.errorf(fn.TypeParams, diag.InvalidGeneric, "methods cannot have type parameters")
+			continue
+		}
+
+		switch sym := sym.(type) {
+		case *Struct:
+			if field := sym.body.LookupLocal(decl.Ident.Name); field != nil {
+				check.addError(errorAlreadyDefined(decl.Ident, field.Ident()))
+				continue
+			}
+
+		case *Enum:
+			if slices.Contains(types.AsEnum(types.SkipTypeDesc(sym.t)).Fields(), decl.Ident.Name) {
+				check.errorf(decl.Ident, diag.AlreadyDefined, "enum already has a field named '%s'", decl.Ident.Name)
+				continue
+			}
+		}
+
+		check.resolveFuncDecl(decl, fn)
+
+		if method, _ := scope.LookupLocal(decl.Ident.Name).(*Func); method != nil && method.decl == decl {
+			method.methodOf = sym
+		}
+	}
+}
+
+func (check *Checker) methodOf(t types.Type, name string) *Func {
+	return check.module.MethodOf(t, name)
+}
+
+// Type checks 'x.method(...args)' where 'x' is a value. The receiver is
+// passed as the first argument, its address is taken or it is
+// dereferenced to match the type of the first parameter. Reports false
+// if the call is not a method call.
+func (check *Checker) typeOfMethodCall(node *ast.Call, dot *ast.Dot) (types.Type, bool) {
+	tRecv := check.typeOf(dot.X)
+	if tRecv == nil {
+		return nil, true
+	}
+
+	if types.IsTypeDesc(tRecv) {
+		return nil, false
+	}
+
+	tBase := tRecv
+	if ref := types.AsRef(tRecv); ref != nil {
+		tBase = ref.Base()
+	}
+
+	if tStruct := types.AsStruct(tBase); tStruct != nil &&
+		slices.ContainsFunc(tStruct.Fields(), func(field types.StructField) bool {
+			return field.Name == dot.Y.Name
+		}) {
+		return nil, false
+	}
+
+	method := check.methodOf(tBase, dot.Y.Name)
+	if method == nil {
+		return nil, false
+	}
+
+	params := method.ty.Params().Types()
+
+	if len(params) == 0 || !isReceiverOf(params[0], tBase) {
+		name := method.methodOf.Name()
+		check.errorf(dot.Y, diag.InvalidMethod, "method '%s' has no receiver, its first parameter must be of type '%s' or '*%s'", dot.Y.Name, name, name)
+		return nil, true
+	}
+
+	if types.IsRef(params[0]) && !types.IsRef(tRecv) && !isAddressable(dot.X) {
+		check.errorf(dot.X, diag.NotAssignable, "cannot take the address of the receiver")
+		return nil, true
+	}
+
+	check.newUse(dot.Y, method)
+	check.setType(dot, method.ty)
+
+	tArgs := types.SkipUntyped(check.typeOfParenList(node.Args))
+	if tArgs == nil {
+		return nil, true
+	}
+
+	fn := types.NewFunc(types.NewTuple(params[1:]...), method.ty.Result(), method.ty.Variadic())
+
+	if idx, err := fn.CheckArgs(tArgs.(*types.Tuple)); err != nil {
+		check.addError(newArgsError(node.Args, fn, tArgs.(*types.Tuple), idx, err))
+		return nil, true
+	}
+
+	check.setTupleArgTypes(node.Args.Nodes, fn.Params().Types())
+
+	if fn.Result().Len() == 1 {
+		return fn.Result().Types()[0], true
+	}
+
+	return fn.Result(), true
+}
+
+// Reports whether the parameter of type 't' can receive the value of
+// the type 'tBase' in the method call.
+func isReceiverOf(t, tBase types.Type) bool {
+	if ref := types.AsRef(t); ref != nil {
+		return ref.Base().Equals(tBase)
+	}
+
+	return t.Equals(tBase)
+}
+
+func isAddressable(expr ast.Node) bool {
+	switch expr := expr.(type) {
+	case *ast.Ident, *ast.Deref:
+		return true
+
+	case *ast.Dot:
+		return isAddressable(expr.X)
+
+	case *ast.Index:
+		return isAddressable(expr.X)
+	}
+
+	return false
+}
@@ -3,6 +3,7 @@ package checker
 import (
 	"github.com/elliotchance/orderedmap/v2"
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/config"
 	"github.com/saffage/jet/types"
 )
 
@@ -11,9 +12,11 @@ type Module struct {
 	Scope   *Scope
 	Imports []*Module
 
-	name      string
-	stmts     *ast.StmtList
-	completed bool
+	name          string
+	qualifiedName string // Name with the packages, e.g. 'net.http'.
+	stmts         *ast.StmtList
+	fileID        config.FileID
+	completed     bool
 }
 
 func NewModule(scope *Scope, name string, stmts *ast.StmtList) *Module {
@@ -23,6 +26,7 @@ func NewModule(scope *Scope, name string, stmts *ast.StmtList) *Module {
 			TypeSyms: make(map[types.Type]Symbol),
 			Types:    make(map[ast.Node]*TypedValue),
 			Uses:     make(map[*ast.Ident]Symbol),
+			Lambdas:  make(map[*ast.Function]*Lambda),
 		},
 		Scope:     scope,
 		name:      name,
@@ -37,6 +41,18 @@ func (m *Module) Name() string      { return m.name }
 func (m *Module) Ident() *ast.Ident { return nil }
 func (m *Module) Node() ast.Node    { return m.stmts }
 
+// Returns the name of the module qualified with the packages that
+// contain it, for example 'net.http'.
+func (m *Module) QualifiedName() string {
+	if m.qualifiedName == "" {
+		return m.name
+	}
+	return m.qualifiedName
+}
+
+// Returns ID of the file from which the module was checked.
+func (m *Module) FileID() config.FileID { return m.fileID }
+
 func (m *Module) TypeOf(expr ast.Node) types.Type {
 	if expr != nil {
 		if t := m.TypeInfo.TypeOf(expr); t != nil {
@@ -78,8 +94,17 @@ func (m *Module) SymbolOf(ident *ast.Ident) Symbol {
 }
 
 func (check *Checker) visit(node ast.Node) ast.Visitor {
-	if decl, _ := node.(*ast.Decl); decl != nil {
-		check.resolveDecl(decl)
+	if check.skipBadStmt(node) {
+		return nil
+	}
+
+	switch node := node.(type) {
+	case *ast.Decl:
+		check.resolveDecl(node)
+		return nil
+
+	case *ast.Import:
+		check.resolveImport(node)
 		return nil
 	}
 
@@ -2,80 +2,223 @@ package checker
 
 import (
 	"bytes"
-	"io/fs"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 )
 
+// Resolves and checks the modules imported by the program. The importer
+// is shared by the checkers of all modules of the program, so each file
+// is checked only once.
+type importer struct {
+	opts    Options
+	modules map[string]*Module // Checked modules, indexed by their absolute paths.
+	stack   []importFrame      // Modules being checked, the innermost is the last.
+	skipped []*skippedBody     // Function bodies of the unchanged modules that are not checked yet.
+}
+
+type importFrame struct {
+	path string      // Absolute path to the module file.
+	name string      // Qualified name of the module.
+	node *ast.Import // Import of the module, nil if the module is not imported.
+}
+
+func newImporter(opts Options) *importer {
+	return &importer{opts: opts, modules: map[string]*Module{}}
+}
+
+// Checks the skipped function bodies of the unchanged modules. The bodies
+// are needed when the code of the modules is evaluated at compile time.
+func (imp *importer) checkSkipped() {
+	for len(imp.skipped) > 0 {
+		body := imp.skipped[0]
+		imp.skipped = imp.skipped[1:]
+		body.resolve()
+	}
+}
+
+// Checks the skipped function bodies of the module that can refer to
+// generic declarations, see [skippedBody.mayInstantiate].
+func (imp *importer) checkSkippedGenerics(check *Checker) {
+	skipped := imp.skipped
+	imp.skipped = nil
+
+	for _, body := range skipped {
+		if body.check == check && body.mayInstantiate() {
+			body.resolve()
+		} else {
+			imp.skipped = append(imp.skipped, body)
+		}
+	}
+}
+
+// Returns the index of the frame of the module with the specified path,
+// or -1 if the module is not being checked. Importing a module that is
+// being checked forms an import cycle.
+func (imp *importer) cycleStart(path string) int {
+	return slices.IndexFunc(imp.stack, func(frame importFrame) bool {
+		return frame.path == path
+	})
+}
+
 func (check *Checker) resolveImport(node *ast.Import) {
-	path := check.resolveImportPath(node.Module)
+	name := importName(node)
+
+	path := check.resolveImportPath(node, name)
 	if path == "" {
-		check.errorf(node.Module, "cannot find module named '%s'", node.Module)
 		return
 	}
 
-	fileContent, err := os.ReadFile(path)
-	if err != nil {
-		check.errorf(node.Module, "while reading file: %s", err.Error())
+	if start := check.importer.cycleStart(path); start != -1 {
+		check.addError(errorImportCycle(node, name, check.importer.stack, start))
+		return
 	}
 
-	fileID := config.NextFileID()
-	check.cfg.Files[fileID] = config.FileInfo{
-		Name: node.Module.Name,
-		Path: path,
-		Buf:  bytes.NewBuffer(fileContent),
-	}
+	m := check.importer.modules[path]
+
+	if m == nil {
+		fileContent, err := os.ReadFile(path)
+		if err != nil {
+			check.errorf(node, diag.InvalidImport, "while reading file: %s", err.Error())
+			return
+		}
+
+		fileID := config.NextFileID()
+		check.cfg.Files[fileID] = config.FileInfo{
+			Name: name,
+			Path: path,
+			Buf:  bytes.NewBuffer(fileContent),
+		}
 
-	m, err := CheckFile(check.cfg, fileID)
-	if err != nil {
-		report.Errors(err)
-		check.errorf(node.Module, "the module check was finished with errors")
+		m, err = check.importer.checkFile(check.cfg, fileID, node)
+		if err != nil {
+			report.Errors(err)
+			check.errorf(node.Module(), diag.InvalidImport, "the module check was finished with errors")
+		}
+		if m == nil {
+			return
+		}
+	} else {
+		report.TaggedDebugf("importer", "module '%s' is already checked", name)
 	}
 
 	if defined := check.module.Scope.Define(m); defined != nil {
-		check.addError(errorAlreadyDefined(node.Module, defined.Ident()))
+		check.addError(errorAlreadyDefined(node.Module(), defined.Ident()))
 		return
 	}
 	check.module.Imports = append(check.module.Imports, m)
-	check.newDef(node.Module, m)
+	check.newDef(node.Module(), m)
 }
 
-func (check *Checker) resolveImportPath(ident *ast.Ident) string {
-	modulePath := ""
-	dir := filepath.Dir(check.cfg.Files[check.fileID].Path)
-	err := filepath.Walk(dir, makeWalkFunc(dir, ident.Name, &modulePath))
-	if err != nil {
-		check.errorf(ident, "while walking dir: %s", err.Error())
-		return ""
+// Returns the absolute path to the file of the imported module. The
+// module 'a.b.c' is the file 'a/b/c.jet' in one of the directories of
+// [Checker.searchPath], the first found file is used.
+func (check *Checker) resolveImportPath(node *ast.Import, name string) string {
+	relPath := filepath.FromSlash(strings.ReplaceAll(name, ".", "/"))
+	dirs := check.searchPath()
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, relPath+".jet")
+
+		if stat, err := os.Stat(path); err == nil && stat.Mode().IsRegular() {
+			report.TaggedDebugf("importer", "found file: '%s'", path)
+			return path
+		}
 	}
-	return modulePath
-}
 
-func makeWalkFunc(root string, expectedName string, result *string) filepath.WalkFunc {
-	return func(path string, info fs.FileInfo, err error) error {
-		if info.IsDir() {
-			if path != "." && path != root {
-				return fs.SkipDir
-			}
-			return nil
+	for _, dir := range dirs {
+		if stat, err := os.Stat(filepath.Join(dir, relPath)); err == nil && stat.IsDir() {
+			check.errorf(node, diag.InvalidImport, "'%s' is a package, expected a module of the package (for example, '%s.name')", name, name)
+			return ""
 		}
+	}
+
+	quoted := make([]string, len(dirs))
+	for i, dir := range dirs {
+		quoted[i] = "'" + dir + "'"
+	}
 
-		ext := filepath.Ext(path)
-		name := filepath.Base(path[:len(path)-len(ext)])
+	check.errorf(node, diag.InvalidImport, "cannot find module '%s' (searched in %s)", name, strings.Join(quoted, ", "))
+	return ""
+}
 
-		if name == expectedName && ext == ".jet" {
-			if result != nil {
-				*result = path
-			}
+// Returns the directories in which the imported modules are searched,
+// in the order of priority:
+//
+//   - the project root, which is the directory of the main file;
+//   - the directory of the core library ('JETLIB');
+//   - the directories of the search path ('JETPATH').
+func (check *Checker) searchPath() []string {
+	dirs := []string{}
 
-			report.TaggedDebugf("importer", "found file: '%s'", path)
-			return filepath.SkipAll
+	add := func(dir string) {
+		if dir == "" {
+			return
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
 		}
+	}
+
+	if main, ok := check.cfg.Files[config.MainFileID]; ok && main.Path != "" {
+		add(filepath.Dir(main.Path))
+	}
+
+	add(check.cfg.Options.CoreLibPath)
 
-		return nil
+	for _, dir := range check.cfg.Options.SearchPath {
+		add(dir)
+	}
+
+	return dirs
+}
+
+// Returns the qualified name of the imported module, e.g. 'net.http'.
+func importName(node *ast.Import) string {
+	names := make([]string, len(node.Path))
+	for i, ident := range node.Path {
+		names[i] = ident.Name
+	}
+	return strings.Join(names, ".")
+}
+
+// Reports the import cycle with the chain of imports from the first
+// checked module. Imports that form the cycle are noted.
+func errorImportCycle(node *ast.Import, name string, stack []importFrame, start int) *Error {
+	chain := make([]string, 0, len(stack)+1)
+	for _, frame := range stack {
+		chain = append(chain, frame.name)
+	}
+	chain = append(chain, name)
+
+	err := newErrorf(node, diag.InvalidImport, "import cycle is not allowed: %s", strings.Join(chain, " -> "))
+
+	for i := start + 1; i < len(stack); i++ {
+		err.Notes = append(err.Notes, &Error{
+			Message: fmt.Sprintf("module '%s' imports '%s' here", stack[i-1].name, stack[i].name),
+			Node:    stack[i].node,
+		})
+	}
+
+	return err
+}
+
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
 	}
+	return path
 }
@@ -104,7 +104,7 @@ func checkBuiltInPkgsAux(cfg *config.Config) error {
 		Buf:  bytes.NewBuffer(cModuleContent),
 	}
 
-	ModuleBuiltin, err = CheckFile(cfg, builtinFileID)
+	ModuleBuiltin, err = CheckFile(cfg, builtinFileID, Options{})
 	if err != nil {
 		report.TaggedErrorf("internal", "while checking package 'builtin'")
 		return err
@@ -129,5 +129,5 @@ var ErrorInvalidPkgPath = errors.New("invalid path to the package")
 func internalCheckBuiltInModule(cfg *config.Config, filepath string) (*Module, error) {
 	var fileID config.FileID
 
-	return CheckFile(cfg, fileID)
+	return CheckFile(cfg, fileID, Options{})
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/types"
 )
@@ -47,12 +48,12 @@ func (check *Checker) prefix(node *ast.Op, tyOperand types.Type) types.Type {
 			return types.NewRef(tyOperand)
 		}
 
-		check.errorf(node.Y, "expression is not an addressable location")
+		check.errorf(node.Y, diag.NotAssignable, "expression is not an addressable location")
 		return nil
 
 	case ast.OperatorPtr, ast.OperatorMutPtr:
 		if !types.IsTypeDesc(tyOperand) {
-			check.errorf(node.Y, "expression is not a type")
+			check.errorf(node.Y, diag.ExpectedTypeExpr, "expression is not a type")
 			return nil
 		}
 
@@ -63,7 +64,7 @@ func (check *Checker) prefix(node *ast.Op, tyOperand types.Type) types.Type {
 	}
 
 	check.errorf(
-		node,
+		node, diag.InvalidOperator,
 		"operator '%s' is not defined for the type %s",
 		node.Kind,
 		tyOperand,
@@ -78,14 +79,14 @@ func (check *Checker) infix(node *ast.Op, tOperandX, tOperandY types.Type) types
 
 	// TODO invalid type will be inferred if one of them is untyped
 	if !tOperandY.Equals(tOperandX) && !types.SkipUntyped(tOperandY).Equals(types.SkipUntyped(tOperandX)) {
-		check.errorf(node, "type mismatch (%s and %s)", tOperandX, tOperandY)
+		check.errorf(node, diag.TypeMismatch, "type mismatch (%s and %s)", tOperandX, tOperandY)
 		return nil
 	}
 
 	// Assignment operation doesn't have a value.
 	if node.Kind == ast.OperatorAssign {
 		if !check.assignable(node.X) {
-			check.errorf(node.X, "expression cannot be assigned")
+			check.errorf(node.X, diag.NotAssignable, "expression cannot be assigned")
 		}
 		check.setType(node.Y, tOperandX)
 		return types.Unit
@@ -98,20 +99,25 @@ func (check *Checker) infix(node *ast.Op, tOperandX, tOperandY types.Type) types
 		}
 
 	case *types.Ref, *types.Enum:
+		if tX, _ := tX.(*types.Enum); tX != nil && tX.IsTagged() {
+			check.errorf(node, diag.InvalidOperator, "enums with payloads cannot be compared, use 'match' instead")
+			return nil
+		}
+
 		switch node.Kind {
 		case ast.OperatorEq, ast.OperatorNe:
 			return types.Bool
 		}
 	}
 
-	check.errorf(node, "type mismatch (%s and %s)", tOperandX, tOperandY)
+	check.errorf(node, diag.TypeMismatch, "type mismatch (%s and %s)", tOperandX, tOperandY)
 	return nil
 }
 
 func (check *Checker) infixAs(node *ast.Op, _, tyY types.Type) types.Type {
 	typedesc := types.AsTypeDesc(tyY)
 	if typedesc == nil {
-		check.errorf(node.Y, "expected type, got '%s' instead", tyY)
+		check.errorf(node.Y, diag.ExpectedTypeExpr, "expected type, got '%s' instead", tyY)
 		return nil
 	}
 
@@ -162,7 +168,7 @@ func (check *Checker) infixPrimitive(
 			types.KindF32,
 			types.KindF64:
 			if !check.assignable(node.X) {
-				check.errorf(node.X, "expression cannot be assigned")
+				check.errorf(node.X, diag.NotAssignable, "expression cannot be assigned")
 			}
 			return types.Unit
 		}
@@ -203,7 +209,7 @@ func (check *Checker) infixPrimitive(
 			types.KindU32,
 			types.KindU64:
 			if !check.assignable(node.X) {
-				check.errorf(node.X, "expression cannot be assigned")
+				check.errorf(node.X, diag.NotAssignable, "expression cannot be assigned")
 			}
 			return types.Unit
 		}
@@ -280,7 +286,7 @@ func (check *Checker) postfix(node *ast.Op, tyOperand types.Type) types.Type {
 	// 		}
 	// 	}
 
-	// 	check.errorf(node.X, "expression is not an addressable location")
+	// 	check.errorf(node.X, diag.NotAssignable, "expression is not an addressable location")
 	// 	return nil
 
 	// case ast.OperatorStar:
@@ -293,7 +299,7 @@ func (check *Checker) postfix(node *ast.Op, tyOperand types.Type) types.Type {
 	// 		return ref.Base()
 	// 	}
 
-	// 	check.errorf(node.X, "expression is not a reference type")
+	// 	check.errorf(node.X, diag.InvalidPointer, "expression is not a reference type")
 	// 	return nil
 
 	// default:
@@ -301,18 +307,37 @@ func (check *Checker) postfix(node *ast.Op, tyOperand types.Type) types.Type {
 	// }
 }
 
+// Reports whether the expression is the target of an assignment.
 func (check *Checker) assignable(node ast.Node) bool {
+	return check.addressable(node, true)
+}
+
+// Reports whether the expression refers to a location in memory, so it
+// can be assigned, indexed or sliced. When 'assign' is true, assignment
+// to a variable captured by value is reported.
+func (check *Checker) addressable(node ast.Node, assign bool) bool {
 	switch operand := node.(type) {
 	case *ast.Ident:
 		if operand != nil {
-			varSym, ok := check.symbolOf(operand).(*Var)
+			sym := check.symbolOf(operand)
+
+			// Constants are stored in memory too, but cannot be changed.
+			if constSym, _ := sym.(*Const); constSym != nil && !assign {
+				check.newUse(operand, constSym)
+				return true
+			}
+
+			varSym, ok := sym.(*Var)
 			if !ok || varSym == nil {
-				check.errorf(operand, "identifier is not a variable")
+				check.errorf(operand, diag.NotAssignable, "identifier is not a variable")
 				return false
 			}
 
 			report.TaggedDebugf("checker", "assign '%s' at '%s'", varSym.Name(), operand)
 			check.newUse(operand, varSym)
+			if assign {
+				check.checkCaptureAssign(operand, varSym)
+			}
 			return true
 		}
 
@@ -325,29 +350,35 @@ func (check *Checker) assignable(node ast.Node) bool {
 
 			// fieldSym, ok := check.symbolOf(fieldIdent).(*Var)
 			// if !ok || fieldSym == nil {
-			// 	check.errorf(fieldIdent, "identifier is not a variable")
+			// 	check.errorf(fieldIdent, diag.NotAssignable, "identifier is not a variable")
 			// 	return false
 			// }
 
 			// check.newUse(fieldIdent, fieldSym)
-			return check.assignable(operand.X)
+			return check.addressable(operand.X, assign)
 		}
 
 	case *ast.Index:
 		if operand != nil {
-			if t := types.AsArray(check.typeOf(operand.X)); t != nil {
+			t := check.typeOf(operand.X)
+			if types.IsSlice(t) {
 				return true
 			}
 
+			// Elements of the array are stored in the array itself.
+			if types.IsArray(t) {
+				return check.addressable(operand.X, assign)
+			}
+
 			// operandName, _ := operand.X.(*ast.Ident)
 			// if operandName == nil {
-			// 	check.errorf(operand.X, "expected identifier")
+			// 	check.errorf(operand.X, diag.InvalidDecl, "expected identifier")
 			// 	return false
 			// }
 
 			// varSym, ok := check.symbolOf(operandName).(*Var)
 			// if !ok || varSym == nil {
-			// 	check.errorf(operand, "identifier is not a variable")
+			// 	check.errorf(operand, diag.NotAssignable, "identifier is not a variable")
 			// 	return false
 			// }
 
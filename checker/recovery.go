@@ -0,0 +1,95 @@
+package checker
+
+import "github.com/saffage/jet/ast"
+
+// Reports whether the statement contains the code that was replaced by
+// the parser because of a syntax error. Such statements are skipped
+// without errors, the syntax errors are already reported.
+//
+// Statements of the nested blocks are not visited, they are checked
+// (and skipped) separately.
+func hasBadNode(stmt ast.Node) (found bool) {
+	var visit ast.Visitor
+
+	visit = func(node ast.Node) ast.Visitor {
+		switch node := node.(type) {
+		case *ast.BadNode:
+			found = true
+
+		case *ast.CurlyList:
+			return nil
+
+		// The parser leaves nil in place of the invalid members.
+		case *ast.StructType:
+			found = hasNil(node.Fields) || hasNil(node.Methods)
+
+		case *ast.EnumType:
+			found = hasNil(node.Variants) || hasNil(node.Methods)
+
+		case *ast.Match:
+			found = hasNil(node.Arms)
+		}
+
+		if found {
+			return nil
+		}
+		return visit
+	}
+
+	visit.WalkTopDown(stmt)
+	return found
+}
+
+func hasNil[T any](nodes []*T) bool {
+	for _, node := range nodes {
+		if node == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the identifiers declared by the statement.
+func declaredIdents(stmt ast.Node) []*ast.Ident {
+	switch stmt := stmt.(type) {
+	case *ast.Decl:
+		return []*ast.Ident{stmt.Ident}
+
+	case *ast.TupleDecl:
+		idents := []*ast.Ident{}
+		for _, name := range stmt.Names.Nodes {
+			if ident, _ := name.(*ast.Ident); ident != nil {
+				idents = append(idents, ident)
+			}
+		}
+		return idents
+
+	case *ast.BadNode:
+		if stmt.Decl != nil {
+			return []*ast.Ident{stmt.Decl}
+		}
+	}
+
+	return nil
+}
+
+// Skips the statement if it contains a syntax error. Names declared by
+// the statement are marked as invalid in the current scope, so their
+// uses are not reported as undefined.
+func (check *Checker) skipBadStmt(stmt ast.Node) bool {
+	if !hasBadNode(stmt) {
+		return false
+	}
+
+	check.markInvalidDecl(stmt)
+	return true
+}
+
+// Marks the names declared by the statement as invalid in the current
+// scope. It is used when the declaration has an error, which is already
+// reported, or when its value refers to an invalid name.
+func (check *Checker) markInvalidDecl(stmt ast.Node) {
+	for _, ident := range declaredIdents(stmt) {
+		check.scope.markInvalid(ident.Name)
+	}
+}
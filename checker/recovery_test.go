@@ -0,0 +1,65 @@
+package checker
+
+import (
+	"slices"
+	"testing"
+)
+
+// Every syntax error is reported, declarations with them are skipped
+// without the errors of the checker, the rest of the file is checked.
+func TestRecovery(t *testing.T) {
+	input := `Point := struct {
+	x: i32
+	y i32
+}
+
+add := (a: i32 b: i32) -> i32 { a + b }
+
+f := (n: i32) -> i32 {
+	xs := [1, 2 3]
+	$println(xs[0])
+	n + undefined
+}
+
+g := (a: i32) -> i32 {
+	x := a + )
+	y := x * 2
+	y
+}
+
+Opt := enum {
+	A
+	B(x i32)
+}
+
+classify := (o: Opt) -> i32 {
+	match o {
+		_ => 1
+	}
+}
+
+main := () {
+	p := Point(x = 1, y = 2)
+	$println(add(1, 2) + f(3) + g(4))
+	v: i32 = "str"
+}
+`
+	expected := []string{
+		"3:2: E0105 expected declaration",
+		"6:9: E0101 unterminated expression",
+		"9:12: E0101 unterminated expression",
+		"15:11: E0103 expected operand",
+		"22:4: E0105 expected declaration",
+		"11:6: E0201 identifier is undefined",
+		"34:11: E0301 type mismatch, expected 'i32', got 'untyped string'",
+	}
+
+	_, diagnostics, err := testCheck(t, "", "recovery", input, Options{})
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+
+	if actual := diagnosticStrings(diagnostics); !slices.Equal(actual, expected) {
+		t.Errorf("unexpected diagnostics\nexpect: %q\nactual: %q", expected, actual)
+	}
+}
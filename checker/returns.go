@@ -0,0 +1,98 @@
+package checker
+
+import (
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// A function or lambda whose body is being checked.
+type funcContext struct {
+	result  *types.Tuple  // Nil if the result type is inferred from the body.
+	returns []*ast.Return // All 'return' statements of the function.
+	defers  int           // Number of 'defer' statements being checked.
+}
+
+func (check *Checker) typeOfReturn(node *ast.Return) types.Type {
+	if len(check.funcs) == 0 {
+		check.errorf(node, diag.InvalidReturn, "'return' outside of a function")
+		return types.Unit
+	}
+
+	fn := check.funcs[len(check.funcs)-1]
+	fn.returns = append(fn.returns, node)
+
+	if fn.defers > 0 {
+		check.errorf(node, diag.InvalidReturn, "'return' cannot be used in a deferred statement")
+		return types.Unit
+	}
+
+	if node.X == nil {
+		if fn.result != nil && !fn.result.Equals(types.Unit) {
+			check.errorf(node, diag.InvalidReturn, "expected a value of type '%s' for 'return'", resultType(fn.result))
+		}
+		return types.Unit
+	}
+
+	t := check.typeOf(node.X)
+	if t == nil {
+		return types.Unit
+	}
+
+	if fn.result == nil {
+		check.errorf(node.X, diag.InvalidReturn, "'return' with a value requires the result type of the function to be specified")
+		return types.Unit
+	}
+
+	tyResult := resultType(fn.result)
+
+	if !t.Equals(tyResult) {
+		check.errorf(
+			node.X, diag.InvalidReturn,
+			"expected expression of type '%s' for 'return', got '%s' instead",
+			tyResult,
+			t,
+		)
+		return types.Unit
+	}
+
+	if types.IsUntyped(t) {
+		check.setType(node.X, tyResult)
+	}
+
+	return types.Unit
+}
+
+// Checks 'return' statements without a value after the result type
+// of the function was inferred from its body.
+func (check *Checker) checkInferredReturns(fn *funcContext, tyResult *types.Tuple) {
+	if tyResult.Equals(types.Unit) {
+		return
+	}
+
+	for _, ret := range fn.returns {
+		if ret.X == nil {
+			check.errorf(ret, diag.InvalidReturn, "expected a value of type '%s' for 'return'", resultType(tyResult))
+		}
+	}
+}
+
+// Reports whether the last statement of the function body is 'return',
+// so the body itself has no value.
+func endsWithReturn(body ast.Node) bool {
+	list, _ := body.(*ast.CurlyList)
+	if list == nil || len(list.Nodes) == 0 {
+		return false
+	}
+
+	_, isReturn := list.Nodes[len(list.Nodes)-1].(*ast.Return)
+	return isReturn
+}
+
+// Returns the type of the single result, or the tuple otherwise.
+func resultType(result *types.Tuple) types.Type {
+	if result.Len() == 1 {
+		return result.Types()[0]
+	}
+	return result
+}
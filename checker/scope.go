@@ -1,7 +1,11 @@
 package checker
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 )
 
 var Global = NewScope(nil, "global")
@@ -13,6 +17,7 @@ type Scope struct {
 	children []*Scope
 	defers   []*ast.Defer
 	symbols  map[string]Symbol
+	invalid  map[string]struct{} // Names declared with syntax errors.
 }
 
 func NewScope(parent *Scope, name string) *Scope {
@@ -51,6 +56,21 @@ func (scope *Scope) Defers() []*ast.Defer {
 	return scope.defers
 }
 
+// Returns all symbols defined in the scope, sorted by name.
+func (scope *Scope) Symbols() []Symbol {
+	symbols := make([]Symbol, 0, len(scope.symbols))
+
+	for _, sym := range scope.symbols {
+		symbols = append(symbols, sym)
+	}
+
+	slices.SortFunc(symbols, func(a, b Symbol) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return symbols
+}
+
 // Defines a new symbol in the scope. If a symbol with the same
 // name is already defined in this scope, it will return error.
 func (scope *Scope) Define(symbol Symbol) Symbol {
@@ -97,8 +117,29 @@ func (scope *Scope) LookupLocal(name string) Symbol {
 	return scope.symbols[name]
 }
 
+// Marks the name as declared by the code with an error. The symbol is
+// not defined, but its uses are not reported.
+func (scope *Scope) markInvalid(name string) {
+	if scope.invalid == nil {
+		scope.invalid = make(map[string]struct{})
+	}
+
+	scope.invalid[name] = struct{}{}
+}
+
+// Reports whether the name is marked as invalid in the scope or in
+// one of its parents.
+func (scope *Scope) isInvalid(name string) bool {
+	for ; scope != nil; scope = scope.parent {
+		if _, ok := scope.invalid[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func errorAlreadyDefined(ident, previous *ast.Ident) *Error {
-	err := newErrorf(ident, "name '%s' is already defined in this scope", ident.Name)
+	err := newErrorf(ident, diag.AlreadyDefined, "name '%s' is already defined in this scope", ident.Name)
 
 	if previous != nil && previous.Start.Line > 0 {
 		err.Notes = append(err.Notes, &Error{
@@ -0,0 +1,108 @@
+package checker
+
+import (
+	"errors"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// Session checks declarations and expressions that are entered one by
+// one (for example, by the REPL). All inputs share the scope of a single
+// module, so every input can use the declarations of the previous ones.
+type Session struct {
+	check *Checker
+}
+
+func NewSession(cfg *config.Config, name string, opts Options) *Session {
+	module := NewModule(NewScope(Global, "module "+name), name, &ast.StmtList{})
+	return &Session{
+		check: &Checker{
+			module:   module,
+			scope:    module.Scope,
+			errors:   make([]error, 0),
+			importer: newImporter(opts),
+			cfg:      cfg,
+		},
+	}
+}
+
+// Returns the module that contains all declarations of the session.
+func (s *Session) Module() *Module { return s.check.module }
+
+// Checks the declarations from the file and adds them to the module.
+func (s *Session) CheckDecls(fileID config.FileID, stmts *ast.StmtList) error {
+	s.begin(fileID)
+
+	s.check.declareTypes(stmts)
+	visitor := ast.Visitor(s.check.visit)
+
+	for _, node := range stmts.Nodes {
+		if _, isEmpty := node.(*ast.Empty); !isEmpty {
+			s.check.module.stmts.Nodes = append(s.check.module.stmts.Nodes, node)
+			visitor.WalkTopDown(node)
+		}
+	}
+
+	return errors.Join(s.check.errors...)
+}
+
+// Checks the expression in the scope of the module and returns its type.
+func (s *Session) CheckExpr(fileID config.FileID, expr ast.Node) (types.Type, error) {
+	s.begin(fileID)
+
+	t := s.check.typeOf(expr)
+	if err := errors.Join(s.check.errors...); err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, newErrorf(expr, diag.NoType, "expression has no type")
+	}
+
+	return t, nil
+}
+
+func (s *Session) begin(fileID config.FileID) {
+	s.check.fileID = fileID
+	s.check.scope = s.check.module.Scope
+	s.check.errors = s.check.errors[:0]
+}
+
+// Returns the name of the struct or enum type declared in the module or
+// in one of its imports. Other types are displayed as is.
+func (m *Module) TypeName(t types.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+
+	visited := map[*Module]bool{}
+
+	var lookup func(*Module) Symbol
+	lookup = func(m *Module) Symbol {
+		if visited[m] {
+			return nil
+		}
+
+		visited[m] = true
+
+		if sym := m.TypeSyms[types.SkipAlias(t)]; sym != nil {
+			return sym
+		}
+
+		for _, imported := range m.Imports {
+			if sym := lookup(imported); sym != nil {
+				return sym
+			}
+		}
+
+		return nil
+	}
+
+	if sym := lookup(m); sym != nil {
+		return sym.Name()
+	}
+
+	return t.String()
+}
@@ -0,0 +1,72 @@
+package checker
+
+import (
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// Reports whether the index is a range expression 'a..b' or 'a..<b'.
+func isRangeIndex(node *ast.Index) (*ast.Op, bool) {
+	if len(node.Args.Nodes) != 1 {
+		return nil, false
+	}
+
+	op, _ := node.Args.Nodes[0].(*ast.Op)
+	if op == nil || op.Kind != ast.OperatorRangeInclusive && op.Kind != ast.OperatorRangeExclusive {
+		return nil, false
+	}
+
+	return op, true
+}
+
+// Type checks 'x[a..b]' and 'x[a..<b]' where 'x' is an array or a slice.
+// The result is a slice of the same element type, bounds are checked
+// at runtime.
+func (check *Checker) typeOfSliceExpr(node *ast.Index, bounds *ast.Op, t types.Type) types.Type {
+	var elem types.Type
+
+	if array := types.AsArray(t); array != nil {
+		if !check.addressable(node.X, false) {
+			check.errorf(node.X, diag.InvalidIndex, "expression cannot be sliced")
+			return nil
+		}
+		elem = array.ElemType()
+	} else if slice := types.AsSlice(t); slice != nil {
+		elem = slice.ElemType()
+	} else {
+		check.errorf(node.X, diag.InvalidIndex, "expression is not an array or slice")
+		return nil
+	}
+
+	if bounds.X == nil || bounds.Y == nil {
+		check.errorf(bounds, diag.InvalidLoop, "expected range expression")
+		return nil
+	}
+
+	for _, bound := range []ast.Node{bounds.X, bounds.Y} {
+		tBound := check.typeOf(bound)
+		if tBound == nil {
+			return nil
+		}
+
+		if !tBound.Equals(types.I32) {
+			check.errorf(bound, diag.TypeMismatch, "expected type (i32) for slice bound, got (%s) instead", tBound)
+			return nil
+		}
+	}
+
+	return types.NewSlice(elem)
+}
+
+// Type checks 'x.len' where 'x' is an array or a slice.
+func (check *Checker) lenOf(node *ast.Dot, t types.Type) types.Type {
+	if node.Y.Name != "len" {
+		err := newErrorf(node.Y, diag.UnknownMember, "type '%s' has no field named '%s'", t, node.Y.Name)
+		err.suggestName(node.Y, []string{"len"})
+		check.addError(err)
+		return nil
+	}
+
+	return types.I32
+}
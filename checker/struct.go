@@ -6,15 +6,19 @@ import (
 	"strings"
 
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/types"
 )
 
 type Struct struct {
-	owner *Scope
-	body  *Scope
-	t     *types.TypeDesc
-	decl  *ast.Decl
+	owner    *Scope
+	body     *Scope
+	methods  *Scope
+	t        *types.TypeDesc
+	decl     *ast.Decl
+	generic  *Generic     // Declaration from which the struct was instantiated.
+	typeArgs []types.Type // Type arguments of the instance.
 }
 
 func NewStruct(owner *Scope, body *Scope, t *types.TypeDesc, decl *ast.Decl) *Struct {
@@ -24,7 +28,7 @@ func NewStruct(owner *Scope, body *Scope, t *types.TypeDesc, decl *ast.Decl) *St
 	if body.Parent() != owner {
 		panic("invalid local scope parent")
 	}
-	return &Struct{owner, body, t, decl}
+	return &Struct{owner, body, NewScope(owner, "methods "+decl.Ident.Name), t, decl, nil, nil}
 }
 
 func (sym *Struct) Owner() *Scope     { return sym.owner }
@@ -33,9 +37,38 @@ func (sym *Struct) Name() string      { return sym.decl.Ident.Name }
 func (sym *Struct) Ident() *ast.Ident { return sym.decl.Ident }
 func (sym *Struct) Node() ast.Node    { return sym.decl }
 
+func (sym *Struct) Methods() *Scope        { return sym.methods }
+func (sym *Struct) Generic() *Generic      { return sym.generic }
+func (sym *Struct) TypeArgs() []types.Type { return sym.typeArgs }
+
+// Declares the struct before its fields are resolved, so the struct can
+// be referred by its own fields and by declarations above it. Returns nil
+// if the name is already defined.
+func (check *Checker) declareStruct(decl *ast.Decl) *Struct {
+	if check.scope.LookupLocal(decl.Ident.Name) != nil {
+		return nil
+	}
+
+	local := NewScope(check.scope, "struct "+decl.Ident.Name)
+	sym := NewStruct(check.scope, local, types.NewTypeDesc(types.NewStruct()), decl)
+	check.scope.Define(sym)
+	check.module.TypeSyms[types.SkipTypeDesc(sym.Type())] = sym
+	return sym
+}
+
 func (check *Checker) resolveStructDecl(decl *ast.Decl, value *ast.StructType) {
+	sym, _ := check.scope.LookupLocal(decl.Ident.Name).(*Struct)
+
+	if sym == nil || sym.decl != decl {
+		if sym = check.declareStruct(decl); sym == nil {
+			defined := check.scope.LookupLocal(decl.Ident.Name)
+			check.addError(errorAlreadyDefined(decl.Ident, defined.Ident()))
+			return
+		}
+	}
+
+	t := types.AsStruct(types.SkipTypeDesc(sym.Type()))
 	fields := make([]types.StructField, len(value.Fields))
-	local := NewScope(check.scope, "struct "+decl.Ident.Name)
 
 	for i, fieldDecl := range value.Fields {
 		tField := check.typeOf(fieldDecl.Type)
@@ -44,7 +77,7 @@ func (check *Checker) resolveStructDecl(decl *ast.Decl, value *ast.StructType) {
 		}
 
 		if !types.IsTypeDesc(tField) {
-			check.errorf(fieldDecl.Type, "expected field type, got (%s) instead", tField)
+			check.errorf(fieldDecl.Type, diag.TypeMismatch, "expected field type, got (%s) instead", tField)
 			return
 		}
 
@@ -52,13 +85,23 @@ func (check *Checker) resolveStructDecl(decl *ast.Decl, value *ast.StructType) {
 			panic("typedesc cannot have an untyped base")
 		}
 
-		t := types.AsTypeDesc(tField).Base()
-		fieldSym := NewVar(local, t, fieldDecl)
+		tBase := types.AsTypeDesc(tField).Base()
+
+		if containsType(tBase, t) {
+			check.errorf(
+				fieldDecl.Type, diag.RecursiveType,
+				"invalid recursive type, field '%s' must be a pointer",
+				fieldDecl.Ident.Name,
+			)
+			return
+		}
+
+		fieldSym := NewVar(sym.body, tBase, fieldDecl)
 		fieldSym.isField = true
-		fields[i] = types.StructField{fieldDecl.Ident.Name, t}
+		fields[i] = types.StructField{Name: fieldDecl.Ident.Name, Type: tBase}
 
-		if defined := local.Define(fieldSym); defined != nil {
-			err := newErrorf(fieldSym.Ident(), "duplicate field '%s'", fieldSym.Name())
+		if defined := sym.body.Define(fieldSym); defined != nil {
+			err := newErrorf(fieldSym.Ident(), diag.AlreadyDefined, "duplicate field '%s'", fieldSym.Name())
 			err.Notes = append(err.Notes, &Error{
 				Message: "field was defined here",
 				Node:    defined.Ident(),
@@ -70,27 +113,55 @@ func (check *Checker) resolveStructDecl(decl *ast.Decl, value *ast.StructType) {
 		check.newDef(fieldDecl.Ident, fieldSym)
 	}
 
-	t := types.NewTypeDesc(types.NewStruct(fields...))
-	sym := NewStruct(check.scope, local, t, decl)
+	t.SetFields(fields...)
+	check.newDef(decl.Ident, sym)
+	check.resolveMethods(sym, sym.methods, value.Methods)
+}
 
-	if defined := check.scope.Define(sym); defined != nil {
-		check.addError(errorAlreadyDefined(sym.Ident(), defined.Ident()))
-		return
+// Reports whether the value of type 't' contains the value of type
+// 'target' (itself, in an array, in a struct field or in a payload).
+func containsType(t, target types.Type) bool {
+	if array := types.AsArray(t); array != nil {
+		return containsType(array.ElemType(), target)
 	}
 
-	check.newDef(decl.Ident, sym)
+	if s := types.AsStruct(t); s != nil {
+		if types.Type(s) == target {
+			return true
+		}
+
+		for _, field := range s.Fields() {
+			if containsType(field.Type, target) {
+				return true
+			}
+		}
+	}
+
+	if e := types.AsEnum(t); e != nil {
+		if types.Type(e) == target {
+			return true
+		}
+
+		for _, field := range e.Fields() {
+			if payload := e.Payload(field); payload != nil && containsType(payload, target) {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 func (check *Checker) structInit(initList *ast.ParenList, ty *types.Struct) {
 	// tTypeStruct := types.AsStruct(typedesc.Base())
 	// if tTypeStruct == nil {
-	// 	check.errorf(node.X, "type (%s) is not a struct", typedesc)
+	// 	check.errorf(node.X, diag.InvalidStructInit, "type (%s) is not a struct", typedesc)
 	// 	return nil
 	// }
 
 	// initList, _ := node.Y.(*ast.CurlyList)
 	// if initList == nil {
-	// 	check.errorf(node.Y, "expected struct initializer")
+	// 	check.errorf(node.Y, diag.InvalidStructInit, "expected struct initializer")
 	// 	return nil
 	// }
 
@@ -121,7 +192,7 @@ func (check *Checker) structInit(initList *ast.ParenList, ty *types.Struct) {
 
 			if _, hasField := initFields[fieldNameNode.Name]; hasField {
 				// TODO point to the previous field assignment.
-				err := newErrorf(fieldNameNode, "field '%s' is already specified", fieldNameNode.Name)
+				err := newErrorf(fieldNameNode, diag.InvalidStructInit, "field '%s' is already specified", fieldNameNode.Name)
 				check.addError(err)
 			} else {
 				initFields[fieldNameNode.Name] = tFieldValue
@@ -163,7 +234,7 @@ func (check *Checker) structInit(initList *ast.ParenList, ty *types.Struct) {
 
 		if !tInit.Equals(field.Type) {
 			check.errorf(
-				initFieldValues[field.Name],
+				initFieldValues[field.Name], diag.TypeMismatch,
 				"type mismatch, expected (%s) for field '%s', got (%s) instead",
 				field.Type,
 				field.Name,
@@ -185,13 +256,13 @@ func (check *Checker) structInit(initList *ast.ParenList, ty *types.Struct) {
 
 	if len(missingFieldNames) == 1 {
 		check.errorf(
-			initList,
+			initList, diag.InvalidStructInit,
 			"missing field '%s' in struct initializer",
 			missingFieldNames[0],
 		)
 	} else if len(missingFieldNames) > 1 {
 		check.errorf(
-			initList,
+			initList, diag.InvalidStructInit,
 			"missing fields '%s' in struct initializer",
 			strings.Join(missingFieldNames, "', '"),
 		)
@@ -200,7 +271,7 @@ func (check *Checker) structInit(initList *ast.ParenList, ty *types.Struct) {
 	if len(initFields) > 0 {
 		for name := range initFields {
 			check.errorf(
-				initFieldNames[name],
+				initFieldNames[name], diag.InvalidStructInit,
 				"extra field '%s' in struct initializer",
 				name,
 			)
@@ -210,7 +281,7 @@ func (check *Checker) structInit(initList *ast.ParenList, ty *types.Struct) {
 
 func (check *Checker) structMember(selector *ast.Dot, ty *types.Struct) types.Type {
 	if ty == types.String {
-		check.errorf(selector.X, "member access on string type is not implemented")
+		check.errorf(selector.X, diag.UnsupportedFeature, "member access on string type is not implemented")
 		return nil
 	}
 
@@ -219,7 +290,13 @@ func (check *Checker) structMember(selector *ast.Dot, ty *types.Struct) types.Ty
 	})
 
 	if fieldIndex == -1 {
-		check.errorf(selector, "unknown field '%s'", selector.Y.Name)
+		if check.methodOf(ty, selector.Y.Name) != nil {
+			check.errorf(selector, diag.NotCallable, "method '%s' must be called", selector.Y.Name)
+		} else {
+			err := newErrorf(selector, diag.UnknownMember, "unknown field '%s'", selector.Y.Name)
+			err.suggestName(selector.Y, check.memberNames(ty))
+			check.addError(err)
+		}
 		return nil
 	}
 
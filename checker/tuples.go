@@ -0,0 +1,88 @@
+package checker
+
+import (
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// Type checks the tuple '(a, b)'. When all elements are types, the
+// result is the type of the tuple '(A, B)'.
+func (check *Checker) typeOfTuple(node *ast.ParenList) types.Type {
+	t := check.typeOfParenList(node)
+	if t == nil {
+		return nil
+	}
+
+	tuple := types.AsTuple(t)
+	if tuple.Len() == 0 || !types.IsTypeDesc(tuple.Types()[0]) {
+		return tuple
+	}
+
+	elems := make([]types.Type, tuple.Len())
+
+	for i, elem := range tuple.Types() {
+		elems[i] = types.SkipTypeDesc(elem)
+	}
+
+	return types.NewTypeDesc(types.NewTuple(elems...))
+}
+
+// Type checks '(a, b) := expr' and defines a variable for each element
+// of the tuple, except for elements named '_'.
+func (check *Checker) resolveTupleDecl(node *ast.TupleDecl) {
+	t := check.typeOf(node.Value)
+	if t == nil {
+		check.markInvalidDecl(node)
+		return
+	}
+
+	if types.IsTypeDesc(t) {
+		check.errorf(node.Value, diag.ExpectedValue, "expected value, got type '%s' instead", t)
+		check.markInvalidDecl(node)
+		return
+	}
+
+	tuple := types.AsTuple(types.SkipUntyped(t))
+	if tuple == nil || tuple.Equals(types.Unit) {
+		check.errorf(node.Value, diag.InvalidIndex, "expected tuple, got '%s' instead", t)
+		check.markInvalidDecl(node)
+		return
+	}
+
+	if tuple.Len() != len(node.Names.Nodes) {
+		check.errorf(node.Names, diag.ArgumentCount, "expected %d names for the tuple '%s', got %d", tuple.Len(), t, len(node.Names.Nodes))
+		check.markInvalidDecl(node)
+		return
+	}
+
+	if types.IsUntyped(t) {
+		check.setType(node.Value, tuple)
+	}
+
+	for i, name := range node.Names.Nodes {
+		ident := name.(*ast.Ident)
+		if ident.Name == "_" {
+			continue
+		}
+
+		sym := NewVar(check.scope, tuple.Types()[i], &ast.Decl{Ident: ident, Mut: node.Mut})
+
+		if defined := check.scope.Define(sym); defined != nil {
+			check.addError(errorAlreadyDefined(ident, defined.Ident()))
+			continue
+		}
+
+		check.newDef(ident, sym)
+	}
+}
+
+// Sets the types of untyped tuples passed as arguments to the types of
+// parameters, so the tuple has the same representation as the parameter.
+func (check *Checker) setTupleArgTypes(args []ast.Node, params []types.Type) {
+	for i, arg := range args {
+		if i < len(params) && types.IsTuple(params[i]) && types.IsUntyped(check.module.TypeOf(arg)) {
+			check.setType(arg, params[i])
+		}
+	}
+}
@@ -2,6 +2,7 @@ package checker
 
 import (
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/types"
 )
@@ -37,7 +38,7 @@ func (check *Checker) resolveTypeAliasDecl(decl *ast.Decl) {
 	typedesc := types.AsTypeDesc(t)
 
 	if typedesc == nil {
-		check.errorf(decl.Value, "expression is not a type")
+		check.errorf(decl.Value, diag.ExpectedTypeExpr, "expression is not a type")
 		return
 	}
 
@@ -18,6 +18,9 @@ type TypeInfo struct {
 
 	// Every usage in the entire module.
 	Uses map[*ast.Ident]Symbol
+
+	// Every function literal in the entire module.
+	Lambdas map[*ast.Function]*Lambda
 }
 
 func (ti *TypeInfo) TypeOf(expr ast.Node) types.Type {
@@ -49,3 +52,23 @@ func (ti *TypeInfo) SymbolOf(ident *ast.Ident) Symbol {
 	}
 	return nil
 }
+
+// Returns the method of the struct or enum type, or nil if the type
+// has no method with the specified name.
+func (ti *TypeInfo) MethodOf(t types.Type, name string) *Func {
+	var scope *Scope
+
+	switch sym := ti.TypeSyms[types.SkipAlias(t)].(type) {
+	case *Struct:
+		scope = sym.methods
+
+	case *Enum:
+		scope = sym.methods
+
+	default:
+		return nil
+	}
+
+	method, _ := scope.LookupLocal(name).(*Func)
+	return method
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"slices"
+	"strings"
 
 	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/types"
 )
@@ -19,7 +22,9 @@ func (check *Checker) typeOfInternal(expr ast.Node) types.Type {
 		panic("got nil node for expr")
 
 	case *ast.Decl:
-		panic("unhandled declaration at " + expr.Pos().String())
+		// Declarations are allowed only as statements and as parameters.
+		check.errorf(node, diag.InvalidDecl, "declaration is not allowed in an expression")
+		return nil
 
 	case *ast.BadNode,
 		*ast.Comment,
@@ -67,7 +72,7 @@ func (check *Checker) typeOfInternal(expr ast.Node) types.Type {
 		return check.typeOfBracketList(node)
 
 	case *ast.ParenList:
-		return check.typeOfParenList(node)
+		return check.typeOfTuple(node)
 
 	case *ast.CurlyList:
 		return check.typeOfCurlyList(node)
@@ -78,6 +83,9 @@ func (check *Checker) typeOfInternal(expr ast.Node) types.Type {
 	case *ast.While:
 		return check.typeOfWhile(node)
 
+	case *ast.Match:
+		return check.typeOfMatch(node)
+
 	case *ast.For:
 		return check.typeOfFor(node)
 
@@ -85,21 +93,13 @@ func (check *Checker) typeOfInternal(expr ast.Node) types.Type {
 		return check.typeOfDefer(node)
 
 	case *ast.Return:
-		check.errorf(node, "'return' statement are not implemented")
-		return types.Unit
+		return check.typeOfReturn(node)
 
-	// NOTE implementation of break & continue are not finished.
 	case *ast.Break:
-		if node.Label != nil {
-			check.errorf(node.Label, "labels are not implemented")
-		}
-		return types.Unit
+		return check.checkLoopExit(node, "break", node.Label)
 
 	case *ast.Continue:
-		if node.Label != nil {
-			check.errorf(node.Label, "labels are not implemented")
-		}
-		return types.Unit
+		return check.checkLoopExit(node, "continue", node.Label)
 
 	default:
 		panic(fmt.Sprintf("type checking of %T is not implemented", expr))
@@ -120,7 +120,12 @@ func (check *Checker) typeOfIdent(node *ast.Ident) types.Type {
 			return sym.Type()
 		}
 
-		check.errorf(node, "expression has no type")
+		if _, isGeneric := sym.(*Generic); isGeneric {
+			check.errorf(node, diag.InvalidGeneric, "generic '%s' cannot be used without instantiation", node.Name)
+			return nil
+		}
+
+		check.errorf(node, diag.NoType, "expression has no type")
 		return nil
 	}
 
@@ -152,28 +157,44 @@ func (check *Checker) typeOfCall(node *ast.Call) types.Type {
 		return nil
 	}
 
+	if dot, _ := node.X.(*ast.Dot); dot != nil && !check.isModule(dot.X) {
+		if t, isVariantInit := check.typeOfVariantInit(node, dot); isVariantInit {
+			return t
+		}
+
+		if t, isMethodCall := check.typeOfMethodCall(node, dot); isMethodCall {
+			return t
+		}
+	}
+
+	if sym := check.genericOf(node.X); sym != nil {
+		instSym := check.inferInstance(sym, node)
+		if instSym == nil {
+			return nil
+		}
+
+		check.setType(node.X, instSym.Type())
+	}
+
 	tyOperand := check.typeOf(node.X)
 	if tyOperand == nil {
 		return nil
 	}
 
 	if fn := types.AsFunc(tyOperand); fn != nil {
-		tArgs := types.SkipUntyped(check.typeOfParenList(node.Args))
+		// Untyped constants are converted to the types of the parameters.
+		tArgs := check.typeOfParenList(node.Args)
 		if tArgs == nil {
 			return nil
 		}
 
 		if idx, err := fn.CheckArgs(tArgs.(*types.Tuple)); err != nil {
-			n := ast.Node(node.Args)
-
-			if idx < len(node.Args.Nodes) {
-				n = node.Args.Nodes[idx]
-			}
-
-			check.errorf(n, err.Error())
+			check.addError(newArgsError(node.Args, fn, tArgs.(*types.Tuple), idx, err))
 			return nil
 		}
 
+		check.setTupleArgTypes(node.Args.Nodes, fn.Params().Types())
+
 		if fn.Result().Len() == 1 {
 			return fn.Result().Types()[0]
 		}
@@ -184,45 +205,59 @@ func (check *Checker) typeOfCall(node *ast.Call) types.Type {
 		return tyStruct
 	}
 
-	check.errorf(node.X, "expression is not a function or a struct type (%s)", tyOperand)
+	check.errorf(node.X, diag.NotCallable, "expression is not a function or a struct type (%s)", tyOperand)
 	return nil
 }
 
 func (check *Checker) typeOfIndex(node *ast.Index) types.Type {
+	if sym := check.genericOf(node.X); sym != nil {
+		return check.typeOfInstance(sym, node)
+	}
+
 	t := check.typeOf(node.X)
 	if t == nil {
 		return nil
 	}
 
 	if t.Equals(types.Unit) {
-		check.errorf(node.X, "expession is of type (unit) and cannot be indexed")
+		check.errorf(node.X, diag.InvalidIndex, "expession is of type (unit) and cannot be indexed")
 		return nil
 	}
 
 	if len(node.Args.Nodes) != 1 {
-		check.errorf(node.Args.List, "expected 1 argument")
+		check.errorf(node.Args.List, diag.ArgumentCount, "expected 1 argument")
 		return nil
 	}
 
+	if bounds, ok := isRangeIndex(node); ok {
+		return check.typeOfSliceExpr(node, bounds, t)
+	}
+
 	tIndex := check.typeOf(node.Args.Nodes[0])
 	if tIndex == nil {
 		return nil
 	}
 
-	if array := types.AsArray(t); array != nil {
+	if slice := types.AsSlice(t); slice != nil {
+		if !tIndex.Equals(types.I32) {
+			check.errorf(node.Args.Nodes[0], diag.TypeMismatch, "expected type (i32) for index, got (%s) instead", tIndex)
+			return nil
+		}
+		return slice.ElemType()
+	} else if array := types.AsArray(t); array != nil {
 		if !tIndex.Equals(types.I32) {
-			check.errorf(node.Args.Nodes[0], "expected type (i32) for index, got (%s) instead", tIndex)
+			check.errorf(node.Args.Nodes[0], diag.TypeMismatch, "expected type (i32) for index, got (%s) instead", tIndex)
 			return nil
 		}
-		if !check.assignable(node.X) {
-			check.errorf(node.X, "expression cannot be indexed")
+		if !check.addressable(node.X, false) {
+			check.errorf(node.X, diag.InvalidIndex, "expression cannot be indexed")
 			return nil
 		}
 		return array.ElemType()
 	} else if tuple := types.AsTuple(t); tuple != nil {
 		value := check.valueOf(node.Args.Nodes[0])
 		if value == nil || value.Value == nil || value.Value.Kind() != constant.Int {
-			check.errorf(node.Args.Nodes[0], "expected compile-time integer")
+			check.errorf(node.Args.Nodes[0], diag.NotConstant, "expected compile-time integer")
 			return nil
 		}
 
@@ -230,42 +265,51 @@ func (check *Checker) typeOfIndex(node *ast.Index) types.Type {
 		tupleLen := big.NewInt(int64(tuple.Len() - 1))
 
 		if index.Sign() == -1 || index.Cmp(tupleLen) == 1 {
-			check.errorf(node.Args.Nodes[0], "index must be in range 0..%d", tuple.Len()-1)
+			check.errorf(node.Args.Nodes[0], diag.InvalidIndex, "index must be in range 0..%d", tuple.Len()-1)
 			return nil
 		}
 
 		return tuple.Types()[index.Int64()]
 	}
 
-	check.errorf(node.X, "expression is not an array or tuple")
+	check.errorf(node.X, diag.InvalidIndex, "expression is not an array, slice or tuple")
 	return nil
 }
 
 func (check *Checker) typeOfArrayType(node *ast.ArrayType) types.Type {
 	if len(node.Args.Nodes) == 0 {
-		check.errorf(node.Args, "slices are not implemented")
-		return nil
+		elemType := check.typeOf(node.X)
+		if elemType == nil {
+			return nil
+		}
+
+		if !types.IsTypeDesc(elemType) {
+			check.errorf(node.X, diag.ExpectedTypeExpr, "expected type, got '%s'", elemType)
+			return nil
+		}
+
+		return types.NewTypeDesc(types.NewSlice(types.SkipTypeDesc(elemType)))
 	}
 
 	if len(node.Args.Nodes) > 1 {
-		check.errorf(node.Args, "expected 1 argument")
+		check.errorf(node.Args, diag.ArgumentCount, "expected 1 argument")
 		return nil
 	}
 
 	value := check.valueOf(node.Args.Nodes[0])
 	if value == nil {
-		check.errorf(node.Args.Nodes[0], "array size cannot be infered")
+		check.errorf(node.Args.Nodes[0], diag.NoType, "array size cannot be infered")
 		return nil
 	}
 
 	intValue := constant.AsInt(value.Value)
 	if intValue == nil {
-		check.errorf(node.Args.Nodes[0], "expected integer value for array size")
+		check.errorf(node.Args.Nodes[0], diag.NotConstant, "expected integer value for array size")
 		return nil
 	}
 
 	if intValue.Sign() == -1 || intValue.Int64() > math.MaxInt {
-		check.errorf(node.Args.Nodes[0], "size must be in range 0..9223372036854775807")
+		check.errorf(node.Args.Nodes[0], diag.ComptimeFailed, "size must be in range 0..9223372036854775807")
 		return nil
 	}
 
@@ -275,7 +319,7 @@ func (check *Checker) typeOfArrayType(node *ast.ArrayType) types.Type {
 	}
 
 	if !types.IsTypeDesc(elemType) {
-		check.errorf(node.X, "expected type, got '%s'", elemType)
+		check.errorf(node.X, diag.ExpectedTypeExpr, "expected type, got '%s'", elemType)
 		return nil
 	}
 
@@ -290,6 +334,18 @@ func (check *Checker) typeOfSignature(node *ast.Signature) types.Type {
 		return nil
 	}
 
+	params := types.AsTuple(tyParams).Types()
+	tyParamTypes := make([]types.Type, len(params))
+
+	for i, param := range params {
+		if !types.IsTypeDesc(param) {
+			check.errorf(node.Params.Nodes[i], diag.ExpectedTypeExpr, "expected type, got value of type '%s' instead", param)
+			return nil
+		}
+
+		tyParamTypes[i] = types.SkipTypeDesc(param)
+	}
+
 	tyResult := types.Unit
 
 	if node.Result != nil {
@@ -301,7 +357,7 @@ func (check *Checker) typeOfSignature(node *ast.Signature) types.Type {
 		if !types.IsTypeDesc(tyResultActual) &&
 			!tyResultActual.Equals(types.Unit) {
 			check.errorf(
-				node.Result,
+				node.Result, diag.ExpectedTypeExpr,
 				"expected type, got value of type '%s' instead",
 				tyResultActual,
 			)
@@ -312,36 +368,23 @@ func (check *Checker) typeOfSignature(node *ast.Signature) types.Type {
 	}
 
 	ty := types.NewFunc(
-		types.AsTuple(types.SkipTypeDesc(tyParams)),
+		types.NewTuple(tyParamTypes...),
 		tyResult,
 		nil,
 	)
 	return types.NewTypeDesc(ty)
 }
 
-func (check *Checker) typeOfFunction(node *ast.Function) types.Type {
-	check.errorf(node, "closures are not implemented")
-	return nil
-}
-
 func (check *Checker) typeOfDot(node *ast.Dot) types.Type {
-	// if ident, _ := node.X.(*ast.Ident); ident != nil {
-	// 	if m, _ := check.symbolOf(ident).(*Module); m != nil {
-	// 		if sym, _ := m.Scope.Lookup(node.Y.Name); sym != nil {
-	// 			if sym.Type() == nil {
-	// 				check.errorf(node.Y, "expression has no type")
-	// 			}
-	// 			return sym.Type()
-	// 		}
-	// 		check.errorf(
-	// 			node.Y,
-	// 			"identifier `%s` is not defined in the module `%s`",
-	// 			node.Y,
-	// 			m.Name(),
-	// 		)
-	// 		return nil
-	// 	}
-	// }
+	if sym := check.moduleMember(node); sym != nil {
+		if sym.Type() == nil {
+			check.errorf(node.Y, diag.NoType, "expression has no type")
+		}
+		return sym.Type()
+	}
+	if check.isModule(node.X) {
+		return nil
+	}
 
 	tyOperand := check.typeOf(node.X)
 	if tyOperand == nil {
@@ -350,9 +393,17 @@ func (check *Checker) typeOfDot(node *ast.Dot) types.Type {
 
 	// TODO get symbol of the type.
 	if typedesc := types.AsTypeDesc(tyOperand); typedesc != nil {
+		if method := check.methodOf(typedesc.Base(), node.Y.Name); method != nil {
+			check.newUse(node.Y, method)
+			return method.Type()
+		}
+
 		switch t := typedesc.Base().Underlying().(type) {
-		// case *types.Struct:
-		// 	return check.structInit(node.Args, typedesc)
+		case *types.Struct:
+			err := newErrorf(node.Y, diag.UnknownMember, "type '%s' has no method named '%s'", typedesc.Base(), node.Y.Name)
+			err.suggestName(node.Y, check.methodNames(typedesc.Base()))
+			check.addError(err)
+			return nil
 
 		case *types.Enum:
 			return check.enumMember(node, t)
@@ -366,10 +417,50 @@ func (check *Checker) typeOfDot(node *ast.Dot) types.Type {
 		return check.structMember(node, tyStruct)
 	}
 
-	check.errorf(node.X, "expected module or struct variable, got '%s' instead", tyOperand)
+	if types.IsSlice(tyOperand) || types.IsArray(tyOperand) {
+		return check.lenOf(node, tyOperand)
+	}
+
+	check.errorf(node.X, diag.UnknownMember, "expected module or struct variable, got '%s' instead", tyOperand)
 	return nil
 }
 
+// Returns the symbol of the imported module accessed with 'module.name',
+// or nil if the operand is not a module or the module has no such
+// symbol, the error is reported in the latter case.
+func (check *Checker) moduleMember(node *ast.Dot) Symbol {
+	ident, _ := node.X.(*ast.Ident)
+	if ident == nil {
+		return nil
+	}
+
+	m, _ := check.symbolOf(ident).(*Module)
+	if m == nil {
+		return nil
+	}
+
+	check.newUse(ident, m)
+
+	sym := m.Scope.LookupLocal(node.Y.Name)
+	if sym == nil {
+		err := newErrorf(node.Y, diag.UndefinedName, "identifier '%s' is not defined in the module '%s'", node.Y.Name, m.QualifiedName())
+		err.suggestName(node.Y, localNames(m.Scope))
+		check.addError(err)
+		return nil
+	}
+
+	check.newUse(node.Y, sym)
+	return sym
+}
+
+func (check *Checker) isModule(node ast.Node) bool {
+	if ident, _ := node.(*ast.Ident); ident != nil {
+		_, isModule := check.symbolOf(ident).(*Module)
+		return isModule
+	}
+	return false
+}
+
 func (check *Checker) typeOfDeref(node *ast.Deref) types.Type {
 	tyOperand := check.typeOf(node.X)
 	if tyOperand == nil {
@@ -380,7 +471,7 @@ func (check *Checker) typeOfDeref(node *ast.Deref) types.Type {
 		return ref.Base()
 	}
 
-	check.errorf(node.X, "expression is not a pointer")
+	check.errorf(node.X, diag.InvalidPointer, "expression is not a pointer")
 	return nil
 }
 
@@ -429,7 +520,7 @@ func (check *Checker) typeOfBracketList(node *ast.BracketList) types.Type {
 		}
 
 		if !ty.Equals(tyElem) {
-			check.errorf(expr, "expected type '%s' for element, got '%s' instead", tyElem, ty)
+			check.errorf(expr, diag.TypeMismatch, "expected type '%s' for element, got '%s' instead", tyElem, ty)
 			return nil
 		}
 	}
@@ -466,13 +557,13 @@ func (check *Checker) typeOfParenList(node *ast.ParenList) types.Type {
 		if isTypeDesc {
 			if !types.IsTypeDesc(ty) {
 				wasError = true
-				check.errorf(expr, "expected type, got value of type '%s' instead", ty)
+				check.errorf(expr, diag.ExpectedTypeExpr, "expected type, got value of type '%s' instead", ty)
 				continue
 			}
 		} else {
 			if types.IsTypeDesc(ty) {
 				wasError = true
-				check.errorf(expr, "expected expression, got type '%s' instead", ty)
+				check.errorf(expr, diag.ExpectedValue, "expected expression, got type '%s' instead", ty)
 				continue
 			}
 		}
@@ -511,7 +602,7 @@ func (check *Checker) typeOfIf(node *ast.If) types.Type {
 
 	if tCondition != nil && !tCondition.Equals(types.Bool) {
 		check.errorf(
-			node.Cond,
+			node.Cond, diag.TypeMismatch,
 			"expected type 'bool' for condition, got '%s' instead",
 			tCondition,
 		)
@@ -552,7 +643,7 @@ func (check *Checker) typeOfElse(node *ast.Else, tExpected types.Type) bool {
 		}
 
 		check.errorf(
-			lastNode,
+			lastNode, diag.TypeMismatch,
 			"all branches must have the same type with first branch (%s), got (%s) instead",
 			tExpected,
 			tBody,
@@ -563,6 +654,154 @@ func (check *Checker) typeOfElse(node *ast.Else, tExpected types.Type) bool {
 	return true
 }
 
+func (check *Checker) typeOfMatch(node *ast.Match) types.Type {
+	tOperand := check.typeOf(node.X)
+	if tOperand == nil {
+		return nil
+	}
+
+	tEnum := types.AsEnum(tOperand)
+	if tEnum == nil {
+		check.errorf(node.X, diag.InvalidVariant, "expected value of enum type, got '%s' instead", tOperand)
+		return nil
+	}
+
+	var (
+		tResult  types.Type
+		wasError bool
+		wildcard *ast.Ident
+		matched  = map[string]*ast.Ident{}
+	)
+
+	for _, arm := range node.Arms {
+		name := arm.Variant.Name
+
+		if wildcard != nil {
+			err := newErrorf(arm.Variant, diag.InvalidMatch, "unreachable arm")
+			err.Notes = append(err.Notes, &Error{
+				Message: "all values are matched here",
+				Node:    wildcard,
+			})
+			check.addError(err)
+			wasError = true
+		} else if name == "_" {
+			wildcard = arm.Variant
+		} else if !slices.Contains(tEnum.Fields(), name) {
+			typeName := tOperand.String()
+			if sym := check.module.TypeSyms[tEnum]; sym != nil {
+				typeName = sym.Name()
+			}
+
+			check.errorf(arm.Variant, diag.UnknownMember, "type '%s' has no variant named '%s'", typeName, name)
+			wasError = true
+			continue
+		} else if prev := matched[name]; prev != nil {
+			err := newErrorf(arm.Variant, diag.InvalidMatch, "variant '%s' is already matched", name)
+			err.Notes = append(err.Notes, &Error{
+				Message: "variant was matched here",
+				Node:    prev,
+			})
+			check.addError(err)
+			wasError = true
+		} else {
+			matched[name] = arm.Variant
+		}
+
+		tArm := check.typeOfMatchArm(arm, tEnum)
+		if tArm == nil {
+			wasError = true
+			continue
+		}
+
+		if tResult == nil {
+			tResult = tArm
+		} else if tArm.Equals(tResult) || types.SkipUntyped(tArm).Equals(types.SkipUntyped(tResult)) {
+			if types.IsUntyped(tResult) {
+				tResult = tArm
+			}
+		} else {
+			check.errorf(
+				arm.Body, diag.TypeMismatch,
+				"all arms must have the same type with first arm (%s), got (%s) instead",
+				tResult,
+				tArm,
+			)
+			wasError = true
+		}
+	}
+
+	if wildcard == nil {
+		missing := []string{}
+
+		for _, field := range tEnum.Fields() {
+			if matched[field] == nil {
+				missing = append(missing, field)
+			}
+		}
+
+		if len(missing) > 0 {
+			check.errorf(
+				node.X, diag.InvalidMatch,
+				"match is not exhaustive, missing variants '%s'",
+				strings.Join(missing, "', '"),
+			)
+			wasError = true
+		}
+	}
+
+	if wasError {
+		return nil
+	}
+
+	if tResult == nil {
+		return types.Unit
+	}
+
+	return tResult
+}
+
+// Type checks the body of the arm. Names in the pattern are bound to
+// the payload fields of the variant with the same names.
+func (check *Checker) typeOfMatchArm(arm *ast.MatchArm, tEnum *types.Enum) types.Type {
+	local := NewScope(check.scope, "match arm")
+
+	if arm.Names != nil {
+		payload := tEnum.Payload(arm.Variant.Name)
+		if payload == nil {
+			check.errorf(arm.Names, diag.InvalidVariant, "variant '%s' has no payload", arm.Variant.Name)
+			return nil
+		}
+
+		for _, node := range arm.Names.Nodes {
+			ident := node.(*ast.Ident)
+			i := slices.IndexFunc(payload.Fields(), func(field types.StructField) bool {
+				return field.Name == ident.Name
+			})
+
+			if i == -1 {
+				err := newErrorf(ident, diag.UnknownMember, "variant '%s' has no field named '%s'", arm.Variant.Name, ident.Name)
+				err.suggestName(ident, check.memberNames(payload))
+				check.addError(err)
+				return nil
+			}
+
+			sym := NewVar(local, payload.Fields()[i].Type, &ast.Decl{Ident: ident})
+
+			if defined := local.Define(sym); defined != nil {
+				check.addError(errorAlreadyDefined(ident, defined.Ident()))
+				return nil
+			}
+
+			check.newDef(ident, sym)
+		}
+	}
+
+	defer check.setScope(check.scope)
+	check.scope = local
+
+	return check.typeOf(arm.Body)
+}
+
 func (check *Checker) typeOfWhile(node *ast.While) types.Type {
 	tCond := check.typeOf(node.Cond)
 	if tCond == nil {
@@ -570,17 +809,20 @@ func (check *Checker) typeOfWhile(node *ast.While) types.Type {
 	}
 
 	if !tCond.Equals(types.Bool) {
-		check.errorf(node.Cond, "expected type 'bool' for condition, got (%s) instead", tCond)
+		check.errorf(node.Cond, diag.TypeMismatch, "expected type 'bool' for condition, got (%s) instead", tCond)
 		// Don't return, check the body.
 	}
 
+	check.pushLoop(node, node.Label)
 	tBody := check.typeOf(node.Body)
+	check.popLoop()
+
 	if tBody == nil {
 		return nil
 	}
 
 	if !tBody.Equals(types.Unit) {
-		check.errorf(node.Body, "while loop body must have no type, but got (%s)", tBody)
+		check.errorf(node.Body, diag.InvalidLoop, "while loop body must have no type, but got (%s)", tBody)
 		return nil
 	}
 
@@ -590,76 +832,101 @@ func (check *Checker) typeOfWhile(node *ast.While) types.Type {
 func (check *Checker) typeOfFor(node *ast.For) (ty types.Type) {
 	ty = types.Unit
 
-	// TODO implement range types
-	infix, _ := node.IterExpr.(*ast.Op)
-	if infix == nil {
-		check.errorf(node.IterExpr, "expected range expression")
-		return
-	}
-	if infix.Kind != ast.OperatorRangeInclusive && infix.Kind != ast.OperatorRangeExclusive {
-		check.errorf(infix, "expected range operator")
-		return
-	}
-	if infix.X == nil || infix.Y == nil {
-		check.errorf(node.IterExpr, "expected range expression")
-		return
-	}
+	// Types of the loop variables, the index is optional.
+	var tyLoopVars []types.Type
 
-	tyX := check.typeOf(infix.X)
-	if tyX == nil {
-		return
-	}
+	if infix, _ := node.IterExpr.(*ast.Op); infix != nil &&
+		(infix.Kind == ast.OperatorRangeInclusive || infix.Kind == ast.OperatorRangeExclusive) {
+		if infix.X == nil || infix.Y == nil {
+			check.errorf(node.IterExpr, diag.InvalidLoop, "expected range expression")
+			return
+		}
 
-	tyY := check.typeOf(infix.Y)
-	if tyY == nil {
-		return
-	}
+		tyX := check.typeOf(infix.X)
+		if tyX == nil {
+			return
+		}
 
-	if !tyY.Equals(tyX) {
-		check.errorf(infix, "type mismatch (%s and %s)", infix.X, infix.Y)
-		return
-	}
+		tyY := check.typeOf(infix.Y)
+		if tyY == nil {
+			return
+		}
 
-	// TODO allow only integral types
-	tyLoopVar := tyX
+		if !tyY.Equals(tyX) && !tyX.Equals(tyY) {
+			check.errorf(infix, diag.TypeMismatch, "type mismatch (%s and %s)", infix.X, infix.Y)
+			return
+		}
 
-	if len(node.DeclList.Nodes) > 1 {
-		check.errorf(node.DeclList.Nodes[1], "invalid loop variables count (expected 1)")
-		return
-	}
+		// TODO allow only integral types
+		tyLoopVar := tyX
+		if types.IsUntyped(tyX) {
+			tyLoopVar = tyY
+		}
 
-	loopVarDecl, _ := node.DeclList.Nodes[0].(*ast.Decl)
-	if loopVarDecl == nil {
-		panic("unreachable")
-	}
-	if loopVarDecl.Type != nil {
-		tyLoopVarExplicit := check.typeOf(loopVarDecl.Type)
-		if tyLoopVarExplicit == nil {
+		if len(node.DeclList.Nodes) > 1 {
+			check.errorf(node.DeclList.Nodes[1], diag.InvalidLoop, "invalid loop variables count (expected 1)")
 			return
 		}
-		if !types.IsTypeDesc(tyLoopVarExplicit) {
-			check.errorf(loopVarDecl.Type, "'%s' is not a type", loopVarDecl.Type)
+
+		tyLoopVars = []types.Type{tyLoopVar}
+	} else {
+		tyElem := check.typeOfIterElem(node.IterExpr)
+		if tyElem == nil {
 			return
 		}
-		tyLoopVarExplicit = types.SkipTypeDesc(tyLoopVarExplicit)
-		if !tyLoopVar.Equals(tyLoopVarExplicit) {
-			check.errorf(
-				infix,
-				"type mismatch, expected '%s' for loop variable, got '%s' instead",
-				tyLoopVarExplicit,
-				tyLoopVar,
-			)
+
+		switch len(node.DeclList.Nodes) {
+		case 1:
+			tyLoopVars = []types.Type{tyElem}
+
+		case 2:
+			tyLoopVars = []types.Type{types.I32, tyElem}
+
+		default:
+			check.errorf(node.DeclList.Nodes[2], diag.InvalidLoop, "invalid loop variables count (expected 1 or 2)")
 			return
 		}
-		tyLoopVar = tyLoopVarExplicit
 	}
 
-	tyLoopVar = types.SkipUntyped(tyLoopVar)
-
 	bodyScope := NewScope(check.scope, "loop body")
-	loopVar := NewVar(bodyScope, tyLoopVar, loopVarDecl)
-	bodyScope.Define(loopVar)
-	check.newDef(loopVarDecl.Ident, loopVar)
+
+	for i, declNode := range node.DeclList.Nodes {
+		loopVarDecl, _ := declNode.(*ast.Decl)
+		if loopVarDecl == nil {
+			panic("unreachable")
+		}
+
+		tyLoopVar := tyLoopVars[i]
+
+		if loopVarDecl.Type != nil {
+			tyLoopVarExplicit := check.typeOf(loopVarDecl.Type)
+			if tyLoopVarExplicit == nil {
+				return
+			}
+			if !types.IsTypeDesc(tyLoopVarExplicit) {
+				check.errorf(loopVarDecl.Type, diag.ExpectedTypeExpr, "'%s' is not a type", loopVarDecl.Type)
+				return
+			}
+			tyLoopVarExplicit = types.SkipTypeDesc(tyLoopVarExplicit)
+			if !tyLoopVar.Equals(tyLoopVarExplicit) {
+				check.errorf(
+					loopVarDecl.Type, diag.TypeMismatch,
+					"type mismatch, expected '%s' for loop variable, got '%s' instead",
+					tyLoopVarExplicit,
+					tyLoopVar,
+				)
+				return
+			}
+			tyLoopVar = tyLoopVarExplicit
+		}
+
+		loopVar := NewVar(bodyScope, types.SkipUntyped(tyLoopVar), loopVarDecl)
+		if defined := bodyScope.Define(loopVar); defined != nil {
+			check.addError(errorAlreadyDefined(loopVarDecl.Ident, defined.Ident()))
+			return
+		}
+		check.newDef(loopVarDecl.Ident, loopVar)
+	}
 
 	var tyBody types.Type
 	{
@@ -671,9 +938,11 @@ func (check *Checker) typeOfFor(node *ast.For) (ty types.Type) {
 
 		visitor := ast.Visitor(check.visitBlock(block))
 
+		check.pushLoop(node, node.Label)
 		for _, node := range node.Body.Nodes {
 			visitor.WalkTopDown(node)
 		}
+		check.popLoop()
 
 		report.TaggedDebugf("checker", "pop %s", bodyScope.name)
 		tyBody = block.t
@@ -682,7 +951,7 @@ func (check *Checker) typeOfFor(node *ast.For) (ty types.Type) {
 		return
 	}
 	if !tyBody.Equals(types.Unit) {
-		check.errorf(node.Body, "body must have no type, but got '%s'", tyBody)
+		check.errorf(node.Body, diag.InvalidLoop, "body must have no type, but got '%s'", tyBody)
 	}
 
 	return
@@ -692,6 +961,17 @@ func (check *Checker) typeOfDefer(node *ast.Defer) (ty types.Type) {
 	ty = types.Unit
 	check.scope.defers = append(check.scope.defers, node)
 
+	// Deferred statements cannot exit the function or enclosing loops.
+	loops := check.loops
+	check.loops = nil
+	defer func() { check.loops = loops }()
+
+	if len(check.funcs) > 0 {
+		fn := check.funcs[len(check.funcs)-1]
+		fn.defers++
+		defer func() { fn.defers-- }()
+	}
+
 	tyX := check.typeOf(node.X)
 	if tyX == nil {
 		return
@@ -0,0 +1,58 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnchangedModules(t *testing.T) {
+	input := `import util
+
+main := () {
+	$println(util.twice(util.larger(1, 2)) + util.max[i32](3, 4))
+}
+`
+	// The error in 'broken' is not found when the body is skipped.
+	util := `twice := (x: i32) -> i32 { x * 2 }
+
+broken := () -> i32 { undefined }
+
+max := [T](a: T, b: T) -> T {
+	if a > b { a } else { b }
+}
+
+larger := (a: i32, b: i32) -> i32 { max[i32](a, b) }
+`
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "util.jet"), []byte(util), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := testCheck(t, dir, "unchanged", input, Options{}); err == nil {
+		t.Fatal("expected an error in the imported module")
+	}
+
+	m, _, err := testCheck(t, dir, "unchanged", input, Options{
+		Unchanged: func(path string, source []byte) bool {
+			return filepath.Base(path) == "util.jet" && string(source) == util
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// The body of 'larger' refers to the generic, so it is checked and
+	// the instance is owned by the imported module as without skipping.
+	instances := 0
+	for def := m.Imports[0].Defs.Front(); def != nil; def = def.Next() {
+		if fn, _ := def.Value.(*Func); fn != nil && fn.Generic() != nil {
+			instances++
+		}
+	}
+
+	if instances != 1 {
+		t.Errorf("expected 1 instance in the imported module, got %d", instances)
+	}
+}
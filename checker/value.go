@@ -8,6 +8,7 @@ import (
 
 	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/types"
 )
 
@@ -88,7 +89,25 @@ func (check *Checker) valueOfInternal(expr ast.Node) *TypedValue {
 			return nil
 		}
 
-		check.errorf(node, "identifier is undefined")
+		if check.scope.isInvalid(node.Name) {
+			return nil
+		}
+
+		err := newErrorf(node, diag.UndefinedName, "identifier is undefined")
+		err.suggestName(node, visibleNames(check.scope))
+		check.addError(err)
+
+	case *ast.Dot:
+		// Constant of the imported module.
+		if ident, _ := node.X.(*ast.Ident); ident != nil {
+			if m, _ := check.symbolOf(ident).(*Module); m != nil {
+				if _const, _ := m.Scope.LookupLocal(node.Y.Name).(*Const); _const != nil {
+					check.newUse(ident, m)
+					check.newUse(node.Y, _const)
+					return _const.value
+				}
+			}
+		}
 
 	case *ast.Op:
 		if node.X == nil {
@@ -142,7 +161,7 @@ func (check *Checker) resolveBuiltInCall(node *ast.BuiltIn, call *ast.Call) *Typ
 		return b.name == node.Name
 	})
 	if idx == -1 {
-		check.errorf(node, "unknown built-in function '%s'", node.Repr())
+		check.errorf(node, diag.UnknownBuiltIn, "unknown built-in function '%s'", node.Repr())
 		return nil
 	}
 
@@ -165,7 +184,7 @@ func (check *Checker) resolveBuiltInCall(node *ast.BuiltIn, call *ast.Call) *Typ
 			n = call.Args.Nodes[idx]
 		}
 
-		check.errorf(n, err.Error())
+		check.errorf(n, argsErrorCode(err), err.Error())
 		return nil
 	}
 
@@ -237,7 +256,7 @@ func comptimeBinaryOp(x, y constant.Value, opKind ast.OperatorKind) constant.Val
 		switch x.Kind() {
 		case constant.Int:
 			x, y := constant.AsInt(x), constant.AsInt(y)
-			return constant.NewBigInt(new(big.Int).Div(x, y))
+			return constant.NewBigInt(new(big.Int).Quo(x, y))
 
 		case constant.Float:
 			x, y := constant.AsFloat(x), constant.AsFloat(y)
@@ -251,7 +270,7 @@ func comptimeBinaryOp(x, y constant.Value, opKind ast.OperatorKind) constant.Val
 		switch x.Kind() {
 		case constant.Int:
 			x, y := constant.AsInt(x), constant.AsInt(y)
-			return constant.NewBigInt(new(big.Int).Mod(x, y))
+			return constant.NewBigInt(new(big.Int).Rem(x, y))
 
 		default:
 			panic("unreachable")
@@ -359,6 +378,10 @@ func comptimeBinaryOp(x, y constant.Value, opKind ast.OperatorKind) constant.Val
 			x, y := constant.AsFloat(x), constant.AsFloat(y)
 			return constant.NewBool(x.Cmp(y) == 0)
 
+		case constant.String:
+			x, y := constant.AsString(x), constant.AsString(y)
+			return constant.NewBool(*x == *y)
+
 		default:
 			panic("unreachable")
 		}
@@ -2,6 +2,7 @@ package checker
 
 import (
 	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/types"
 )
@@ -41,12 +42,14 @@ func (check *Checker) resolveVarDecl(node *ast.Decl) {
 	// 'tValue' can be nil.
 	tValue, ok := check.resolveVarValue(node.Value)
 	if !ok {
+		check.markInvalidDecl(node)
 		return
 	}
 
 	// 'tType' cannot be nil.
 	tType := check.resolveVarType(node.Type, tValue)
 	if tType == nil {
+		check.markInvalidDecl(node)
 		return
 	}
 
@@ -57,12 +60,15 @@ func (check *Checker) resolveVarDecl(node *ast.Decl) {
 	report.TaggedDebugf("checker", "var specified type: %s", tType)
 
 	if tValue != nil && !tValue.Equals(tType) {
-		check.errorf(
-			node.Value,
+		err := newErrorf(
+			node.Value, diag.TypeMismatch,
 			"type mismatch, expected '%s', got '%s'",
 			tType,
 			tValue,
 		)
+		err.suggestCast(node.Value, tValue, tType)
+		check.addError(err)
+		check.markInvalidDecl(node)
 		return
 	}
 
@@ -96,7 +102,7 @@ func (check *Checker) resolveVarValue(value ast.Node) (types.Type, bool) {
 		}
 
 		if types.IsTypeDesc(t) {
-			check.errorf(value, "expected value, got type '%s' instead", t)
+			check.errorf(value, diag.ExpectedValue, "expected value, got type '%s' instead", t)
 			return nil, false
 		}
 
@@ -124,7 +130,7 @@ func (check *Checker) resolveVarType(typeExpr ast.Node, value types.Type) types.
 	}
 
 	if typedesc == nil {
-		check.errorf(typeExpr, "expression is not a type")
+		check.errorf(typeExpr, diag.ExpectedTypeExpr, "expression is not a type")
 		return nil
 	}
 
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"path/filepath"
+
+	"github.com/saffage/jet/checker"
 	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/interp"
 	"github.com/saffage/jet/report"
 	"github.com/urfave/cli/v2"
 )
@@ -14,6 +18,11 @@ func Run(args []string) error {
 			Aliases:            []string{"r"},
 			DisableDefaultText: true,
 		},
+		&cli.BoolFlag{
+			Name:               "debug-info",
+			Usage:              "emit debug information that maps the executable to Jet sources",
+			DisableDefaultText: true,
+		},
 		&cli.BoolFlag{
 			Name:               "parse-ast",
 			Usage:              "display program AST of the specified module and exit",
@@ -40,6 +49,27 @@ func Run(args []string) error {
 			DefaultText: "",
 		},
 	}
+	runFlags := append([]cli.Flag{
+		&cli.BoolFlag{
+			Name:               "interp",
+			Usage:              "execute the program with the interpreter instead of compiling it",
+			Aliases:            []string{"i"},
+			DisableDefaultText: true,
+		},
+	}, buildFlags[1:]...) // Build flags, except '--run'.
+	parseAstFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Usage:   "output `FORMAT` (json, sexpr or repr)",
+			Aliases: []string{"f"},
+			Value:   "json",
+		},
+		&cli.BoolFlag{
+			Name:               "trace-parser",
+			Usage:              "trace parser calls (used for debugging)",
+			DisableDefaultText: true,
+		},
+	}
 	appFlags := []cli.Flag{
 		&cli.BoolFlag{
 			Name:               "debug",
@@ -51,6 +81,16 @@ func Run(args []string) error {
 			Usage:              "disable compiler hints",
 			DisableDefaultText: true,
 		},
+		&cli.StringFlag{
+			Name:  "diagnostics-format",
+			Usage: "output `FORMAT` of errors and warnings (text, json or sarif)",
+			Value: "text",
+		},
+		&cli.IntFlag{
+			Name:  "max-errors",
+			Usage: "maximum number of the reported errors, 0 means no limit",
+			Value: 20,
+		},
 		&cli.BoolFlag{
 			Name:  "no-core-lib",
 			Usage: "disable the language core library",
@@ -61,6 +101,11 @@ func Run(args []string) error {
 			Value:   "./lib",
 			EnvVars: []string{"JETLIB"},
 		},
+		&cli.StringFlag{
+			Name:    "search-path",
+			Usage:   "list of `DIRS` in which imported modules are searched, separated by the OS path list separator",
+			EnvVars: []string{"JETPATH"},
+		},
 		&cli.StringFlag{
 			Name:    "cache-dir",
 			Usage:   "compiler cache directory",
@@ -70,7 +115,7 @@ func Run(args []string) error {
 	}
 	app := &cli.App{
 		Name:    "jet",
-		Version: "0.0.1",
+		Version: config.Version,
 		Flags:   appFlags,
 		Before:  beforeCommand,
 		Commands: []*cli.Command{
@@ -84,13 +129,63 @@ func Run(args []string) error {
 				Before:          beforeBuild,
 			},
 			{
-				Name:   "parse-ast",
-				Action: actionParseAst,
+				Name:            "run",
+				Usage:           "build and run the specified file",
+				Args:            true,
+				ArgsUsage:       " <FILEPATH>",
+				HideHelpCommand: true,
+				Flags:           runFlags,
+				Action:          actionRun,
+				Before:          beforeRun,
+			},
+			{
+				Name:            "repl",
+				Usage:           "start the interactive interpreter",
+				HideHelpCommand: true,
+				Action:          actionRepl,
+			},
+			{
+				Name:            "lsp",
+				Usage:           "start the language server (communicates over stdio)",
+				HideHelpCommand: true,
+				Action:          actionLsp,
+			},
+			{
+				Name:            "fix",
+				Usage:           "apply the fixes suggested by the compiler to the specified file",
+				Args:            true,
+				ArgsUsage:       " <FILEPATH>",
+				HideHelpCommand: true,
+				Action:          actionFix,
+			},
+			{
+				Name:            "explain",
+				Usage:           "print the explanation of the diagnostic code, or the list of all codes",
+				Args:            true,
+				ArgsUsage:       " [CODE]",
+				HideHelpCommand: true,
+				Action:          actionExplain,
+			},
+			{
+				Name:            "parse-ast",
+				Usage:           "print AST of the specified file",
+				Args:            true,
+				ArgsUsage:       " <FILEPATH>",
+				HideHelpCommand: true,
+				Flags:           parseAstFlags,
+				Action:          actionParseAst,
 			},
 		},
 	}
 
-	config.Global.MaxErrors = 3
+	// Collected messages must be written even if the command exits
+	// with a non-zero code.
+	exit := cli.OsExiter
+	cli.OsExiter = func(code int) {
+		report.Flush()
+		exit(code)
+	}
+
 	config.Global.Files = map[config.FileID]config.FileInfo{}
 	return app.Run(args)
 }
@@ -99,9 +194,17 @@ func beforeCommand(ctx *cli.Context) error {
 	config.Global.Flags.Debug = ctx.Bool("debug")
 	config.Global.Flags.NoHints = ctx.Bool("no-hints")
 	config.Global.Flags.NoCoreLib = ctx.Bool("no-core-lib")
+	config.Global.MaxErrors = ctx.Int("max-errors")
 	config.Global.Options.CoreLibPath = ctx.Path("core-lib-path")
+	config.Global.Options.SearchPath = filepath.SplitList(ctx.String("search-path"))
 	config.Global.Options.CacheDir = ctx.String("cache-dir")
 
+	format, err := report.ParseFormat(ctx.String("diagnostics-format"))
+	if err != nil {
+		return err
+	}
+	report.OutputFormat = format
+
 	switch {
 	case config.Global.Flags.Debug:
 		report.Level = report.KindDebug
@@ -115,3 +218,9 @@ func beforeCommand(ctx *cli.Context) error {
 
 	return nil
 }
+
+// Returns the options of the checker. Compile-time expressions are
+// evaluated by the interpreter.
+func checkOptions() checker.Options {
+	return checker.Options{Evaluator: interp.Comptime}
+}
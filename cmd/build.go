@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/saffage/jet/cgen"
 	"github.com/saffage/jet/checker"
@@ -24,11 +25,33 @@ func Build(cfg *config.Config) error {
 
 	report.Debugf("set file '%s' as main module", path)
 
-	if err := internalBuild(cfg, config.MainFileID); err != nil {
+	dir := filepath.Join(filepath.Dir(path), cfg.Options.CacheDir)
+	err := os.Mkdir(dir, os.ModePerm)
+	if err != nil && !os.IsExist(err) {
 		return err
 	}
 
-	if err := compileToC(cfg, filepath.Dir(path), name); err != nil {
+	if cfg.Flags.ParseAst {
+		_, err := checker.CheckFile(cfg, config.MainFileID, checkOptions())
+		return err
+	}
+
+	cache := loadManifest(cfg, dir)
+	objects, upToDate := cache.upToDateObjects(path, cfg.Files[config.MainFileID].Buf.Bytes())
+
+	if upToDate {
+		report.Hintf("module '%s' is up to date", name)
+	} else {
+		if objects, err = internalBuild(cfg, config.MainFileID, dir, cache); err != nil {
+			return err
+		}
+
+		if err := cache.save(dir); err != nil {
+			report.TaggedWarningf("cache", "while writing manifest: %s", err)
+		}
+	}
+
+	if err := linkObjects(cfg, name, objects); err != nil {
 		return err
 	}
 
@@ -58,6 +81,7 @@ func Build(cfg *config.Config) error {
 
 func beforeBuild(ctx *cli.Context) error {
 	config.Global.Flags.Run = ctx.Bool("run")
+	config.Global.Flags.DebugInfo = ctx.Bool("debug-info")
 	config.Global.Flags.DumpCheckerState = ctx.Bool("dump-checker-state")
 	config.Global.Flags.ParseAst = ctx.Bool("parse-ast")
 	config.Global.Flags.TraceParser = ctx.Bool("trace-parser")
@@ -68,6 +92,16 @@ func beforeBuild(ctx *cli.Context) error {
 }
 
 func actionBuild(ctx *cli.Context) error {
+	if err := setMainFile(ctx); err != nil {
+		return err
+	}
+
+	return Build(config.Global)
+}
+
+// Reads the file passed as the argument of the command and sets it as
+// the main module.
+func setMainFile(ctx *cli.Context) error {
 	if !ctx.Args().Present() {
 		return errors.New("expected path to a file")
 	}
@@ -88,51 +122,116 @@ func actionBuild(ctx *cli.Context) error {
 		Buf:  bytes.NewBuffer(data),
 	}
 
-	return Build(config.Global)
+	return nil
 }
 
-func internalBuild(cfg *config.Config, fileID config.FileID) error {
+// Checks the module and compiles it and all of its imports into object
+// files. Object files of unchanged imported modules are reused. Returns
+// object files to link.
+//
+// Only the declarations of unchanged imported modules are checked, the
+// bodies of their functions are skipped (see [checker.Options.Unchanged]),
+// and generating and compiling them is skipped too. If neither the module
+// nor its imports are changed, [Build] skips the check of the module,
+// see [manifest.upToDateObjects].
+func internalBuild(
+	cfg *config.Config,
+	fileID config.FileID,
+	dir string,
+	cache *manifest,
+) ([]string, error) {
 	if err := checker.CheckBuiltInPkgs(cfg); err != nil {
-		return err
+		return nil, err
+	}
+
+	opts := checkOptions()
+	opts.Unchanged = func(path string, source []byte) bool {
+		_, unchanged := cache.upToDateObjects(path, source)
+		return unchanged
 	}
 
-	m, err := checker.CheckFile(cfg, fileID)
+	m, err := checker.CheckFile(cfg, fileID, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	finfo := cfg.Files[fileID]
-	dir := filepath.Join(filepath.Dir(finfo.Path), cfg.Options.CacheDir)
-	err = os.Mkdir(dir, os.ModePerm)
-	if err != nil && !os.IsExist(err) {
-		return err
+	modules := importOrder(m)
+	files := make([]string, len(modules))
+	objects := make([]string, len(modules))
+
+	for i, module := range modules {
+		if module != m && cache.isCompiled(module, cfg) {
+			report.TaggedDebugf("cache", "module '%s' is unchanged", module.QualifiedName())
+			objects[i] = cache.Modules[absPath(cfg.Files[module.FileID()].Path)].Object
+			continue
+		}
+
+		if files[i], err = genModule(module, dir); err != nil {
+			return nil, err
+		}
 	}
 
-	for _, importedModule := range m.Imports {
-		if err := genModule(importedModule, dir); err != nil {
-			return err
+	if err := compileAll(cfg, files, objects); err != nil {
+		return nil, err
+	}
+
+	for i, module := range modules {
+		if files[i] != "" {
+			cache.update(module, cfg, files[i], objects[i])
 		}
 	}
 
-	return genModule(m, dir)
+	return objects, nil
 }
 
-func genModule(m *checker.Module, dir string) error {
-	filename := filepath.Join(dir, m.Name()+".c")
-	f, err := os.Create(filename)
+// Returns the module and all modules imported by it, directly or not.
+// Imported modules are placed before the modules that import them.
+func importOrder(m *checker.Module) []*checker.Module {
+	modules := []*checker.Module{}
+	visited := map[*checker.Module]bool{}
+
+	var visit func(*checker.Module)
+	visit = func(m *checker.Module) {
+		if visited[m] {
+			return
+		}
+
+		visited[m] = true
+
+		for _, imported := range m.Imports {
+			visit(imported)
+		}
+
+		modules = append(modules, m)
+	}
+
+	visit(m)
+	return modules
+}
+
+// Generates the C file and the header of the module.
+func genModule(m *checker.Module, dir string) (string, error) {
+	filename := filepath.Join(dir, cgen.ModuleName(m)+".c")
+	src, err := os.Create(filename)
 	if err != nil {
-		return err
+		return "", err
+	}
+	defer src.Close()
+
+	header, err := os.Create(filepath.Join(dir, cgen.HeaderName(m)))
+	if err != nil {
+		return "", err
 	}
-	defer f.Close()
+	defer header.Close()
 
-	report.Hintf("generating module '%s'", m.Name())
+	report.Hintf("generating module '%s'", m.QualifiedName())
 	report.TaggedDebugf("gen", "module file is '%s'", filename)
 
-	if err := cgen.Generate(f, m); err != nil {
-		return err
+	if err := cgen.Generate(src, header, m); err != nil {
+		return "", err
 	}
 
-	return nil
+	return filename, nil
 }
 
 func readFile(path string) (name string, data []byte, err error) {
@@ -168,9 +267,64 @@ func readFile(path string) (name string, data []byte, err error) {
 	return
 }
 
-func compileToC(cfg *config.Config, dir, moduleName string) error {
-	file := filepath.Join(dir, cfg.Options.CacheDir, moduleName+".c")
-	args := []string{"-o", moduleName, file}
+// Compiles C files in parallel. Object file of each compiled file
+// is stored into `objects` at the same index. Empty file names are
+// skipped.
+func compileAll(cfg *config.Config, files, objects []string) error {
+	wg := sync.WaitGroup{}
+	errs := make([]error, len(files))
+	sem := make(chan struct{}, runtime.NumCPU())
+
+	for i, filename := range files {
+		if filename == "" {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			objects[i], errs[i] = compileToC(cfg, filename)
+		}()
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Compiles the C file into an object file placed next to it.
+func compileToC(cfg *config.Config, filename string) (string, error) {
+	object := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".o"
+	args := []string{"-c", "-o", object, filename}
+
+	if cfg.Flags.DebugInfo {
+		args = append(args, "-g", "-O0")
+	}
+
+	if len(cfg.Options.CCFlags) > 0 {
+		args = append(args, strings.Split(cfg.Options.CCFlags, " ")...)
+	}
+
+	cmd := exec.Command(cfg.Options.CC, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	report.TaggedHint("cc", cmd.String())
+
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	return object, nil
+}
+
+func linkObjects(cfg *config.Config, name string, objects []string) error {
+	args := append([]string{"-o", name}, objects...)
+
+	if cfg.Flags.DebugInfo {
+		args = append(args, "-g")
+	}
 
 	if len(cfg.Options.LDFlags) > 0 {
 		args = append(args, strings.Split(cfg.Options.LDFlags, " ")...)
@@ -179,7 +333,7 @@ func compileToC(cfg *config.Config, dir, moduleName string) error {
 	cmd := exec.Command(cfg.Options.CC, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	report.TaggedHint("cc", cmd.String())
+	report.TaggedHint("ld", cmd.String())
 
 	if err := cmd.Run(); err != nil {
 		return err
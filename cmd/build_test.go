@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/report"
+)
+
+func TestDefer(t *testing.T) {
+	testRun(t, "defer")
+}
+
+func TestComptime(t *testing.T) {
+	testRun(t, "comptime")
+}
+
+func TestRuntime(t *testing.T) {
+	testRun(t, "runtime")
+}
+
+func TestClosures(t *testing.T) {
+	testRun(t, "closures")
+}
+
+func TestLoops(t *testing.T) {
+	testRun(t, "loops")
+}
+
+func TestTuples(t *testing.T) {
+	testRun(t, "tuples")
+}
+
+func TestImports(t *testing.T) {
+	testRun(t, "imports")
+}
+
+// Each module is compiled into a separate object file, 'main' of the
+// imported module must not be linked as the entry point.
+func TestEntry(t *testing.T) {
+	testRun(t, "entry")
+}
+
+// Builds the program 'testdata/<name>.jet', runs it and compares its
+// output with 'testdata/<name>.out'.
+func testRun(t *testing.T, name string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("C compiler is not found")
+	}
+
+	cfg, expected := setupTest(t, name)
+	buildAndRun(t, cfg, name, expected)
+}
+
+// Local variables keep their names in the debug info, they must not
+// collide with C keywords and with the names used by the generated code.
+func TestDebugInfo(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("C compiler is not found")
+	}
+
+	cfg, expected := setupTest(t, "debug_info")
+	cfg.Flags.DebugInfo = true
+	buildAndRun(t, cfg, "debug_info", expected)
+}
+
+func buildAndRun(t *testing.T, cfg *config.Config, name string, expected []byte) {
+	t.Helper()
+
+	if err := Build(cfg); err != nil {
+		t.Fatal("unexpected build error:", err)
+	}
+
+	output, err := exec.Command(filepath.Join(filepath.Dir(cfg.Files[config.MainFileID].Path), name)).Output()
+	if err != nil {
+		t.Fatal("unexpected run error:", err)
+	}
+
+	if !bytes.Equal(output, expected) {
+		t.Errorf("unexpected output\nexpect:\n%s\nactual:\n%s", expected, output)
+	}
+}
+
+// Copies the program 'testdata/<name>.jet' and the modules from the
+// directory 'testdata/<name>', if it exists, to the temporary directory,
+// which becomes the working directory, and sets it as the main module.
+// Modules from 'testdata/jetpath' are found through the search path.
+// Returns the config and the expected output of the program from
+// 'testdata/<name>.out'.
+func setupTest(t *testing.T, name string) (*config.Config, []byte) {
+	t.Helper()
+
+	expected, err := os.ReadFile(filepath.Join("testdata", name+".out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return setupProgram(t, name), expected
+}
+
+// Sets up the program as [setupTest] does, for the programs that are
+// not expected to run.
+func setupProgram(t *testing.T, name string) *config.Config {
+	t.Helper()
+
+	src, err := os.ReadFile(filepath.Join("testdata", name+".jet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	libDir, err := filepath.Abs("../lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	searchDir, err := filepath.Abs(filepath.Join("testdata", "jetpath"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modulesDir, err := filepath.Abs(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The executable is written to the working directory.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	path := filepath.Join(dir, name+".jet")
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if stat, err := os.Stat(modulesDir); err == nil && stat.IsDir() {
+		copyDir(t, modulesDir, dir)
+	}
+
+	cfg := &config.Config{
+		Files: map[config.FileID]config.FileInfo{
+			config.MainFileID: {Name: name, Path: path, Buf: bytes.NewBuffer(src)},
+		},
+		Options: config.Options{
+			CacheDir:    ".jet-cache",
+			CoreLibPath: libDir,
+			SearchPath:  []string{searchDir},
+			CC:          "gcc",
+		},
+	}
+	config.Global = cfg
+
+	return cfg
+}
+
+// Copies the files of the directory 'src' to the directory 'dst'.
+func copyDir(t *testing.T, src, dst string) {
+	t.Helper()
+
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Instances of the generic declarations of an imported module are
+// generated in the module that requires them, the cached object of the
+// imported module is linked with them.
+func TestGenerics(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("C compiler is not found")
+	}
+
+	cfg, expected := setupTest(t, "generics")
+
+	if generated := testBuildAgain(t, cfg, expected); !slices.Equal(generated, []string{"generics", "util"}) {
+		t.Errorf("expected both modules to be generated, got %q", generated)
+	}
+
+	appendLine(t, cfg.Files[config.MainFileID].Path)
+
+	if generated := testBuildAgain(t, cfg, expected); !slices.Equal(generated, []string{"generics"}) {
+		t.Errorf("expected the object of 'util' to be reused, got %q", generated)
+	}
+}
+
+func TestBuildCache(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("C compiler is not found")
+	}
+
+	cfg, expected := setupTest(t, "imports")
+	path := cfg.Files[config.MainFileID].Path
+
+	steps := []struct {
+		name     string
+		change   func()
+		expected []string
+	}{
+		{
+			name:     "first build",
+			change:   func() {},
+			expected: []string{"extra.more", "imports", "net.http", "util.strs"},
+		},
+		{
+			name:     "nothing is changed",
+			change:   func() {},
+			expected: []string{},
+		},
+		{
+			name:     "main module is changed",
+			change:   func() { appendLine(t, path) },
+			expected: []string{"imports"},
+		},
+		{
+			// The main module and 'net.http' import 'util.strs'.
+			name:     "imported module is changed",
+			change:   func() { appendLine(t, filepath.Join(filepath.Dir(path), "util", "strs.jet")) },
+			expected: []string{"imports", "net.http", "util.strs"},
+		},
+		{
+			name:     "object file is removed",
+			change:   func() { os.Remove(filepath.Join(filepath.Dir(path), ".jet-cache", "extra__more.o")) },
+			expected: []string{"extra.more", "imports"},
+		},
+	}
+
+	for _, step := range steps {
+		step.change()
+
+		if generated := testBuildAgain(t, cfg, expected); !slices.Equal(generated, step.expected) {
+			t.Errorf("%s: expected generated modules %q, got %q", step.name, step.expected, generated)
+		}
+	}
+
+	// 'extra.more' is found in the other directory of the search path,
+	// the sources of the previously built modules are not changed.
+	otherDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(otherDir, "extra"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte("answer := () -> i32 {\n\t43\n}\n")
+	if err := os.WriteFile(filepath.Join(otherDir, "extra", "more.jet"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Options.SearchPath = []string{otherDir}
+	expected = append(bytes.TrimSuffix(expected, []byte("42\n")), "43\n"...)
+
+	allModules := []string{"extra.more", "imports", "net.http", "util.strs"}
+	if generated := testBuildAgain(t, cfg, expected); !slices.Equal(generated, allModules) {
+		t.Errorf("search path is changed: expected generated modules %q, got %q", allModules, generated)
+	}
+}
+
+// Builds the program set up by [setupTest] with a new config, as a new
+// compiler process does, and compares its output with the expected one.
+// Returns the names of the generated modules.
+func testBuildAgain(t *testing.T, cfg *config.Config, expected []byte) []string {
+	t.Helper()
+
+	main := cfg.Files[config.MainFileID]
+	src, err := os.ReadFile(main.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg = &config.Config{
+		Files: map[config.FileID]config.FileInfo{
+			config.MainFileID: {Name: main.Name, Path: main.Path, Buf: bytes.NewBuffer(src)},
+		},
+		Options: cfg.Options,
+	}
+	config.Global = cfg
+
+	generated := []string{}
+	report.Handler = func(d report.Diagnostic) {
+		if name, ok := strings.CutPrefix(d.Message, "generating module "); ok {
+			generated = append(generated, strings.Trim(name, "'"))
+		}
+	}
+	defer func() { report.Handler = nil }()
+
+	if err := Build(cfg); err != nil {
+		t.Fatal("unexpected build error:", err)
+	}
+
+	output, err := exec.Command(filepath.Join(filepath.Dir(main.Path), main.Name)).Output()
+	if err != nil {
+		t.Fatal("unexpected run error:", err)
+	}
+
+	if !bytes.Equal(output, expected) {
+		t.Errorf("unexpected output\nexpect:\n%s\nactual:\n%s", expected, output)
+	}
+
+	slices.Sort(generated)
+	return generated
+}
+
+// Appends an empty line to the file, so its hash is changed.
+func appendLine(t *testing.T, path string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString("\n"); err != nil {
+		t.Fatal(err)
+	}
+}
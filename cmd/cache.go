@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/report"
+)
+
+const manifestFileName = "manifest.json"
+
+// Describes the state of the cache directory after the last build.
+type manifest struct {
+	// Environment of the build. When any of these values is changed,
+	// all modules are rebuilt.
+	Compiler   string // Compiler version and a hash of the executable.
+	CoreLib    string // Hash of the core library files.
+	CC         string
+	CCFlags    string
+	Debug      bool     // Whether modules are compiled with debug information.
+	SearchPath []string // Absolute paths of the directories of the search path.
+
+	Modules map[string]*manifestModule // Indexed by the module path.
+}
+
+type manifestModule struct {
+	Name    string
+	Hash    string            // Hash of the module source.
+	Imports map[string]string // Hashes of all imported modules (including transitive imports), indexed by their path.
+	CFile   string            // Path to the generated C file.
+	Object  string            // Path to the compiled object file.
+}
+
+func loadManifest(cfg *config.Config, dir string) *manifest {
+	env := &manifest{
+		Compiler:   compilerHash(),
+		CoreLib:    coreLibHash(cfg),
+		CC:         cfg.Options.CC,
+		CCFlags:    cfg.Options.CCFlags,
+		Debug:      cfg.Flags.DebugInfo,
+		SearchPath: searchPath(cfg),
+		Modules:    map[string]*manifestModule{},
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			report.TaggedWarningf("cache", "while reading manifest: %s", err)
+		}
+		return env
+	}
+
+	m := &manifest{}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		report.TaggedWarningf("cache", "invalid manifest, the cache will be rebuilt: %s", err)
+		return env
+	}
+
+	if m.Compiler != env.Compiler ||
+		m.CoreLib != env.CoreLib ||
+		m.CC != env.CC ||
+		m.CCFlags != env.CCFlags ||
+		m.Debug != env.Debug ||
+		!slices.Equal(m.SearchPath, env.SearchPath) ||
+		m.Modules == nil {
+		report.TaggedDebugf("cache", "build environment was changed, the cache will be rebuilt")
+		return env
+	}
+
+	return m
+}
+
+func (m *manifest) save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, manifestFileName), data, 0o644)
+}
+
+// Reports whether the module at the specified path and all of its imports
+// are unchanged since the last build, so cached object files can be linked
+// without checking the module. Returns object files of the module and all
+// of its imports.
+func (m *manifest) upToDateObjects(path string, source []byte) ([]string, bool) {
+	entry := m.Modules[absPath(path)]
+
+	if entry == nil || entry.Hash != hashBytes(source) || !fileExists(entry.Object) {
+		return nil, false
+	}
+
+	objects := []string{}
+
+	for importPath, hash := range entry.Imports {
+		data, err := os.ReadFile(importPath)
+		if err != nil || hashBytes(data) != hash {
+			return nil, false
+		}
+
+		imported := m.Modules[importPath]
+		if imported == nil || imported.Hash != hash || !fileExists(imported.Object) {
+			return nil, false
+		}
+
+		objects = append(objects, imported.Object)
+	}
+
+	slices.Sort(objects)
+	return append(objects, entry.Object), true
+}
+
+// Reports whether the object file of the checked module is still valid
+// for the current sources.
+func (m *manifest) isCompiled(module *checker.Module, cfg *config.Config) bool {
+	finfo := cfg.Files[module.FileID()]
+	entry := m.Modules[absPath(finfo.Path)]
+
+	return entry != nil &&
+		entry.Hash == hashBytes(finfo.Buf.Bytes()) &&
+		sameImports(entry.Imports, importHashes(module, cfg)) &&
+		fileExists(entry.CFile) &&
+		fileExists(entry.Object)
+}
+
+// Records the state of the checked module.
+func (m *manifest) update(module *checker.Module, cfg *config.Config, cFile, object string) {
+	finfo := cfg.Files[module.FileID()]
+	entry := &manifestModule{
+		Name:    module.QualifiedName(),
+		Hash:    hashBytes(finfo.Buf.Bytes()),
+		Imports: importHashes(module, cfg),
+		CFile:   absPath(cFile),
+		Object:  absPath(object),
+	}
+
+	m.Modules[absPath(finfo.Path)] = entry
+}
+
+// Returns hashes of all modules imported by the specified module.
+func importHashes(module *checker.Module, cfg *config.Config) map[string]string {
+	hashes := map[string]string{}
+	stack := append([]*checker.Module{}, module.Imports...)
+
+	for len(stack) > 0 {
+		imported := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		finfo := cfg.Files[imported.FileID()]
+		path := absPath(finfo.Path)
+
+		if _, visited := hashes[path]; visited {
+			continue
+		}
+
+		hashes[path] = hashBytes(finfo.Buf.Bytes())
+		stack = append(stack, imported.Imports...)
+	}
+
+	return hashes
+}
+
+func sameImports(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for path, hash := range a {
+		if b[path] != hash {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns the search path with absolute directories. Imported modules
+// can be resolved to other files when it is changed, even if the sources
+// are the same.
+func searchPath(cfg *config.Config) []string {
+	dirs := make([]string, len(cfg.Options.SearchPath))
+	for i, dir := range cfg.Options.SearchPath {
+		dirs[i] = absPath(dir)
+	}
+	return dirs
+}
+
+// Paths in the manifest are absolute, so the compiler can be called
+// from any directory.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func fileExists(path string) bool {
+	stat, err := os.Stat(path)
+	return err == nil && stat.Mode().IsRegular()
+}
+
+var compilerHash = sync.OnceValue(func() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return config.Version
+	}
+
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		return config.Version
+	}
+
+	return config.Version + "+" + hashBytes(data)
+})
+
+func coreLibHash(cfg *config.Config) string {
+	if cfg.Flags.NoCoreLib {
+		return ""
+	}
+
+	dir := filepath.Join(cfg.Options.CoreLibPath, "core")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.New()
+
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				report.TaggedWarningf("cache", "while reading '%s': %s", entry.Name(), err)
+			}
+			continue
+		}
+
+		hash.Write([]byte(entry.Name()))
+		hash.Write(data)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
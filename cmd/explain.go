@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/saffage/jet/diag"
+	"github.com/urfave/cli/v2"
+)
+
+func actionExplain(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		return errors.New("invalid arguments count (expected 0 or 1)")
+	}
+
+	return Explain(os.Stdout, ctx.Args().First())
+}
+
+// Writes the explanation of the diagnostic code to the writer. If the
+// code is empty, writes the list of all codes with their summaries.
+func Explain(w io.Writer, name string) error {
+	if name == "" {
+		for _, code := range diag.Codes() {
+			fmt.Fprintf(w, "%s  %s\n", code, code.Summary())
+		}
+		return nil
+	}
+
+	code, ok := diag.Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown diagnostic code '%s' (see 'jet explain' for the list of codes)", name)
+	}
+
+	_, err := fmt.Fprintf(w, "%s\n\n%s\n", code, code.Explanation())
+	return err
+}
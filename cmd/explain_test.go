@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/report"
+)
+
+// The erroneous example of every explanation must produce the
+// diagnostic with its code.
+func TestExplainExamples(t *testing.T) {
+	libDir, err := filepath.Abs("../lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, code := range diag.Codes() {
+		t.Run(string(code), func(t *testing.T) {
+			example, ok := firstExample(code.Explanation())
+			if !ok {
+				t.Fatal("the explanation has no example")
+			}
+
+			path := filepath.Join(t.TempDir(), "example.jet")
+			if err := os.WriteFile(path, []byte(example), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &config.Config{
+				Files: map[config.FileID]config.FileInfo{
+					config.MainFileID: {Name: "example", Path: path, Buf: bytes.NewBufferString(example)},
+				},
+				Options: config.Options{CoreLibPath: libDir},
+			}
+			config.Global = cfg
+
+			codes := []string{}
+			report.Handler = func(d report.Diagnostic) {
+				codes = append(codes, d.Code)
+			}
+			defer func() { report.Handler = nil }()
+
+			if err := checker.CheckBuiltInPkgs(cfg); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			_, err := checker.CheckFile(cfg, config.MainFileID, checkOptions())
+			report.Errors(err)
+
+			if !slices.Contains(codes, string(code)) {
+				t.Errorf("expected a diagnostic with the code %s, got %q\n%s", code, codes, example)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	output := &bytes.Buffer{}
+
+	if err := Explain(output, "e0301"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !strings.HasPrefix(output.String(), "E0301\n\n"+diag.TypeMismatch.Explanation()) {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	if err := Explain(output, "E9999"); err == nil {
+		t.Error("expected an error for the unknown code")
+	}
+}
+
+// Returns the first 'jet' code block of the explanation.
+func firstExample(explanation string) (string, bool) {
+	_, example, ok := strings.Cut(explanation, "```jet\n")
+	if !ok {
+		return "", false
+	}
+	example, _, ok = strings.Cut(example, "```")
+	return example, ok
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/report"
+	"github.com/urfave/cli/v2"
+)
+
+func actionFix(ctx *cli.Context) error {
+	if err := setMainFile(ctx); err != nil {
+		return err
+	}
+
+	_, err := Fix(config.Global, os.Stdout)
+	return err
+}
+
+// Checks the main file and applies the fixes suggested by the
+// diagnostics to it. The applied fixes are listed in the writer.
+// Returns the number of the applied fixes.
+func Fix(cfg *config.Config, out io.Writer) (int, error) {
+	fixes := []report.Fix{}
+	handler := report.Handler
+	report.Handler = func(d report.Diagnostic) {
+		fixes = append(fixes, d.Fixes...)
+	}
+
+	err := checker.CheckBuiltInPkgs(cfg)
+	if err == nil {
+		_, err = checker.CheckFile(cfg, config.MainFileID, checkOptions())
+		report.Errors(err)
+	}
+
+	report.Handler = handler
+
+	file := cfg.Files[config.MainFileID]
+	result, applied := report.ApplyFixes(file.Buf.Bytes(), config.MainFileID, fixes)
+
+	if len(applied) == 0 {
+		if err != nil {
+			return 0, fmt.Errorf("the file has errors that cannot be fixed automatically")
+		}
+		fmt.Fprintln(out, "nothing to fix")
+		return 0, nil
+	}
+
+	stat, statErr := os.Stat(file.Path)
+	if statErr != nil {
+		return 0, statErr
+	}
+
+	if err := os.WriteFile(file.Path, result, stat.Mode().Perm()); err != nil {
+		return 0, err
+	}
+
+	for _, fix := range applied {
+		fmt.Fprintf(out, "%s: %s\n", fix.Start, fix.Message)
+	}
+
+	return len(applied), nil
+}
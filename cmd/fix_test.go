@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/saffage/jet/config"
+)
+
+// Applies the fixes to 'testdata/fix.jet' and compares the fixed file
+// with 'testdata/fix.out'.
+func TestFix(t *testing.T) {
+	cfg, expected := setupTest(t, "fix")
+
+	count, err := Fix(cfg, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 6 {
+		t.Errorf("expected 6 fixes, got %d", count)
+	}
+
+	fixed, err := os.ReadFile(cfg.Files[config.MainFileID].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(fixed, expected) {
+		t.Errorf("unexpected result\nexpect:\n%s\nactual:\n%s", expected, fixed)
+	}
+}
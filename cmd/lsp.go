@@ -0,0 +1,13 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/lsp"
+	"github.com/urfave/cli/v2"
+)
+
+func actionLsp(ctx *cli.Context) error {
+	return lsp.NewServer(config.Global).Serve(os.Stdin, os.Stdout)
+}
@@ -1,11 +1,83 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"path/filepath"
 
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/parser"
+	"github.com/saffage/jet/scanner"
 	"github.com/urfave/cli/v2"
 )
 
 func actionParseAst(ctx *cli.Context) error {
-	return errors.New("'parse-ast' command is not implemented")
+	if !ctx.Args().Present() {
+		return errors.New("expected path to a file")
+	}
+
+	if ctx.Args().Len() != 1 {
+		return errors.New("invalid arguments count (expected 1)")
+	}
+
+	path := filepath.Clean(ctx.Args().Get(0))
+	name, data, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	config.Global.Files[config.MainFileID] = config.FileInfo{
+		Name: name,
+		Path: path,
+		Buf:  bytes.NewBuffer(data),
+	}
+
+	parserFlags := parser.DefaultFlags
+
+	if ctx.Bool("trace-parser") {
+		parserFlags |= parser.Trace
+	}
+
+	// Comments are not skipped, so the parser can attach them to the
+	// declarations.
+	tokens, err := scanner.Scan(data, config.MainFileID, scanner.SkipWhitespace)
+	if err != nil {
+		return err
+	}
+
+	stmts, err := parser.Parse(tokens, parserFlags)
+	if err != nil {
+		return err
+	}
+
+	if stmts == nil {
+		stmts = &ast.StmtList{}
+	}
+
+	switch format := ctx.String("format"); format {
+	case "json":
+		out, err := ast.EncodeJSON(stmts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(out))
+
+	case "sexpr":
+		fmt.Println(ast.SExpr(stmts))
+
+	case "repr":
+		for _, node := range stmts.Nodes {
+			if _, isEmpty := node.(*ast.Empty); !isEmpty {
+				fmt.Println(node.Repr())
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown output format '%s' (expected 'json', 'sexpr' or 'repr')", format)
+	}
+
+	return nil
 }
@@ -0,0 +1,370 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/interp"
+	"github.com/saffage/jet/parser"
+	"github.com/saffage/jet/report"
+	"github.com/saffage/jet/scanner"
+	"github.com/saffage/jet/token"
+	"github.com/urfave/cli/v2"
+)
+
+const replHelp = `Enter a declaration to add it to the session, or an expression to
+print its value and type.
+
+Commands:
+  :type <expr>    print the type of the expression without evaluating it
+  :ast <expr>     print the AST of the expression
+  :load <path>    check and declare the declarations of the file
+  :help           print this message
+  :quit           exit the REPL
+`
+
+type repl struct {
+	cfg    *config.Config
+	out    io.Writer
+	prompt bool // Whether the prompts are printed.
+
+	check   *checker.Session
+	session *interp.Session
+}
+
+func actionRepl(ctx *cli.Context) error {
+	stat, err := os.Stdin.Stat()
+	prompt := err == nil && stat.Mode()&os.ModeCharDevice != 0
+
+	code, err := Repl(config.Global, os.Stdin, os.Stdout, prompt)
+	if err != nil {
+		return err
+	}
+
+	if code != 0 {
+		return cli.Exit("", code)
+	}
+
+	return nil
+}
+
+// Reads declarations, expressions and commands from the input until
+// it ends or the ':quit' command is entered. Values of the expressions
+// are printed to the output, errors are reported and do not stop the
+// REPL. Returns the exit code passed to 'exit', if it was called.
+func Repl(cfg *config.Config, in io.Reader, out io.Writer, prompt bool) (int, error) {
+	if err := checker.CheckBuiltInPkgs(cfg); err != nil {
+		return 0, err
+	}
+
+	check := checker.NewSession(cfg, "repl", checkOptions())
+	r := &repl{
+		cfg:     cfg,
+		out:     out,
+		prompt:  prompt,
+		check:   check,
+		session: interp.NewSession(check.Module(), out),
+	}
+
+	lines := bufio.NewScanner(in)
+	input := strings.Builder{}
+
+	for {
+		if input.Len() == 0 {
+			r.printPrompt(">>> ")
+		} else {
+			r.printPrompt("... ")
+		}
+
+		if !lines.Scan() {
+			break
+		}
+
+		input.WriteString(lines.Text())
+		input.WriteByte('\n')
+
+		if !isComplete(input.String()) {
+			continue
+		}
+
+		line := strings.TrimSpace(input.String())
+		input.Reset()
+
+		if line == ":quit" || line == ":q" {
+			return 0, nil
+		}
+
+		if err := r.exec(line); err != nil {
+			if exit, _ := err.(*interp.ExitError); exit != nil {
+				return exit.Code, nil
+			}
+			report.Errors(err)
+		}
+	}
+
+	r.printPrompt("\n")
+	return 0, lines.Err()
+}
+
+func (r *repl) printPrompt(prompt string) {
+	if r.prompt {
+		fmt.Fprint(r.out, prompt)
+	}
+}
+
+// Executes the input, which is a declaration, an expression or a
+// command.
+func (r *repl) exec(line string) error {
+	if line == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(line, ":") {
+		return r.eval(r.newFile(line))
+	}
+
+	command, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch command {
+	case ":type":
+		expr, err := r.parseExpr(r.newFile(arg))
+		if err != nil {
+			return err
+		}
+
+		t, err := r.check.CheckExpr(expr.Pos().FileID, expr)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(r.out, r.check.Module().TypeName(t))
+
+	case ":ast":
+		expr, err := r.parseExpr(r.newFile(arg))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(r.out, expr.Repr())
+
+	case ":load":
+		return r.load(arg)
+
+	case ":help":
+		fmt.Fprint(r.out, replHelp)
+
+	default:
+		return fmt.Errorf("unknown command '%s', enter ':help' for the list of commands", command)
+	}
+
+	return nil
+}
+
+// Registers the input as a file, so the reported errors can refer to it.
+func (r *repl) newFile(input string) config.FileID {
+	fileID := config.NextFileID()
+	r.cfg.Files[fileID] = config.FileInfo{
+		Name: "repl",
+		Path: "<repl>",
+		Buf:  bytes.NewBufferString(input),
+	}
+	return fileID
+}
+
+func (r *repl) eval(fileID config.FileID) error {
+	tokens, err := scan(r.cfg, fileID)
+	if err != nil {
+		return err
+	}
+
+	if isDecl(tokens) {
+		return r.declare(tokens)
+	}
+
+	expr, err := r.parseExpr(fileID)
+	if err != nil {
+		return err
+	}
+
+	t, err := r.check.CheckExpr(fileID, expr)
+	if err != nil {
+		return err
+	}
+
+	value, err := r.session.Eval(expr)
+	if err != nil {
+		return err
+	}
+
+	if value != "" {
+		fmt.Fprintf(r.out, "%s: %s\n", value, r.check.Module().TypeName(t))
+	}
+
+	return nil
+}
+
+func (r *repl) declare(tokens []token.Token) error {
+	stmts, err := parser.Parse(tokens, parser.DefaultFlags)
+	if err != nil {
+		return err
+	}
+	if stmts == nil {
+		return nil
+	}
+
+	if err := r.check.CheckDecls(tokens[0].Start.FileID, stmts); err != nil {
+		return err
+	}
+
+	return r.session.Declare()
+}
+
+func (r *repl) load(path string) error {
+	if path == "" {
+		return errors.New("expected path to a file")
+	}
+
+	path = filepath.Clean(path)
+	name, data, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	fileID := config.NextFileID()
+	r.cfg.Files[fileID] = config.FileInfo{
+		Name: name,
+		Path: path,
+		Buf:  bytes.NewBuffer(data),
+	}
+
+	tokens, err := scan(r.cfg, fileID)
+	if err != nil {
+		return err
+	}
+
+	return r.declare(tokens)
+}
+
+// Parses the input as a single expression.
+func (r *repl) parseExpr(fileID config.FileID) (ast.Node, error) {
+	tokens, err := scan(r.cfg, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := parser.ParseExpr(tokens, parser.DefaultFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	// The parser stops after the expression, so the rest of the input
+	// is checked here.
+	end := expr.PosEnd()
+
+	for _, tok := range tokens {
+		if tok.Kind != token.EOF && (tok.Start.Line > end.Line ||
+			tok.Start.Line == end.Line && tok.Start.Char > end.Char) {
+			return nil, &replError{tok.Start, tok.End, "unexpected token after the expression"}
+		}
+	}
+
+	return expr, nil
+}
+
+// Error in the input of the REPL that is not reported by the parser.
+type replError struct {
+	start, end token.Pos
+	message    string
+}
+
+func (err *replError) Error() string {
+	return err.message
+}
+
+func (err *replError) Report() {
+	report.TaggedErrorAt("repl", err.start, err.end, err.message)
+}
+
+func scan(cfg *config.Config, fileID config.FileID) ([]token.Token, error) {
+	return scanner.Scan(cfg.Files[fileID].Buf.Bytes(), fileID, scanner.SkipWhitespace|scanner.SkipComments)
+}
+
+// Reports whether the tokens start a declaration: 'name: T', 'name := x'
+// or a declaration with 'mut' or attributes.
+func isDecl(tokens []token.Token) bool {
+	switch {
+	case len(tokens) == 0:
+		return false
+
+	case tokens[0].Kind == token.KwMut, tokens[0].Kind == token.At:
+		return true
+
+	case len(tokens) > 1:
+		return tokens[0].Kind == token.Ident && tokens[1].Kind == token.Colon
+	}
+
+	return false
+}
+
+// Reports whether all brackets of the input are closed and the input
+// does not end with an attribute list, so the input can be executed.
+// Otherwise, the REPL reads the next line.
+func isComplete(input string) bool {
+	tokens, _ := scanner.Scan([]byte(input), 0, scanner.SkipWhitespace|scanner.SkipComments)
+	depth := 0
+	attrs := false // Whether the last closed brackets are the attribute list.
+
+	for i, tok := range tokens {
+		switch tok.Kind {
+		case token.LParen, token.LCurly, token.LBracket:
+			depth++
+
+		case token.RParen, token.RCurly, token.RBracket:
+			depth--
+
+			if depth == 0 {
+				attrs = tok.Kind == token.RBracket && isAttrList(tokens[:i+1])
+				continue
+			}
+
+		case token.NewLine, token.EOF:
+			continue
+		}
+
+		if depth == 0 {
+			attrs = false
+		}
+	}
+
+	return depth <= 0 && !attrs
+}
+
+// Reports whether the tokens end with the attribute list that is not
+// a part of an expression.
+func isAttrList(tokens []token.Token) bool {
+	depth := 0
+
+	for i := len(tokens) - 1; i >= 0; i-- {
+		switch tokens[i].Kind {
+		case token.RBracket:
+			depth++
+
+		case token.LBracket:
+			if depth--; depth == 0 {
+				return i > 0 && tokens[i-1].Kind == token.At
+			}
+		}
+	}
+
+	return false
+}
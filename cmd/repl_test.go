@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saffage/jet/config"
+)
+
+func TestRepl(t *testing.T) {
+	input := `x := 5
+x * 2 + 1
+Point := struct {
+	x: i32
+	y: i32
+}
+p := Point(x = 1, y = 2)
+p
+p.x + p.y
+sq := (n: i32) -> i32 { n * n }
+sq(7)
+:type sq(2)
+:ast sq(2) + 1
+undefined
+(1, "a")
+$println(42)
+@[extern_c]
+exit: (code: i32) -> ()
+exit(3)
+1
+`
+	expected := `11: i32
+Point(x = 1, y = 2): Point
+3: i32
+49: i32
+i32
+sq(2) + 1
+(1, "a"): (untyped int, untyped string)
+42
+`
+
+	libDir, err := filepath.Abs("../lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Files:   map[config.FileID]config.FileInfo{},
+		Options: config.Options{CoreLibPath: libDir},
+	}
+	config.Global = cfg
+
+	output := &bytes.Buffer{}
+
+	code, err := Repl(cfg, strings.NewReader(input), output, false)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if code != 3 {
+		t.Errorf("expected exit code 3, got %d", code)
+	}
+
+	if output.String() != expected {
+		t.Errorf("unexpected output\nexpect:\n%s\nactual:\n%s", expected, output)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/interp"
+	"github.com/saffage/jet/report"
+	"github.com/urfave/cli/v2"
+)
+
+// Checks the main module and executes it with the interpreter, so no C
+// compiler is required. Returns the exit code of the program.
+func Interpret(cfg *config.Config, stdout io.Writer) (int, error) {
+	if err := checker.CheckBuiltInPkgs(cfg); err != nil {
+		return 0, err
+	}
+
+	m, err := checker.CheckFile(cfg, config.MainFileID, checkOptions())
+	if err != nil {
+		return 0, err
+	}
+
+	report.Hintf("interpreting module '%s'", m.Name())
+	return interp.Run(m, stdout)
+}
+
+func beforeRun(ctx *cli.Context) error {
+	if err := beforeBuild(ctx); err != nil {
+		return err
+	}
+
+	config.Global.Flags.Run = true
+	config.Global.Flags.Interp = ctx.Bool("interp")
+	return nil
+}
+
+func actionRun(ctx *cli.Context) error {
+	if err := setMainFile(ctx); err != nil {
+		return err
+	}
+
+	if !config.Global.Flags.Interp || config.Global.Flags.ParseAst {
+		return Build(config.Global)
+	}
+
+	code, err := Interpret(config.Global, os.Stdout)
+	if runtimeErr := (*interp.RuntimeError)(nil); errors.As(err, &runtimeErr) {
+		// The program is terminated by the error, the exit code must
+		// not be 0 as for the compiled program.
+		report.Errors(err)
+		report.Flush()
+		return cli.Exit("", code)
+	}
+	if err != nil {
+		return err
+	}
+
+	if code != 0 {
+		return cli.Exit("", code)
+	}
+
+	return nil
+}
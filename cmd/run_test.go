@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/saffage/jet/report"
+	"github.com/urfave/cli/v2"
+)
+
+// Programs must have the same output whether they are compiled or
+// interpreted, so the interpreter is tested with the same programs.
+func TestInterp(t *testing.T) {
+	for _, name := range []string{"defer", "comptime", "runtime", "closures", "loops", "tuples", "imports", "entry", "generics"} {
+		t.Run(name, func(t *testing.T) {
+			testInterp(t, name)
+		})
+	}
+}
+
+// Interprets the program 'testdata/<name>.jet' and compares its output
+// with 'testdata/<name>.out'.
+func testInterp(t *testing.T, name string) {
+	t.Helper()
+
+	cfg, expected := setupTest(t, name)
+	output := &bytes.Buffer{}
+
+	code, err := Interpret(cfg, output)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if code != 0 {
+		t.Errorf("unexpected exit code %d", code)
+	}
+
+	if !bytes.Equal(output.Bytes(), expected) {
+		t.Errorf("unexpected output\nexpect:\n%s\nactual:\n%s", expected, output)
+	}
+}
+
+// Runtime errors of the interpreted program must terminate the compiler
+// with a non-zero exit code, as they terminate the compiled program.
+func TestInterpExitCode(t *testing.T) {
+	libDir, err := filepath.Abs("../lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"assert", "main := () {\n\t$assert(1 == 2)\n}\n"},
+		{"bounds", "main := () {\n\txs := [1, 2, 3]\n\ti := 3\n\t$println(xs[i])\n}\n"},
+	}
+
+	code := 0
+	cli.OsExiter = func(c int) { code = c }
+	defer func() { cli.OsExiter = os.Exit }()
+
+	report.Handler = func(report.Diagnostic) {}
+	defer func() { report.Handler = nil }()
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), test.name+".jet")
+		if err := os.WriteFile(path, []byte(test.src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		code = 0
+		if err := Run([]string{"jet", "--core-lib-path", libDir, "run", "--interp", path}); err == nil {
+			t.Errorf("%s: expected the runtime error", test.name)
+		}
+
+		if code != 1 {
+			t.Errorf("%s: expected exit code 1, got %d", test.name, code)
+		}
+	}
+}
+
+// The error names every module of the cycle.
+func TestImportCycle(t *testing.T) {
+	cfg := setupProgram(t, "import_cycle")
+
+	messages := []string{}
+	report.Handler = func(d report.Diagnostic) {
+		if d.Kind == report.KindError {
+			messages = append(messages, d.Message)
+		}
+	}
+	defer func() { report.Handler = nil }()
+
+	if _, err := Interpret(cfg, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected the import cycle error")
+	}
+
+	const expected = "import cycle is not allowed: import_cycle -> b -> c -> b"
+	if !slices.Contains(messages, expected) {
+		t.Errorf("expected the error %q, got %q", expected, messages)
+	}
+}
@@ -1,5 +1,8 @@
 package config
 
+// Version of the compiler.
+const Version = "0.0.1"
+
 var Global = &Config{}
 
 type Config struct {
@@ -7,7 +10,7 @@ type Config struct {
 	//
 	// The file on which the compiler was called always has the key [MainFileID].
 	Files     map[FileID]FileInfo
-	MaxErrors int
+	MaxErrors int    // Errors after this number are not reported, 0 means no limit.
 	Exe       string // Path to the compiler executable.
 	Flags     Flags
 	Options   Options
@@ -16,17 +19,20 @@ type Config struct {
 type Flags struct {
 	Run              bool // Run a compiled executable.
 	Debug            bool // Enable debug information.
+	DebugInfo        bool // Emit source-level debug information into a compiled executable.
 	NoHints          bool // Disable compiler hints.
 	DumpCheckerState bool // Dump the checker state after checking a specified module.
 	ParseAst         bool // Display program AST of the specified module and exit.
 	TraceParser      bool // Trace parser calls (used for debugging).
 	NoCoreLib        bool // Disable the language core library.
+	Interp           bool // Execute the program with the interpreter instead of compiling it.
 }
 
 type Options struct {
-	CacheDir    string // Compiler cache directory.
-	CoreLibPath string // Path to the language core library.
-	CC          string // Path to a C compiler executable.
-	CCFlags     string // Flags that must be passed to a C compiler.
-	LDFlags     string // Flags that must be passed to a linker.
+	CacheDir    string   // Compiler cache directory.
+	CoreLibPath string   // Path to the language core library.
+	SearchPath  []string // Directories in which imported modules are searched.
+	CC          string   // Path to a C compiler executable.
+	CCFlags     string   // Flags that must be passed to a C compiler.
+	LDFlags     string   // Flags that must be passed to a linker.
 }
@@ -13,11 +13,12 @@ func _() {
 	_ = x[String-2]
 	_ = x[Bool-3]
 	_ = x[Array-4]
+	_ = x[Struct-5]
 }
 
-const _Kind_name = "IntFloatStringBoolArray"
+const _Kind_name = "IntFloatStringBoolArrayStruct"
 
-var _Kind_index = [...]uint8{0, 3, 8, 14, 18, 23}
+var _Kind_index = [...]uint8{0, 3, 8, 14, 18, 23, 29}
 
 func (i Kind) String() string {
 	if i >= Kind(len(_Kind_index)-1) {
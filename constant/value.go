@@ -2,8 +2,11 @@ package constant
 
 import (
 	"fmt"
+	"maps"
 	"math/big"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 type Kind byte
@@ -15,6 +18,7 @@ const (
 	String
 	Bool
 	Array
+	Struct
 )
 
 type Value interface {
@@ -42,11 +46,15 @@ func NewFloat(value float64) Value { return &floatValue{big.NewFloat(value)} }
 func NewString(value string) Value { return &stringValue{value} }
 func NewArray(value []Value) Value { return &arrayValue{value} }
 
+// Creates a struct value from values of its fields.
+func NewStruct(fields map[string]Value) Value { return &structValue{fields} }
+
 func IsInt(value Value) bool    { return value.Kind() == Int }
 func IsFloat(value Value) bool  { return value.Kind() == Float }
 func IsString(value Value) bool { return value.Kind() == String }
 func IsBool(value Value) bool   { return value.Kind() == Bool }
 func IsArray(value Value) bool  { return value.Kind() == Array }
+func IsStruct(value Value) bool { return value.Kind() == Struct }
 
 func AsInt(value Value) *big.Int {
 	if IsInt(value) {
@@ -86,6 +94,14 @@ func AsArray(value Value) *[]Value {
 	return nil
 }
 
+// Returns a copy of the fields of the struct value.
+func AsStruct(value Value) map[string]Value {
+	if IsStruct(value) {
+		return maps.Clone(value.(*structValue).val)
+	}
+	return nil
+}
+
 //------------------------------------------------
 // Value implementation
 //------------------------------------------------
@@ -96,6 +112,7 @@ type (
 	stringValue struct{ val string }
 	boolValue   struct{ val bool }
 	arrayValue  struct{ val []Value }
+	structValue struct{ val map[string]Value }
 )
 
 func (v *intValue) String() string    { return v.val.String() }
@@ -104,14 +121,25 @@ func (v *stringValue) String() string { return strconv.Quote(v.val) }
 func (v *boolValue) String() string   { return strconv.FormatBool(v.val) }
 func (v *arrayValue) String() string  { return fmt.Sprintf("%v", v.val) }
 
+func (v *structValue) String() string {
+	fields := make([]string, 0, len(v.val))
+	for name, value := range v.val {
+		fields = append(fields, fmt.Sprintf("%s = %v", name, value))
+	}
+	slices.Sort(fields)
+	return "(" + strings.Join(fields, ", ") + ")"
+}
+
 func (v *intValue) Kind() Kind    { return Int }
 func (v *floatValue) Kind() Kind  { return Float }
 func (v *stringValue) Kind() Kind { return String }
 func (v *boolValue) Kind() Kind   { return Bool }
 func (v *arrayValue) Kind() Kind  { return Array }
+func (v *structValue) Kind() Kind { return Struct }
 
 func (intValue) implValue()    {}
 func (floatValue) implValue()  {}
 func (stringValue) implValue() {}
 func (boolValue) implValue()   {}
 func (arrayValue) implValue()  {}
+func (structValue) implValue() {}
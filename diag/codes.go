@@ -0,0 +1,97 @@
+// Package diag defines the stable codes of the compiler diagnostics
+// and their explanations, which are printed by 'jet explain'.
+package diag
+
+// Code identifies a kind of the diagnostic. Codes of the errors
+// start with 'E', codes of the warnings start with 'W'. A code is
+// never reused for a different kind of the diagnostic.
+type Code string
+
+// Scanner errors.
+const (
+	IllegalCharacter   Code = "E0001"
+	InvalidEscape      Code = "E0002"
+	UnterminatedString Code = "E0003"
+	InvalidNumber      Code = "E0004"
+)
+
+// Parser errors.
+const (
+	UnexpectedToken       Code = "E0101"
+	UnclosedBracket       Code = "E0102"
+	ExpectedExpr          Code = "E0103"
+	ExpectedBlock         Code = "E0104"
+	ExpectedDecl          Code = "E0105"
+	ExpectedIdent         Code = "E0106"
+	InvalidBinaryOperator Code = "E0107"
+)
+
+// Checker errors: names and declarations.
+const (
+	UndefinedName      Code = "E0201"
+	AlreadyDefined     Code = "E0202"
+	InvalidDecl        Code = "E0203"
+	NotAssignable      Code = "E0204"
+	CapturedByValue    Code = "E0205"
+	UnsupportedFeature Code = "E0206"
+)
+
+// Checker errors: types.
+const (
+	TypeMismatch     Code = "E0301"
+	ExpectedTypeExpr Code = "E0302"
+	ExpectedValue    Code = "E0303"
+	NoType           Code = "E0304"
+	RecursiveType    Code = "E0305"
+)
+
+// Checker errors: expressions.
+const (
+	InvalidOperator Code = "E0401"
+	InvalidIndex    Code = "E0402"
+	InvalidPointer  Code = "E0403"
+	UnknownMember   Code = "E0404"
+)
+
+// Checker errors: structs and enums.
+const (
+	InvalidStructInit Code = "E0501"
+	InvalidVariant    Code = "E0502"
+	InvalidMatch      Code = "E0503"
+)
+
+// Checker errors: functions and calls.
+const (
+	ArgumentCount   Code = "E0601"
+	NotCallable     Code = "E0602"
+	InvalidFunction Code = "E0603"
+	InvalidGeneric  Code = "E0604"
+	InvalidMethod   Code = "E0605"
+)
+
+// Checker errors: control flow.
+const (
+	InvalidLoop   Code = "E0701"
+	InvalidLabel  Code = "E0702"
+	InvalidReturn Code = "E0703"
+)
+
+// Checker errors: compile-time evaluation.
+const (
+	NotConstant    Code = "E0801"
+	ComptimeFailed Code = "E0802"
+)
+
+// Checker errors: modules, attributes and built-ins.
+const (
+	InvalidImport    Code = "E0901"
+	InvalidAttribute Code = "E0902"
+	UnknownBuiltIn   Code = "E0903"
+)
+
+// Warnings.
+const (
+	Unused      Code = "W0001"
+	Unreachable Code = "W0002"
+	UnknownLint Code = "W0003"
+)
@@ -0,0 +1,985 @@
+package diag
+
+import (
+	"slices"
+	"strings"
+)
+
+// Returns the explanation of the code, or an empty string if the code
+// is unknown. The first 'jet' code block of the explanation is the
+// erroneous example, which produces the diagnostic with this code.
+func (code Code) Explanation() string {
+	return strings.TrimSpace(explanations[code])
+}
+
+// Returns the first sentence of the explanation in a single line.
+func (code Code) Summary() string {
+	paragraph, _, _ := strings.Cut(code.Explanation(), "\n\n")
+	paragraph = strings.ReplaceAll(paragraph, "\n", " ")
+
+	if i := strings.Index(paragraph, ". "); i >= 0 {
+		return paragraph[:i+1]
+	}
+	return paragraph
+}
+
+// Returns the code with the specified name, the name is case
+// insensitive. Reports whether the code exists.
+func Lookup(name string) (Code, bool) {
+	code := Code(strings.ToUpper(strings.TrimSpace(name)))
+	_, ok := explanations[code]
+	return code, ok
+}
+
+// Returns all codes in the ascending order.
+func Codes() []Code {
+	codes := make([]Code, 0, len(explanations))
+	for code := range explanations {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+var explanations = map[Code]string{
+	IllegalCharacter: `
+The source code contains a character that cannot start any token, or
+a byte that is not a part of a valid UTF-8 sequence.
+
+Erroneous code example:
+
+` + "```jet" + `
+x := 1 ~ 2
+` + "```" + `
+
+Remove the character or place it inside of a string literal.
+`,
+
+	InvalidEscape: `
+The string or character literal contains an unknown escape sequence.
+
+Erroneous code example:
+
+` + "```jet" + `
+s := "tab:\q"
+` + "```" + `
+
+Use one of the supported escape sequences, for example '\n', '\t',
+'\\' or '\"'.
+`,
+
+	UnterminatedString: `
+The string literal is not closed before the end of the line.
+
+Erroneous code example:
+
+` + "```jet" + `
+s := "hello
+` + "```" + `
+
+Add the closing quote:
+
+` + "```jet" + `
+s := "hello"
+` + "```" + `
+`,
+
+	InvalidNumber: `
+The number literal is malformed: it has an uppercase base prefix, a
+leading zero, no digits after the point or the prefix, or an invalid
+suffix.
+
+Erroneous code example:
+
+` + "```jet" + `
+x := 0X1F
+` + "```" + `
+
+Base prefixes must be lowercase ('0x', '0b', '0o'):
+
+` + "```jet" + `
+x := 0x1F
+` + "```" + `
+`,
+
+	UnexpectedToken: `
+The parser found a token that cannot appear at this position.
+
+Erroneous code example:
+
+` + "```jet" + `
+f := (a: i32 b: i32) -> i32 { a + b }
+` + "```" + `
+
+The message lists the tokens that were expected. Here, the parameters
+must be separated by commas:
+
+` + "```jet" + `
+f := (a: i32, b: i32) -> i32 { a + b }
+` + "```" + `
+`,
+
+	UnclosedBracket: `
+An opening bracket has no matching closing bracket.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	$println(1
+}
+` + "```" + `
+
+Close the bracket:
+
+` + "```jet" + `
+main := () {
+	$println(1)
+}
+` + "```" + `
+`,
+
+	ExpectedExpr: `
+An expression was expected, but the code ends or continues with
+something else, for example, an operator without the right operand.
+
+Erroneous code example:
+
+` + "```jet" + `
+x := 1 +
+` + "```" + `
+
+Complete the expression:
+
+` + "```jet" + `
+x := 1 + 2
+` + "```" + `
+`,
+
+	ExpectedBlock: `
+A block in curly brackets was expected, for example, the body of
+'if', 'while' or a function.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	if true $println(1)
+}
+` + "```" + `
+
+Bodies of the control flow statements are always blocks:
+
+` + "```jet" + `
+main := () {
+	if true { $println(1) }
+}
+` + "```" + `
+`,
+
+	ExpectedDecl: `
+Only declarations ('name := value', 'name: T = value') and imports
+can appear at the top level of a module. Attributes must be followed
+by a declaration.
+
+Erroneous code example:
+
+` + "```jet" + `
+$println(1)
+` + "```" + `
+
+Move the statement into a function:
+
+` + "```jet" + `
+main := () {
+	$println(1)
+}
+` + "```" + `
+`,
+
+	ExpectedIdent: `
+An identifier was expected, for example, after 'mut' or in the path
+of an import.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	mut := 1
+}
+` + "```" + `
+
+Name the variable:
+
+` + "```jet" + `
+main := () {
+	mut x := 1
+	x += 1
+}
+` + "```" + `
+`,
+
+	InvalidBinaryOperator: `
+The token cannot be used as a binary operator.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	mut flags := 1
+	flags &= 2
+}
+` + "```" + `
+
+Compound assignments are supported only for the arithmetic operators.
+Write the operation explicitly:
+
+` + "```jet" + `
+main := () {
+	mut flags := 1
+	flags = flags & 2
+}
+` + "```" + `
+`,
+
+	UndefinedName: `
+The identifier does not refer to any declaration that is visible at
+this point, or the module has no member with this name.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	$println(count)
+}
+` + "```" + `
+
+Declare the name before using it, check its spelling, or import the
+module that declares it:
+
+` + "```jet" + `
+main := () {
+	count := 1
+	$println(count)
+}
+` + "```" + `
+`,
+
+	AlreadyDefined: `
+The name is already declared in the same scope. This also applies to
+parameters, fields and enum variants.
+
+Erroneous code example:
+
+` + "```jet" + `
+f := (a: i32, a: i32) -> i32 { a }
+` + "```" + `
+
+Give each declaration a distinct name:
+
+` + "```jet" + `
+f := (a: i32, b: i32) -> i32 { a + b }
+` + "```" + `
+`,
+
+	InvalidDecl: `
+The declaration is not allowed in this form, for example, a mutable
+module-level variable or a local constant.
+
+Erroneous code example:
+
+` + "```jet" + `
+mut counter := 0
+` + "```" + `
+
+Module-level declarations are evaluated at compile time, so they
+cannot be mutable. Declare the variable in a function instead:
+
+` + "```jet" + `
+main := () {
+	mut counter := 0
+	counter += 1
+}
+` + "```" + `
+`,
+
+	NotAssignable: `
+The left side of the assignment is not a variable or another
+addressable location, for example, it is a function.
+
+Erroneous code example:
+
+` + "```jet" + `
+handler := () {}
+
+main := () {
+	handler = handler
+}
+` + "```" + `
+
+Declare a variable to assign to it:
+
+` + "```jet" + `
+handler := () {}
+
+main := () {
+	mut current := handler
+	current = handler
+}
+` + "```" + `
+`,
+
+	CapturedByValue: `
+A lambda captures the immutable variables of the enclosing function by
+value, so the lambda cannot assign to them.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	x := 1
+	f := () { x = 2 }
+	f()
+}
+` + "```" + `
+
+Declare the variable with 'mut' to capture it by reference:
+
+` + "```jet" + `
+main := () {
+	mut x := 1
+	f := () { x = 2 }
+	f()
+}
+` + "```" + `
+`,
+
+	UnsupportedFeature: `
+The construct is valid syntax, but it is not supported by the
+compiler yet, for example, a default value of a parameter.
+
+Erroneous code example:
+
+` + "```jet" + `
+f := (a: i32 = 1) -> i32 { a }
+` + "```" + `
+
+Rewrite the code without the construct:
+
+` + "```jet" + `
+f := (a: i32) -> i32 { a }
+` + "```" + `
+`,
+
+	TypeMismatch: `
+The type of the expression differs from the type expected by the
+context: the declared type of a variable, the type of a parameter, the
+result of a function, the condition of 'if', and so on.
+
+Erroneous code example:
+
+` + "```jet" + `
+x: i32 = "one"
+` + "```" + `
+
+Use a value of the expected type, or convert the value with 'as':
+
+` + "```jet" + `
+x: i32 = 1
+y := 1.5 as i32
+` + "```" + `
+`,
+
+	ExpectedTypeExpr: `
+The expression is used as a type, but it is a value.
+
+Erroneous code example:
+
+` + "```jet" + `
+one := 1
+x: one = 1
+` + "```" + `
+
+Use a type:
+
+` + "```jet" + `
+x: i32 = 1
+` + "```" + `
+`,
+
+	ExpectedValue: `
+The expression is used as a value, but it is a type.
+
+Erroneous code example:
+
+` + "```jet" + `
+x := i32
+` + "```" + `
+
+Use a value of the type instead:
+
+` + "```jet" + `
+x: i32 = 0
+` + "```" + `
+`,
+
+	NoType: `
+The type of the expression cannot be determined, for example, the
+expression is a statement that has no value.
+
+Erroneous code example:
+
+` + "```jet" + `
+arr: [_]i32
+` + "```" + `
+
+Specify the type explicitly:
+
+` + "```jet" + `
+arr: [3]i32
+` + "```" + `
+`,
+
+	RecursiveType: `
+The struct contains itself by value, so its size would be infinite.
+
+Erroneous code example:
+
+` + "```jet" + `
+Node := struct {
+	value: i32
+	next: Node
+}
+` + "```" + `
+
+Use a pointer to refer to the same type:
+
+` + "```jet" + `
+Node := struct {
+	value: i32
+	next: *Node
+}
+` + "```" + `
+`,
+
+	InvalidOperator: `
+The operator is not defined for the types of its operands.
+
+Erroneous code example:
+
+` + "```jet" + `
+x := -true
+` + "```" + `
+
+The arithmetic operators are defined only for numbers, use '!' to
+negate a boolean value:
+
+` + "```jet" + `
+x := -1
+y := !true
+` + "```" + `
+`,
+
+	InvalidIndex: `
+The expression cannot be indexed or sliced, or the index has an
+invalid type or is out of bounds.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	x := 1
+	$println(x[0])
+}
+` + "```" + `
+
+Only arrays, slices and tuples can be indexed:
+
+` + "```jet" + `
+main := () {
+	x := [1, 2, 3]
+	$println(x[0])
+}
+` + "```" + `
+`,
+
+	InvalidPointer: `
+The expression is dereferenced with '.*', but it is not a pointer.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	x := 1
+	$println(x.*)
+}
+` + "```" + `
+
+Take the address of the variable with '&' to get a pointer:
+
+` + "```jet" + `
+main := () {
+	x := 1
+	p := &x
+	$println(p.*)
+}
+` + "```" + `
+`,
+
+	UnknownMember: `
+The type has no field, method or variant with this name.
+
+Erroneous code example:
+
+` + "```jet" + `
+Point := struct { x: i32 }
+
+main := () {
+	p := Point(x = 1)
+	$println(p.y)
+}
+` + "```" + `
+
+Check the name of the member:
+
+` + "```jet" + `
+Point := struct { x: i32 }
+
+main := () {
+	p := Point(x = 1)
+	$println(p.x)
+}
+` + "```" + `
+`,
+
+	InvalidStructInit: `
+The struct initializer must specify every field of the struct exactly
+once, by name.
+
+Erroneous code example:
+
+` + "```jet" + `
+Point := struct {
+	x: i32
+	y: i32
+}
+
+p := Point(x = 1)
+` + "```" + `
+
+Specify the missing field:
+
+` + "```jet" + `
+Point := struct {
+	x: i32
+	y: i32
+}
+
+p := Point(x = 1, y = 2)
+` + "```" + `
+`,
+
+	InvalidVariant: `
+The enum variant is used in a way that does not match its declaration,
+for example, a variant with a payload is used without it.
+
+Erroneous code example:
+
+` + "```jet" + `
+Shape := enum {
+	Circle(r: f64)
+	Empty
+}
+
+s := Shape.Circle
+` + "```" + `
+
+Initialize the payload of the variant:
+
+` + "```jet" + `
+Shape := enum {
+	Circle(r: f64)
+	Empty
+}
+
+s := Shape.Circle(r = 1.0)
+` + "```" + `
+`,
+
+	InvalidMatch: `
+The 'match' does not handle every variant of the enum, or one of its
+arms can never be reached.
+
+Erroneous code example:
+
+` + "```jet" + `
+Color := enum { Red; Green; Blue }
+
+name := (c: Color) -> i32 {
+	match c {
+		Red => 1
+		Green => 2
+	}
+}
+` + "```" + `
+
+Add the missing variants, or the '_' arm that matches the rest:
+
+` + "```jet" + `
+Color := enum { Red; Green; Blue }
+
+name := (c: Color) -> i32 {
+	match c {
+		Red => 1
+		_ => 2
+	}
+}
+` + "```" + `
+`,
+
+	ArgumentCount: `
+The number of the arguments differs from the number of the parameters.
+This also applies to type arguments, names of the tuple declaration
+and loop variables.
+
+Erroneous code example:
+
+` + "```jet" + `
+add := (a: i32, b: i32) -> i32 { a + b }
+
+x := add(1)
+` + "```" + `
+
+Pass an argument for each parameter:
+
+` + "```jet" + `
+add := (a: i32, b: i32) -> i32 { a + b }
+
+x := add(1, 2)
+` + "```" + `
+`,
+
+	NotCallable: `
+The called expression is not a function or a struct type.
+
+Erroneous code example:
+
+` + "```jet" + `
+x := 1
+y := x(2)
+` + "```" + `
+
+Only functions, lambdas and struct types (to initialize a struct) can
+be called.
+`,
+
+	InvalidFunction: `
+The function declaration is invalid, for example, an external function
+has a body.
+
+Erroneous code example:
+
+` + "```jet" + `
+@[extern_c]
+abs := (x: i32) -> i32 { x }
+` + "```" + `
+
+The body of an external function is defined in C, declare only its
+signature:
+
+` + "```jet" + `
+@[extern_c]
+abs: (x: i32) -> i32
+` + "```" + `
+`,
+
+	InvalidGeneric: `
+The generic declaration is used incorrectly, for example, its type
+arguments cannot be inferred or it is used without them.
+
+Erroneous code example:
+
+` + "```jet" + `
+id := [T](x: T) -> T { x }
+
+f := id
+` + "```" + `
+
+Generic functions must be called or instantiated with the type
+arguments:
+
+` + "```jet" + `
+id := [T](x: T) -> T { x }
+
+x := id(1)
+` + "```" + `
+`,
+
+	InvalidMethod: `
+The method declaration or call is invalid. The first parameter of a
+method is its receiver, it must have the type of the struct or a
+pointer to it.
+
+Erroneous code example:
+
+` + "```jet" + `
+Point := struct {
+	x: i32
+	get := () -> i32 { 0 }
+}
+
+main := () {
+	p := Point(x = 1)
+	$println(p.get())
+}
+` + "```" + `
+
+Add the receiver:
+
+` + "```jet" + `
+Point := struct {
+	x: i32
+	get := (p: Point) -> i32 { p.x }
+}
+
+main := () {
+	p := Point(x = 1)
+	$println(p.get())
+}
+` + "```" + `
+`,
+
+	InvalidLoop: `
+The loop is invalid, for example, the iterated expression is not a
+range, an array, a slice or an iterator.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	for i in 10 {
+		$println(i)
+	}
+}
+` + "```" + `
+
+Iterate over a range:
+
+` + "```jet" + `
+main := () {
+	for i in 0..<10 {
+		$println(i)
+	}
+}
+` + "```" + `
+`,
+
+	InvalidLabel: `
+The label of 'break' or 'continue' does not refer to an enclosing
+loop, or the label is already used by an enclosing loop.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	while true {
+		break outer
+	}
+}
+` + "```" + `
+
+Label the loop:
+
+` + "```jet" + `
+main := () {
+	outer: while true {
+		break outer
+	}
+}
+` + "```" + `
+`,
+
+	InvalidReturn: `
+The value of 'return' does not match the result type of the function.
+
+Erroneous code example:
+
+` + "```jet" + `
+f := () -> i32 {
+	return
+}
+` + "```" + `
+
+Return a value of the result type:
+
+` + "```jet" + `
+f := () -> i32 {
+	return 1
+}
+` + "```" + `
+`,
+
+	NotConstant: `
+The expression must be evaluated at compile time (for example, the
+value of a constant or the size of an array), but it depends on values
+that are only known at run time.
+
+Erroneous code example:
+
+` + "```jet" + `
+@[extern_c]
+rand: () -> i32
+
+@[comptime]
+seed := rand()
+` + "```" + `
+
+External functions, the output and global variables cannot be used at
+compile time, and the value cannot contain pointers, slices or functions.
+Compute the value at run time instead:
+
+` + "```jet" + `
+@[extern_c]
+rand: () -> i32
+
+main := () {
+	seed := rand()
+	$println(seed)
+}
+` + "```" + `
+`,
+
+	ComptimeFailed: `
+The evaluation of the expression at compile time failed, for example,
+because of a division by zero or an infinite loop.
+
+Erroneous code example:
+
+` + "```jet" + `
+div := (a: i32, b: i32) -> i32 { a / b }
+
+@[comptime]
+X := div(1, 0)
+` + "```" + `
+
+Fix the computation, the message describes the reason of the failure.
+`,
+
+	InvalidImport: `
+The imported module cannot be found or checked, or the imports form a
+cycle. The module 'a.b' is the file 'a/b.jet' in the project root, the
+core library ('JETLIB') or one of the directories of the search path
+('JETPATH').
+
+Erroneous code example:
+
+` + "```jet" + `
+import no.such.module
+` + "```" + `
+
+Check the path of the module, or add its directory to the search path
+with '--search-path'.
+`,
+
+	InvalidAttribute: `
+The attribute is unknown, or it is applied to a declaration that does
+not support it.
+
+Erroneous code example:
+
+` + "```jet" + `
+@[inline]
+square := (x: i32) -> i32 { x * x }
+` + "```" + `
+
+The attributes of a function are 'extern_c', 'header' and 'allow':
+
+` + "```jet" + `
+@[allow(unused)]
+square := (x: i32) -> i32 { x * x }
+` + "```" + `
+`,
+
+	UnknownBuiltIn: `
+The name after '$' does not refer to a built-in function.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	$print_line(1)
+}
+` + "```" + `
+
+Check the name of the built-in function:
+
+` + "```jet" + `
+main := () {
+	$println(1)
+}
+` + "```" + `
+`,
+
+	Unused: `
+The local variable, parameter or import is never used, or the function
+of the main module is never called. Functions of the imported modules
+are not reported, they can be called by the importing modules.
+
+Erroneous code example:
+
+` + "```jet" + `
+main := () {
+	x := 1
+}
+` + "```" + `
+
+Remove the declaration, or silence the warning with the attribute
+'@[allow(unused)]':
+
+` + "```jet" + `
+main := () {
+	@[allow(unused)] x := 1
+}
+` + "```" + `
+`,
+
+	Unreachable: `
+The statement follows 'return', 'break' or 'continue' in the same
+block, so it is never executed.
+
+Erroneous code example:
+
+` + "```jet" + `
+f := () {
+	return
+	$println(2)
+}
+
+main := () {
+	f()
+}
+` + "```" + `
+
+Remove the statement, or silence the warning with the attribute
+'@[allow(unreachable)]' on the declaration.
+`,
+
+	UnknownLint: `
+The attribute '@[allow(...)]' contains an unknown lint. The known
+lints are 'unused' and 'unreachable'.
+
+Erroneous code example:
+
+` + "```jet" + `
+@[allow(unusd)]
+main := () {}
+` + "```" + `
+
+Check the name of the lint:
+
+` + "```jet" + `
+@[allow(unused)]
+main := () {}
+` + "```" + `
+`,
+}
@@ -0,0 +1,299 @@
+package interp
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/types"
+)
+
+func (ip *interpreter) callExpr(node *ast.Call) value {
+	if builtIn, _ := node.X.(*ast.BuiltIn); builtIn != nil {
+		return ip.builtInCall(builtIn, node)
+	}
+
+	if dot, _ := node.X.(*ast.Dot); dot != nil && types.IsTypeDesc(ip.typeOf(dot.X)) {
+		if ty := types.AsEnum(ip.typeOf(dot)); ty != nil {
+			payload := ip.structInit(node, ty.Payload(dot.Y.Name))
+			if payload == nil {
+				return nil
+			}
+			return &enumValue{slices.Index(ty.Fields(), dot.Y.Name), payload}
+		}
+	}
+
+	tyCallee := ip.typeOf(node.X)
+
+	if types.IsTypeDesc(tyCallee) {
+		if payload := ip.structInit(node, types.AsStruct(types.SkipTypeDesc(tyCallee))); payload != nil {
+			return payload
+		}
+		return nil
+	}
+
+	var (
+		callee *funcValue
+		args   []value
+	)
+
+	if fn := ip.funcSymbol(node.X); fn != nil {
+		callee = &funcValue{fn: fn}
+	} else {
+		value := ip.eval(node.X)
+		if value == nil {
+			return nil
+		}
+		if callee = value.(*funcValue); callee == nil {
+			ip.fatalf(node.X, "call of the null function value")
+		}
+	}
+
+	if recv, ok := ip.receiver(node.X, callee.fn); ok {
+		if recv == nil {
+			return nil
+		}
+		args = append(args, recv)
+	}
+
+	for _, arg := range node.Args.Nodes {
+		value := ip.eval(arg)
+		if value == nil {
+			return nil
+		}
+		args = append(args, value)
+	}
+
+	return ip.call(node, callee, args)
+}
+
+// Returns the function called by its name, or nil if the callee is a
+// function value.
+func (ip *interpreter) funcSymbol(callee ast.Node) *checker.Func {
+	switch node := callee.(type) {
+	case *ast.Ident:
+		sym, _ := ip.symbolOf(node).(*checker.Func)
+		return sym
+
+	case *ast.Dot:
+		sym, _ := ip.syms[node.Y].(*checker.Func)
+		return sym
+
+	case *ast.Index:
+		return ip.instanceOf(node.X)
+	}
+
+	return nil
+}
+
+// Evaluates the receiver of the method call. The receiver is passed by
+// pointer if the method takes a pointer. Reports false if the callee is
+// not a method.
+func (ip *interpreter) receiver(callee ast.Node, method *checker.Func) (value, bool) {
+	dot, _ := callee.(*ast.Dot)
+	if dot == nil || method == nil || method.MethodOf() == nil {
+		return nil, false
+	}
+
+	tyRecv := ip.typeOf(dot.X)
+	if types.IsTypeDesc(tyRecv) {
+		return nil, false
+	}
+
+	switch tyParam := method.Params()[0].Type(); {
+	case types.IsRef(tyParam) && !types.IsRef(tyRecv):
+		if c := ip.addr(dot.X); c != nil {
+			return c, true
+		}
+		return nil, true
+
+	case !types.IsRef(tyParam) && types.IsRef(tyRecv):
+		ptr := ip.eval(dot.X)
+		if ptr == nil {
+			return nil, true
+		}
+		if ptr.(*cell) == nil {
+			ip.fatalf(dot.X, "null pointer dereference")
+		}
+		return ptr.(*cell).v, true
+	}
+
+	return ip.eval(dot.X), true
+}
+
+// Evaluates 'T(...fields)', the fields that are not initialized have
+// zero values.
+func (ip *interpreter) structInit(node *ast.Call, ty *types.Struct) *structValue {
+	result := zeroStruct(ty)
+
+	for _, init := range node.Args.Nodes {
+		init := init.(*ast.Op)
+		name := init.X.(*ast.Ident).Name
+		index := slices.IndexFunc(ty.Fields(), func(field types.StructField) bool {
+			return field.Name == name
+		})
+
+		value := ip.eval(init.Y)
+		if value == nil {
+			return nil
+		}
+
+		result.fields[index].v = copyValue(ip.convert(init, value, ty.Fields()[index].Type))
+	}
+
+	return result
+}
+
+// Calls the function with the evaluated arguments. The receiver of the
+// method is the first argument.
+func (ip *interpreter) call(node ast.Node, callee *funcValue, args []value) value {
+	var (
+		params []*checker.Var
+		body   ast.Node
+		ty     *types.Func
+	)
+
+	if lambda := callee.lambda; lambda != nil {
+		params, body, ty = lambda.Params(), lambda.Node().Body, lambda.Type()
+	} else {
+		fn := callee.fn
+		if fn.IsExtern() {
+			return ip.externCall(node, fn, args)
+		}
+
+		decl, _ := fn.Node().(*ast.Decl)
+		function, _ := decl.Value.(*ast.Function)
+		if function == nil {
+			ip.fatalf(node, "function '%s' has no body", fn.Name())
+		}
+
+		params, body, ty = fn.Params(), function.Body, types.AsFunc(fn.Type())
+	}
+
+	if ip.depth >= maxCallDepth {
+		ip.fatalf(node, "stack overflow: the call depth exceeded %d", maxCallDepth)
+	}
+
+	vars := ip.vars
+	ip.vars = make(map[*checker.Var]*cell, len(params)+len(callee.env))
+	ip.depth++
+
+	defer func() {
+		ip.vars = vars
+		ip.depth--
+	}()
+
+	for v, c := range callee.env {
+		ip.vars[v] = c
+	}
+
+	for i, param := range params {
+		ip.vars[param] = &cell{copyValue(ip.convert(node, args[i], param.Type()))}
+	}
+
+	result := ip.eval(body)
+
+	if ip.flow == flowReturn {
+		result = ip.result
+		ip.flow, ip.result = flowNext, nil
+	}
+
+	switch tyResult := ty.Result(); tyResult.Len() {
+	case 0:
+		return unitValue
+
+	case 1:
+		return copyValue(ip.convert(node, result, tyResult.Types()[0]))
+
+	default:
+		return copyValue(ip.convert(node, result, tyResult))
+	}
+}
+
+// Creates the function value of the lambda. Variables captured by value
+// are copied, variables captured by reference are shared.
+func (ip *interpreter) lambda(node *ast.Function) value {
+	lambda := ip.lambdas[node]
+	env := make(map[*checker.Var]*cell, len(lambda.Captures()))
+
+	for _, capture := range lambda.Captures() {
+		c := ip.varCell(node, capture.Var)
+		if !capture.ByRef {
+			c = &cell{copyValue(c.v)}
+		}
+		env[capture.Var] = c
+	}
+
+	return &funcValue{lambda: lambda, env: env}
+}
+
+func (ip *interpreter) builtInCall(node *ast.BuiltIn, call *ast.Call) value {
+	args := call.Args.Nodes
+
+	switch node.Name {
+	case "print", "println":
+		if ip.comptime {
+			ip.notConstantf(call, "built-in function '%s' cannot be called at compile time", node.Repr())
+		}
+
+		value := ip.eval(args[0])
+		if value == nil {
+			return nil
+		}
+
+		s, ok := formatValue(value)
+		if !ok {
+			ip.fatalf(call, "'$%s' for the type %s is not implemented", node.Name, ip.typeOf(args[0]))
+		}
+		if node.Name == "println" {
+			s += "\n"
+		}
+
+		ip.out.WriteString(s)
+		return unitValue
+
+	case "assert":
+		cond := ip.eval(args[0])
+		if cond == nil {
+			return nil
+		}
+		if !cond.(bool) {
+			ip.fatalf(call, "assertion failed")
+		}
+		return unitValue
+
+	case "as_ptr":
+		return ip.eval(args[0])
+
+	case "cast":
+		value := ip.eval(args[1])
+		if value == nil {
+			return nil
+		}
+		return ip.convert(call, value, types.SkipTypeDesc(ip.typeOf(args[0])))
+
+	case "size_of":
+		size, _ := layout(types.SkipTypeDesc(ip.typeOf(args[0])))
+		return uint64(size)
+	}
+
+	ip.fatalf(call, "built-in function '%s' cannot be interpreted", node.Repr())
+	return nil
+}
+
+// Returns the text printed by '$print' for the value.
+func formatValue(v value) (string, bool) {
+	switch v := v.(type) {
+	case int64, uint64, string:
+		return fmt.Sprint(v), true
+
+	case float64:
+		return fmt.Sprintf("%f", v), true
+
+	case *enumValue:
+		return fmt.Sprint(v.tag), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,114 @@
+package interp
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/diag"
+	"github.com/saffage/jet/types"
+)
+
+// Expressions of the compile-time constants are evaluated by the same
+// interpreter that runs the program, so the constants have the values
+// the expressions would have at run time. The evaluation must not have
+// side effects, so the external functions, the output and the global
+// variables cannot be used, and the loops are limited by the number
+// of steps.
+
+// Maximum number of the statements and iterations of the loops executed
+// by the compile-time evaluation, so the infinite loop is reported as the
+// error.
+const maxComptimeSteps = 1_000_000
+
+// Evaluates the type checked expression of the module at compile time,
+// it is the [checker.Evaluator] of the programs checked by the compiler.
+// The value is converted to the constant, values of pointers, slices and
+// functions cannot be constants. Errors are returned as [*checker.Error].
+func Comptime(m *checker.Module, expr ast.Node) (result constant.Value, err error) {
+	ip := newInterpreter(m, nil)
+	ip.comptime = true
+
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+
+		case *checker.Error:
+			result, err = nil, r
+
+		case *RuntimeError:
+			result, err = nil, &checker.Error{Message: r.Message, Node: r.Node, Code: diag.ComptimeFailed}
+
+		default:
+			panic(r)
+		}
+	}()
+
+	v := ip.eval(expr)
+	if v == nil || v == unitValue {
+		return nil, nil
+	}
+
+	return ip.constantOf(expr, v, m.TypeOf(expr)), nil
+}
+
+// Stops the compile-time evaluation of the expression that can only be
+// executed at run time.
+func (ip *interpreter) notConstantf(node ast.Node, format string, args ...any) {
+	panic(&checker.Error{
+		Message: fmt.Sprintf(format, args...),
+		Node:    node,
+		Code:    diag.NotConstant,
+	})
+}
+
+// Counts the statement or the iteration of the loop executed at compile
+// time, the evaluation is stopped when the limit is exceeded.
+func (ip *interpreter) step(node ast.Node) {
+	if !ip.comptime {
+		return
+	}
+
+	if ip.steps++; ip.steps > maxComptimeSteps {
+		ip.fatalf(node, "compile-time evaluation exceeded the limit of %d steps", maxComptimeSteps)
+	}
+}
+
+// Converts the value of the type to the constant.
+func (ip *interpreter) constantOf(node ast.Node, v value, t types.Type) constant.Value {
+	switch v := v.(type) {
+	case int64:
+		return constant.NewInt(v)
+
+	case uint64:
+		return constant.NewBigInt(new(big.Int).SetUint64(v))
+
+	case float64:
+		return constant.NewFloat(v)
+
+	case bool:
+		return constant.NewBool(v)
+
+	case string:
+		return constant.NewString(v)
+
+	case *arrayValue:
+		elems := make([]constant.Value, len(v.elems))
+		for i, elem := range v.elems {
+			elems[i] = ip.constantOf(node, elem.v, types.AsArray(t).ElemType())
+		}
+		return constant.NewArray(elems)
+
+	case *structValue:
+		fields := make(map[string]constant.Value, len(v.fields))
+		for i, field := range types.AsStruct(t).Fields() {
+			fields[field.Name] = ip.constantOf(node, v.fields[i].v, field.Type)
+		}
+		return constant.NewStruct(fields)
+	}
+
+	ip.notConstantf(node, "value of type '%s' cannot be used as a constant", t)
+	return nil
+}
@@ -0,0 +1,733 @@
+package interp
+
+import (
+	"math"
+	"slices"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/types"
+)
+
+// Evaluates the expression. Returns nil if the evaluation is stopped by
+// the control flow statement.
+func (ip *interpreter) eval(expr ast.Node) value {
+	if v, ok := ip.consts[expr]; ok {
+		return v
+	}
+
+	if tv, ok := ip.types[expr]; ok && tv != nil && tv.Value != nil {
+		v := fromConstant(tv.Value, tv.Type)
+		if tv.Value.Kind() != constant.Array && tv.Value.Kind() != constant.Struct {
+			ip.consts[expr] = v
+		}
+		return v
+	}
+
+	switch node := expr.(type) {
+	case *ast.Ident:
+		switch sym := ip.symbolOf(node).(type) {
+		case *checker.Var:
+			return ip.varCell(node, sym).v
+
+		case *checker.Func:
+			return &funcValue{fn: sym}
+
+		case *checker.Const:
+			return fromConstant(sym.Value(), sym.Type())
+		}
+
+	case *ast.Call:
+		return ip.callExpr(node)
+
+	case *ast.Function:
+		return ip.lambda(node)
+
+	case *ast.Dot:
+		if fn, _ := ip.syms[node.Y].(*checker.Func); fn != nil {
+			return &funcValue{fn: fn}
+		}
+
+		if ip.isModule(node.X) {
+			return ip.eval(node.Y)
+		}
+
+		tyX := ip.typeOf(node.X)
+
+		if types.IsTypeDesc(tyX) {
+			if ty := types.AsEnum(types.SkipTypeDesc(tyX)); ty != nil {
+				return &enumValue{tag: slices.Index(ty.Fields(), node.Y.Name)}
+			}
+			break
+		}
+
+		if array := types.AsArray(tyX); array != nil {
+			// The only member of an array is its length.
+			return int64(array.Size())
+		}
+
+		if types.IsSlice(tyX) {
+			slice := ip.eval(node.X)
+			if slice == nil {
+				return nil
+			}
+			return int64(len(slice.(*sliceValue).elems))
+		}
+
+		if types.IsStruct(tyX) {
+			if c := ip.addr(node); c != nil {
+				return c.v
+			}
+			return nil
+		}
+
+	case *ast.Deref:
+		if c := ip.addr(node); c != nil {
+			return c.v
+		}
+		return nil
+
+	case *ast.Index:
+		if fn := ip.instanceOf(node.X); fn != nil {
+			// Explicit instantiation of the generic function.
+			return &funcValue{fn: fn}
+		}
+
+		if bounds, _ := node.Args.Nodes[0].(*ast.Op); bounds != nil &&
+			(bounds.Kind == ast.OperatorRangeInclusive || bounds.Kind == ast.OperatorRangeExclusive) {
+			return ip.sliceExpr(node, bounds)
+		}
+
+		if c := ip.addr(node); c != nil {
+			return c.v
+		}
+		return nil
+
+	case *ast.Op:
+		return ip.op(node)
+
+	case *ast.BracketList:
+		tyElem := types.Type(nil)
+		if array := types.AsArray(types.SkipUntyped(ip.typeOf(node))); array != nil {
+			tyElem = array.ElemType()
+		}
+
+		elems := make([]value, len(node.Nodes))
+		for i, elem := range node.Nodes {
+			if elems[i] = ip.eval(elem); elems[i] == nil {
+				return nil
+			}
+			elems[i] = copyValue(ip.convert(elem, elems[i], tyElem))
+		}
+		return &arrayValue{newCells(elems)}
+
+	case *ast.ParenList:
+		if len(node.Nodes) == 0 {
+			return unitValue
+		}
+		if len(node.Nodes) == 1 {
+			return ip.eval(node.Nodes[0])
+		}
+
+		ty := types.AsTuple(types.SkipUntyped(ip.typeOf(node)))
+		elems := make([]value, len(node.Nodes))
+		for i, elem := range node.Nodes {
+			if elems[i] = ip.eval(elem); elems[i] == nil {
+				return nil
+			}
+			if ty != nil {
+				elems[i] = ip.convert(elem, elems[i], ty.Types()[i])
+			}
+			elems[i] = copyValue(elems[i])
+		}
+		return &tupleValue{newCells(elems)}
+
+	case *ast.CurlyList:
+		return ip.block(node.StmtList)
+
+	case *ast.If:
+		cond := ip.eval(node.Cond)
+		if cond == nil {
+			return nil
+		}
+		if cond.(bool) {
+			return ip.eval(node.Body)
+		}
+		if node.Else != nil {
+			return ip.eval(node.Else.Body)
+		}
+		return unitValue
+
+	case *ast.Match:
+		return ip.match(node)
+
+	case *ast.While:
+		return ip.while(node)
+
+	case *ast.For:
+		return ip.forLoop(node)
+
+	case *ast.Return:
+		result := unitValue
+		if node.X != nil {
+			if result = ip.eval(node.X); result == nil {
+				return nil
+			}
+		}
+		ip.flow, ip.result = flowReturn, result
+		return nil
+
+	case *ast.Break:
+		ip.flow, ip.label = flowBreak, labelName(node.Label)
+		return nil
+
+	case *ast.Continue:
+		ip.flow, ip.label = flowContinue, labelName(node.Label)
+		return nil
+
+	case *ast.Defer, *ast.Empty:
+		return unitValue
+	}
+
+	ip.fatalf(expr, "expression cannot be interpreted")
+	return nil
+}
+
+// Returns the cell referred by the expression. If the expression is not
+// addressable, its value is stored to the new cell. Returns nil if the
+// evaluation is stopped by the control flow statement.
+func (ip *interpreter) addr(expr ast.Node) *cell {
+	switch node := expr.(type) {
+	case *ast.Ident:
+		if v, _ := ip.symbolOf(node).(*checker.Var); v != nil {
+			return ip.varCell(node, v)
+		}
+
+	case *ast.ParenList:
+		if len(node.Nodes) == 1 {
+			return ip.addr(node.Nodes[0])
+		}
+
+	case *ast.Deref:
+		ptr := ip.eval(node.X)
+		if ptr == nil {
+			return nil
+		}
+
+		c, ok := ptr.(*cell)
+		switch {
+		case !ok:
+			ip.fatalf(node, "pointer to a string cannot be dereferenced by the interpreter")
+
+		case c == nil:
+			ip.fatalf(node, "null pointer dereference")
+
+		case c.v == nil:
+			// Memory allocated by 'malloc'.
+			c.v = zeroValue(ip.typeOf(node))
+		}
+
+		return c
+
+	case *ast.Dot:
+		if ip.isModule(node.X) {
+			return ip.addr(node.Y)
+		}
+
+		tyX := ip.typeOf(node.X)
+		if !types.IsStruct(tyX) || types.IsTypeDesc(tyX) {
+			break
+		}
+
+		base := ip.addr(node.X)
+		if base == nil {
+			return nil
+		}
+
+		index := slices.IndexFunc(types.AsStruct(tyX).Fields(), func(field types.StructField) bool {
+			return field.Name == node.Y.Name
+		})
+		return base.v.(*structValue).fields[index]
+
+	case *ast.Index:
+		switch ip.typeOf(node.X).Underlying().(type) {
+		case *types.Tuple:
+			base := ip.addr(node.X)
+			if base == nil {
+				return nil
+			}
+			index := ip.eval(node.Args.Nodes[0]).(int64)
+			return base.v.(*tupleValue).elems[index]
+
+		case *types.Array:
+			base := ip.addr(node.X)
+			if base == nil {
+				return nil
+			}
+			index := ip.eval(node.Args.Nodes[0])
+			if index == nil {
+				return nil
+			}
+			elems := base.v.(*arrayValue).elems
+			return elems[ip.checkIndex(node, index, len(elems))]
+
+		case *types.Slice:
+			slice := ip.eval(node.X)
+			if slice == nil {
+				return nil
+			}
+			index := ip.eval(node.Args.Nodes[0])
+			if index == nil {
+				return nil
+			}
+			elems := slice.(*sliceValue).elems
+			return elems[ip.checkIndex(node, index, len(elems))]
+		}
+	}
+
+	v := ip.eval(expr)
+	if v == nil {
+		return nil
+	}
+	return &cell{v}
+}
+
+func (ip *interpreter) checkIndex(node *ast.Index, index value, length int) int {
+	i := toInt(index)
+	if i < 0 || i >= int64(length) {
+		ip.fatalf(node, "index out of bounds: the length is %d but the index is %d", length, i)
+	}
+	return int(i)
+}
+
+// Evaluates 'x[a..b]' and 'x[a..<b]' where 'x' is an array or a slice.
+func (ip *interpreter) sliceExpr(node *ast.Index, bounds *ast.Op) value {
+	var elems []*cell
+
+	if types.IsArray(ip.typeOf(node.X)) {
+		base := ip.addr(node.X)
+		if base == nil {
+			return nil
+		}
+		elems = base.v.(*arrayValue).elems
+	} else {
+		slice := ip.eval(node.X)
+		if slice == nil {
+			return nil
+		}
+		elems = slice.(*sliceValue).elems
+	}
+
+	lo, hi := ip.eval(bounds.X), ip.eval(bounds.Y)
+	if lo == nil || hi == nil {
+		return nil
+	}
+
+	start, end := toInt(lo), toInt(hi)
+	if bounds.Kind == ast.OperatorRangeInclusive {
+		end++
+	}
+
+	if start < 0 || start > end || end > int64(len(elems)) {
+		ip.fatalf(node, "slice bounds out of range: %d..<%d with length %d", start, end, len(elems))
+	}
+
+	return &sliceValue{elems[start:end]}
+}
+
+func (ip *interpreter) op(node *ast.Op) value {
+	ty := ip.typeOf(node)
+
+	if node.X == nil {
+		switch node.Kind {
+		case ast.OperatorAddrOf, ast.OperatorMutAddrOf:
+			if c := ip.addr(node.Y); c != nil {
+				return c
+			}
+			return nil
+
+		case ast.OperatorNot:
+			y := ip.eval(node.Y)
+			if y == nil {
+				return nil
+			}
+			return !y.(bool)
+
+		case ast.OperatorNeg:
+			y := ip.eval(node.Y)
+			if y == nil {
+				return nil
+			}
+			switch y := y.(type) {
+			case int64:
+				return ip.convert(node, -y, promoted(ty))
+
+			case uint64:
+				return ip.convert(node, -y, promoted(ty))
+
+			case float64:
+				return -y
+			}
+		}
+
+		ip.fatalf(node, "operator '%s' cannot be interpreted", node.Kind)
+	}
+
+	switch node.Kind {
+	case ast.OperatorAs:
+		x := ip.eval(node.X)
+		if x == nil {
+			return nil
+		}
+		return ip.convert(node, x, ty)
+
+	case ast.OperatorAnd, ast.OperatorOr:
+		x := ip.eval(node.X)
+		if x == nil {
+			return nil
+		}
+		if x.(bool) == (node.Kind == ast.OperatorOr) {
+			return x
+		}
+		return ip.eval(node.Y)
+
+	case ast.OperatorAssign:
+		y := ip.eval(node.Y)
+		if y == nil {
+			return nil
+		}
+		dest := ip.addr(node.X)
+		if dest == nil {
+			return nil
+		}
+		dest.v = copyValue(ip.convert(node, y, ip.typeOf(node.X)))
+		return unitValue
+	}
+
+	if kind, ok := compoundAssignOps[node.Kind]; ok {
+		y := ip.eval(node.Y)
+		if y == nil {
+			return nil
+		}
+		dest := ip.addr(node.X)
+		if dest == nil {
+			return nil
+		}
+		tyDest := ip.typeOf(node.X)
+		result := ip.binaryOp(node, dest.v, ip.convert(node, y, tyDest), kind)
+		dest.v = ip.convert(node, result, tyDest)
+		return unitValue
+	}
+
+	x := ip.eval(node.X)
+	if x == nil {
+		return nil
+	}
+	y := ip.eval(node.Y)
+	if y == nil {
+		return nil
+	}
+
+	if isComparison(node.Kind) {
+		return ip.binaryOp(node, x, y, node.Kind)
+	}
+
+	// Operands are converted to the type of the result, as they are in
+	// the generated code.
+	ty = types.SkipUntyped(ty)
+	x, y = ip.convert(node.X, x, ty), ip.convert(node.Y, y, ty)
+	return ip.convert(node, ip.binaryOp(node, x, y, node.Kind), promoted(ty))
+}
+
+// Returns the type of the arithmetic on the values of the type. As in C,
+// integers smaller than 'int' are promoted to 'int', so the result is not
+// wrapped until it is stored.
+func promoted(ty types.Type) types.Type {
+	if kind, ok := primitiveKind(ty); ok {
+		switch kind {
+		case types.KindI8, types.KindI16, types.KindU8, types.KindU16, types.KindChar, types.KindBool:
+			return types.I32
+		}
+	}
+	return ty
+}
+
+func isComparison(op ast.OperatorKind) bool {
+	switch op {
+	case ast.OperatorEq, ast.OperatorNe, ast.OperatorLt, ast.OperatorLe, ast.OperatorGt, ast.OperatorGe:
+		return true
+	}
+	return false
+}
+
+var compoundAssignOps = map[ast.OperatorKind]ast.OperatorKind{
+	ast.OperatorAddAssign:    ast.OperatorAdd,
+	ast.OperatorSubAssign:    ast.OperatorSub,
+	ast.OperatorMultAssign:   ast.OperatorMul,
+	ast.OperatorDivAssign:    ast.OperatorDiv,
+	ast.OperatorModAssign:    ast.OperatorMod,
+	ast.OperatorBitAndAssign: ast.OperatorBitAnd,
+	ast.OperatorBitOrAssign:  ast.OperatorBitOr,
+	ast.OperatorBitXorAssign: ast.OperatorBitXor,
+	ast.OperatorBitShlAssign: ast.OperatorBitShl,
+	ast.OperatorBitShrAssign: ast.OperatorBitShr,
+}
+
+// Applies the binary operator to the operands. Operands of different
+// kinds are converted as by the usual arithmetic conversions of C. The
+// result is not wrapped to the size of the type.
+func (ip *interpreter) binaryOp(node ast.Node, x, y value, op ast.OperatorKind) value {
+	switch x := x.(type) {
+	case bool:
+		switch op {
+		case ast.OperatorEq:
+			return x == y.(bool)
+
+		case ast.OperatorNe:
+			return x != y.(bool)
+		}
+
+	case *cell:
+		switch op {
+		case ast.OperatorEq:
+			return x == y.(*cell)
+
+		case ast.OperatorNe:
+			return x != y.(*cell)
+		}
+
+	case *enumValue:
+		switch op {
+		case ast.OperatorEq:
+			return x.tag == y.(*enumValue).tag
+
+		case ast.OperatorNe:
+			return x.tag != y.(*enumValue).tag
+		}
+
+	case int64, uint64, float64:
+		switch {
+		case isFloatValue(x) || isFloatValue(y):
+			return floatOp(node, toFloat(x), toFloat(y), op)
+
+		case isUintValue(x) || isUintValue(y):
+			return ip.uintOp(node, uint64(toInt(x)), uint64(toInt(y)), op)
+		}
+		return ip.intOp(node, toInt(x), toInt(y), op)
+	}
+
+	ip.fatalf(node, "operator '%s' cannot be interpreted", op)
+	return nil
+}
+
+func (ip *interpreter) intOp(node ast.Node, x, y int64, op ast.OperatorKind) value {
+	switch op {
+	case ast.OperatorAdd:
+		return x + y
+
+	case ast.OperatorSub:
+		return x - y
+
+	case ast.OperatorMul:
+		return x * y
+
+	case ast.OperatorDiv, ast.OperatorMod:
+		if y == 0 {
+			ip.fatalf(node, "division by zero")
+		}
+		if op == ast.OperatorDiv {
+			return x / y
+		}
+		return x % y
+
+	case ast.OperatorBitAnd:
+		return x & y
+
+	case ast.OperatorBitOr:
+		return x | y
+
+	case ast.OperatorBitXor:
+		return x ^ y
+
+	case ast.OperatorBitShl, ast.OperatorBitShr:
+		if y < 0 || y >= 64 {
+			ip.fatalf(node, "invalid shift count %d", y)
+		}
+		if op == ast.OperatorBitShl {
+			return x << y
+		}
+		return x >> y
+
+	case ast.OperatorEq:
+		return x == y
+
+	case ast.OperatorNe:
+		return x != y
+
+	case ast.OperatorLt:
+		return x < y
+
+	case ast.OperatorLe:
+		return x <= y
+
+	case ast.OperatorGt:
+		return x > y
+
+	case ast.OperatorGe:
+		return x >= y
+	}
+
+	ip.fatalf(node, "operator '%s' cannot be interpreted", op)
+	return nil
+}
+
+func (ip *interpreter) uintOp(node ast.Node, x, y uint64, op ast.OperatorKind) value {
+	switch op {
+	case ast.OperatorAdd:
+		return x + y
+
+	case ast.OperatorSub:
+		return x - y
+
+	case ast.OperatorMul:
+		return x * y
+
+	case ast.OperatorDiv, ast.OperatorMod:
+		if y == 0 {
+			ip.fatalf(node, "division by zero")
+		}
+		if op == ast.OperatorDiv {
+			return x / y
+		}
+		return x % y
+
+	case ast.OperatorBitAnd:
+		return x & y
+
+	case ast.OperatorBitOr:
+		return x | y
+
+	case ast.OperatorBitXor:
+		return x ^ y
+
+	case ast.OperatorBitShl, ast.OperatorBitShr:
+		if y >= 64 {
+			ip.fatalf(node, "invalid shift count %d", y)
+		}
+		if op == ast.OperatorBitShl {
+			return x << y
+		}
+		return x >> y
+
+	case ast.OperatorEq:
+		return x == y
+
+	case ast.OperatorNe:
+		return x != y
+
+	case ast.OperatorLt:
+		return x < y
+
+	case ast.OperatorLe:
+		return x <= y
+
+	case ast.OperatorGt:
+		return x > y
+
+	case ast.OperatorGe:
+		return x >= y
+	}
+
+	ip.fatalf(node, "operator '%s' cannot be interpreted", op)
+	return nil
+}
+
+func floatOp(node ast.Node, x, y float64, op ast.OperatorKind) value {
+	switch op {
+	case ast.OperatorAdd:
+		return x + y
+
+	case ast.OperatorSub:
+		return x - y
+
+	case ast.OperatorMul:
+		return x * y
+
+	case ast.OperatorDiv:
+		return x / y
+
+	case ast.OperatorMod:
+		return math.Mod(x, y)
+
+	case ast.OperatorEq:
+		return x == y
+
+	case ast.OperatorNe:
+		return x != y
+
+	case ast.OperatorLt:
+		return x < y
+
+	case ast.OperatorLe:
+		return x <= y
+
+	case ast.OperatorGt:
+		return x > y
+
+	case ast.OperatorGe:
+		return x >= y
+	}
+
+	panic(&RuntimeError{node, "operator '" + op.String() + "' cannot be applied to floats"})
+}
+
+func isFloatValue(v value) bool {
+	_, ok := v.(float64)
+	return ok
+}
+
+func isUintValue(v value) bool {
+	_, ok := v.(uint64)
+	return ok
+}
+
+func toInt(v value) int64 {
+	switch v := v.(type) {
+	case int64:
+		return v
+
+	case uint64:
+		return int64(v)
+
+	case float64:
+		return int64(v)
+
+	case bool:
+		if v {
+			return 1
+		}
+	}
+	return 0
+}
+
+func toFloat(v value) float64 {
+	switch v := v.(type) {
+	case int64:
+		return float64(v)
+
+	case uint64:
+		return float64(v)
+
+	case float64:
+		return v
+	}
+	return 0
+}
+
+func labelName(label *ast.Ident) string {
+	if label == nil {
+		return ""
+	}
+	return label.Name
+}
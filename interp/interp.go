@@ -0,0 +1,399 @@
+// Package interp executes type checked modules without compiling them
+// to C. The interpreter walks the AST of the functions and uses the type
+// information recorded by the checker, so it has the same semantics as
+// the generated code, including the wrapping of integers and the bounds
+// checks. The checker uses the interpreter to evaluate the expressions
+// at compile time.
+//
+// Functions with the 'extern_c' attribute are supported only for a small
+// set of the C standard library functions, which are emulated in Go.
+package interp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/report"
+	"github.com/saffage/jet/types"
+)
+
+// Maximum depth of calls, deeper recursion is reported as the stack
+// overflow instead of crashing the interpreter.
+const maxCallDepth = 10_000
+
+// Error that occurred during the execution of the program, such as the
+// out of bounds access or the failed assertion.
+type RuntimeError struct {
+	Node    ast.Node
+	Message string
+}
+
+func (err *RuntimeError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Node.Pos(), err.Message)
+}
+
+func (err *RuntimeError) Report() {
+	report.TaggedErrorAt("runtime", err.Node.Pos(), err.Node.PosEnd(), err.Message)
+}
+
+// Code passed to the 'exit' function, the execution is unwound with it.
+type exitCode int
+
+// Executes the 'main' function of the module. Global variables of the
+// module and of the modules it imports are initialized first. Output of
+// the program is written to the stdout.
+//
+// Returns the exit code of the program, which is 0 unless the program
+// calls 'exit'. Errors of the execution are returned as [*RuntimeError].
+func Run(m *checker.Module, stdout io.Writer) (code int, err error) {
+	main := mainFunc(m)
+	if main == nil {
+		return 0, errors.New("function 'main' is not defined")
+	}
+
+	ip := newInterpreter(m, stdout)
+
+	defer func() {
+		if flushErr := ip.out.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+
+		case *RuntimeError:
+			code, err = 1, r
+
+		case exitCode:
+			code = int(r)
+
+		default:
+			panic(r)
+		}
+	}()
+
+	for _, module := range importOrder(m) {
+		ip.initGlobals(module)
+	}
+
+	ip.call(main.Ident(), &funcValue{fn: main}, nil)
+	return 0, nil
+}
+
+func mainFunc(m *checker.Module) *checker.Func {
+	for def := m.Defs.Front(); def != nil; def = def.Next() {
+		if fn, _ := def.Value.(*checker.Func); fn != nil && fn.Name() == "main" && fn.Owner() == m.Scope {
+			return fn
+		}
+	}
+	return nil
+}
+
+// Returns the module and the modules it imports, every module goes after
+// its imports.
+func importOrder(m *checker.Module) []*checker.Module {
+	modules := []*checker.Module{}
+	visited := map[*checker.Module]bool{}
+
+	var visit func(*checker.Module)
+	visit = func(m *checker.Module) {
+		if visited[m] {
+			return
+		}
+
+		visited[m] = true
+
+		for _, imported := range m.Imports {
+			visit(imported)
+		}
+
+		modules = append(modules, m)
+	}
+
+	visit(m)
+	return modules
+}
+
+type flow byte
+
+const (
+	flowNext flow = iota
+	flowBreak
+	flowContinue
+	flowReturn
+)
+
+type interpreter struct {
+	modules []*checker.Module
+	types   map[ast.Node]*checker.TypedValue
+	syms    map[*ast.Ident]checker.Symbol
+	lambdas map[*ast.Function]*checker.Lambda
+	consts  map[ast.Node]value // Scalar values of constant expressions.
+	globals map[*checker.Var]*cell
+	vars    map[*checker.Var]*cell // Locals of the function being executed.
+	depth   int
+	out     *bufio.Writer // Nil at compile time.
+
+	// The expression is evaluated at compile time, see [Comptime].
+	comptime bool
+	steps    int
+
+	// The control flow statement being executed, the loops and calls
+	// are unwound until the target of the statement is reached.
+	flow   flow
+	label  string // Label of 'break' or 'continue', can be empty.
+	result value  // Value of 'return'.
+
+	// Pointers converted to integers, so the integers can be converted
+	// back to the same pointers.
+	addrs    map[*cell]uint64
+	cells    map[uint64]*cell
+	nextAddr uint64
+
+	seed uint64 // State of 'rand'.
+}
+
+func newInterpreter(m *checker.Module, stdout io.Writer) *interpreter {
+	ip := &interpreter{
+		types:    map[ast.Node]*checker.TypedValue{},
+		syms:     map[*ast.Ident]checker.Symbol{},
+		lambdas:  map[*ast.Function]*checker.Lambda{},
+		consts:   map[ast.Node]value{},
+		globals:  map[*checker.Var]*cell{},
+		vars:     map[*checker.Var]*cell{},
+		addrs:    map[*cell]uint64{},
+		cells:    map[uint64]*cell{},
+		nextAddr: 0x1000,
+		seed:     1,
+	}
+
+	if stdout != nil {
+		ip.out = bufio.NewWriter(stdout)
+	}
+
+	ip.merge(m)
+	return ip
+}
+
+// Merges the type information of the module and the modules it imports.
+// Instances of generics and lambdas can be checked by the module other
+// than the one that declares them, so the type information of all
+// modules is needed.
+func (ip *interpreter) merge(m *checker.Module) {
+	ip.modules = importOrder(m)
+
+	for _, module := range ip.modules {
+		for node, tv := range module.Types {
+			ip.types[node] = tv
+		}
+		for ident, sym := range module.Uses {
+			ip.syms[ident] = sym
+		}
+		for def := module.Defs.Front(); def != nil; def = def.Next() {
+			ip.syms[def.Key] = def.Value
+		}
+		for node, lambda := range module.Lambdas {
+			ip.lambdas[node] = lambda
+		}
+	}
+}
+
+func (ip *interpreter) initGlobals(m *checker.Module) {
+	for def := m.Defs.Front(); def != nil; def = def.Next() {
+		if v, _ := def.Value.(*checker.Var); v != nil && v.IsGlobal() {
+			ip.initGlobal(v)
+		}
+	}
+}
+
+func (ip *interpreter) initGlobal(v *checker.Var) {
+	var value value
+
+	if v.Value() != nil {
+		value = ip.eval(v.Value())
+	} else {
+		value = zeroValue(v.Type())
+	}
+
+	ip.globals[v] = &cell{copyValue(ip.convert(v.Value(), value, v.Type()))}
+}
+
+// Stops the execution with the runtime error.
+func (ip *interpreter) fatalf(node ast.Node, format string, args ...any) {
+	panic(&RuntimeError{node, fmt.Sprintf(format, args...)})
+}
+
+// Reports whether the execution of the block must be stopped because
+// of the control flow statement.
+func (ip *interpreter) stopped() bool {
+	return ip.flow != flowNext
+}
+
+func (ip *interpreter) typeOf(node ast.Node) types.Type {
+	if tv, ok := ip.types[node]; ok && tv != nil {
+		return tv.Type
+	}
+	if ident, _ := node.(*ast.Ident); ident != nil {
+		if sym := ip.syms[ident]; sym != nil {
+			return sym.Type()
+		}
+	}
+	return nil
+}
+
+func (ip *interpreter) symbolOf(ident *ast.Ident) checker.Symbol {
+	return ip.syms[ident]
+}
+
+// Returns the instance of the generic function named 'f' or 'module.f'
+// in the explicit instantiation, or nil.
+func (ip *interpreter) instanceOf(expr ast.Node) *checker.Func {
+	var sym checker.Symbol
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		sym = ip.symbolOf(expr)
+
+	case *ast.Dot:
+		sym = ip.syms[expr.Y]
+	}
+
+	if fn, _ := sym.(*checker.Func); fn != nil && fn.Generic() != nil {
+		return fn
+	}
+	return nil
+}
+
+// Reports whether the node is the name of the imported module.
+func (ip *interpreter) isModule(node ast.Node) bool {
+	ident, _ := node.(*ast.Ident)
+	_, isModule := ip.syms[ident].(*checker.Module)
+	return ident != nil && isModule
+}
+
+// Returns the method of the type declared in any of the modules.
+func (ip *interpreter) methodOf(t types.Type, name string) *checker.Func {
+	for _, module := range ip.modules {
+		if method := module.MethodOf(t, name); method != nil {
+			return method
+		}
+	}
+	return nil
+}
+
+func (ip *interpreter) varCell(node ast.Node, v *checker.Var) *cell {
+	if c, ok := ip.vars[v]; ok {
+		return c
+	}
+	if c, ok := ip.globals[v]; ok {
+		return c
+	}
+	if ip.comptime && v.IsGlobal() {
+		ip.notConstantf(node, "global variable '%s' cannot be used at compile time", v.Name())
+	}
+	ip.fatalf(node, "variable '%s' is used before its initialization", v.Name())
+	return nil
+}
+
+// Converts the value to the type, as the C cast does. Values of other
+// types are returned as is.
+func (ip *interpreter) convert(node ast.Node, v value, t types.Type) value {
+	if t == nil {
+		return v
+	}
+
+	if types.IsRef(t) {
+		return ip.pointer(node, v)
+	}
+
+	if tuple, _ := v.(*tupleValue); tuple != nil {
+		if tyTuple := types.AsTuple(t); tyTuple != nil && tyTuple.Len() == len(tuple.elems) {
+			elems := make([]value, len(tuple.elems))
+			for i, elem := range tuple.elems {
+				elems[i] = ip.convert(node, elem.v, tyTuple.Types()[i])
+			}
+			return &tupleValue{newCells(elems)}
+		}
+		return v
+	}
+
+	kind, ok := primitiveKind(t)
+	if !ok {
+		return v
+	}
+
+	if kind == types.KindPointer {
+		return ip.pointer(node, v)
+	}
+
+	switch v := v.(type) {
+	case int64:
+		return convertInt(v, kind)
+
+	case uint64:
+		return convertUint(v, kind)
+
+	case float64:
+		return convertFloat(v, kind)
+
+	case bool:
+		if kind == types.KindBool || kind == types.KindUntypedBool {
+			return v
+		}
+		if v {
+			return convertInt(1, kind)
+		}
+		return convertInt(0, kind)
+
+	case *cell:
+		return convertUint(ip.address(v), kind)
+
+	case *enumValue:
+		return convertInt(int64(v.tag), kind)
+	}
+
+	return v
+}
+
+// Converts the value to the pointer.
+func (ip *interpreter) pointer(node ast.Node, v value) value {
+	switch v := v.(type) {
+	case int64:
+		return ip.cellAt(node, uint64(v))
+
+	case uint64:
+		return ip.cellAt(node, v)
+	}
+	return v
+}
+
+// Returns the integer representation of the pointer.
+func (ip *interpreter) address(c *cell) uint64 {
+	if c == nil {
+		return 0
+	}
+	if addr, ok := ip.addrs[c]; ok {
+		return addr
+	}
+	addr := ip.nextAddr
+	ip.nextAddr += 16
+	ip.addrs[c], ip.cells[addr] = addr, c
+	return addr
+}
+
+func (ip *interpreter) cellAt(node ast.Node, addr uint64) *cell {
+	if addr == 0 {
+		return nil
+	}
+	if c, ok := ip.cells[addr]; ok {
+		return c
+	}
+	ip.fatalf(node, "invalid pointer 0x%x", addr)
+	return nil
+}
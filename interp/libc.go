@@ -0,0 +1,198 @@
+package interp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/types"
+)
+
+type libcFunc func(ip *interpreter, node ast.Node, args []value) value
+
+// Functions of the C standard library that can be called by the
+// interpreted program, by their C names. Memory allocated by 'malloc'
+// is a single cell, which holds a value of any type.
+var libc = map[string]libcFunc{
+	"malloc":  libcMalloc,
+	"calloc":  libcMalloc,
+	"free":    libcFree,
+	"exit":    libcExit,
+	"abort":   libcAbort,
+	"rand":    libcRand,
+	"srand":   libcSrand,
+	"time":    libcTime,
+	"abs":     libcAbs,
+	"strlen":  libcStrlen,
+	"puts":    libcPuts,
+	"putchar": libcPutchar,
+	"printf":  libcPrintf,
+}
+
+func (ip *interpreter) externCall(node ast.Node, fn *checker.Func, args []value) value {
+	name := fn.ExternName()
+	if name == "" {
+		name = fn.Name()
+	}
+
+	if ip.comptime {
+		ip.notConstantf(node, "external function '%s' cannot be called at compile time", name)
+	}
+
+	f, ok := libc[name]
+	if !ok {
+		ip.fatalf(node, "external function '%s' is not supported by the interpreter", name)
+	}
+
+	for i, param := range fn.Params() {
+		args[i] = ip.convert(node, args[i], param.Type())
+	}
+
+	result := f(ip, node, args)
+
+	if tyResult := types.AsFunc(fn.Type()).Result(); tyResult.Len() == 1 {
+		return ip.convert(node, result, tyResult.Types()[0])
+	}
+	return unitValue
+}
+
+func libcMalloc(ip *interpreter, node ast.Node, args []value) value {
+	return &cell{}
+}
+
+func libcFree(ip *interpreter, node ast.Node, args []value) value {
+	return unitValue
+}
+
+func libcExit(ip *interpreter, node ast.Node, args []value) value {
+	panic(exitCode(toInt(args[0])))
+}
+
+func libcAbort(ip *interpreter, node ast.Node, args []value) value {
+	ip.fatalf(node, "program aborted")
+	return nil
+}
+
+// The generator of pseudo-random numbers from the C standard.
+func libcRand(ip *interpreter, node ast.Node, args []value) value {
+	ip.seed = ip.seed*1103515245 + 12345
+	return int64(ip.seed / 65536 % 32768)
+}
+
+func libcSrand(ip *interpreter, node ast.Node, args []value) value {
+	ip.seed = uint64(toInt(args[0]))
+	return unitValue
+}
+
+func libcTime(ip *interpreter, node ast.Node, args []value) value {
+	now := time.Now().Unix()
+	if dest, _ := args[0].(*cell); dest != nil {
+		dest.v = now
+	}
+	return now
+}
+
+func libcAbs(ip *interpreter, node ast.Node, args []value) value {
+	if x := toInt(args[0]); x < 0 {
+		return -x
+	}
+	return args[0]
+}
+
+func libcStrlen(ip *interpreter, node ast.Node, args []value) value {
+	return uint64(len(ip.cString(node, args[0])))
+}
+
+func libcPuts(ip *interpreter, node ast.Node, args []value) value {
+	ip.out.WriteString(ip.cString(node, args[0]) + "\n")
+	return int64(0)
+}
+
+func libcPutchar(ip *interpreter, node ast.Node, args []value) value {
+	ip.out.WriteByte(byte(toInt(args[0])))
+	return args[0]
+}
+
+func libcPrintf(ip *interpreter, node ast.Node, args []value) value {
+	s := ip.sprintf(node, ip.cString(node, args[0]), args[1:])
+	ip.out.WriteString(s)
+	return int64(len(s))
+}
+
+func (ip *interpreter) cString(node ast.Node, v value) string {
+	s, ok := v.(string)
+	if !ok {
+		ip.fatalf(node, "expected a C string, got a value of type %T", v)
+	}
+	return s
+}
+
+// Formats the arguments as the 'printf' function does. Length modifiers
+// are ignored, because the values are already of the correct size.
+func (ip *interpreter) sprintf(node ast.Node, format string, args []value) string {
+	buf := strings.Builder{}
+
+	nextArg := func() value {
+		if len(args) == 0 {
+			ip.fatalf(node, "not enough arguments for the format string")
+		}
+		arg := args[0]
+		args = args[1:]
+		return arg
+	}
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			buf.WriteByte(format[i])
+			continue
+		}
+
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("-+ #0123456789.", format[i]) >= 0 {
+			i++
+		}
+		spec := format[start:i]
+		for i < len(format) && strings.IndexByte("hlLqjzt", format[i]) >= 0 {
+			i++
+		}
+		if i >= len(format) {
+			buf.WriteString(format[start:])
+			break
+		}
+
+		switch verb := format[i]; verb {
+		case '%':
+			buf.WriteByte('%')
+
+		case 'd', 'i':
+			buf.WriteString(fmt.Sprintf(spec+"d", toInt(nextArg())))
+
+		case 'u':
+			buf.WriteString(fmt.Sprintf(spec+"d", uint64(toInt(nextArg()))))
+
+		case 'x', 'X', 'o':
+			buf.WriteString(fmt.Sprintf(spec+string(verb), uint64(toInt(nextArg()))))
+
+		case 'c':
+			buf.WriteByte(byte(toInt(nextArg())))
+
+		case 's':
+			buf.WriteString(fmt.Sprintf(spec+"s", ip.cString(node, nextArg())))
+
+		case 'f', 'F', 'e', 'E', 'g', 'G':
+			buf.WriteString(fmt.Sprintf(spec+string(verb), toFloat(nextArg())))
+
+		case 'p':
+			ptr, _ := nextArg().(*cell)
+			buf.WriteString(fmt.Sprintf("0x%x", ip.address(ptr)))
+
+		default:
+			ip.fatalf(node, "unsupported format verb '%%%c'", verb)
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,209 @@
+package interp
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/types"
+)
+
+// The program called 'exit' during the session.
+type ExitError struct {
+	Code int
+}
+
+func (err *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", err.Code)
+}
+
+// Session executes the inputs checked by [checker.Session]. Global
+// variables and the output are kept between the inputs.
+type Session struct {
+	ip       *interpreter
+	m        *checker.Module
+	declared int                      // Number of the module definitions already handled.
+	imported map[*checker.Module]bool // Imported modules with initialized globals.
+}
+
+func NewSession(m *checker.Module, stdout io.Writer) *Session {
+	return &Session{
+		ip:       newInterpreter(m, stdout),
+		m:        m,
+		imported: map[*checker.Module]bool{},
+	}
+}
+
+// Initializes the global variables declared since the previous call.
+func (s *Session) Declare() (err error) {
+	defer s.recover(&err)
+
+	s.ip.merge(s.m)
+
+	for _, module := range s.ip.modules {
+		if module != s.m && !s.imported[module] {
+			s.imported[module] = true
+			s.ip.initGlobals(module)
+		}
+	}
+
+	i := 0
+	for def := s.m.Defs.Front(); def != nil; def = def.Next() {
+		if i++; i <= s.declared {
+			continue
+		}
+
+		// The variable is not initialized again even if its
+		// initialization fails.
+		s.declared = i
+
+		if v, _ := def.Value.(*checker.Var); v != nil && v.IsGlobal() {
+			s.ip.initGlobal(v)
+		}
+	}
+
+	return nil
+}
+
+// Evaluates the expression and returns its value formatted as the Jet
+// expression. Values of the unit type are formatted as an empty string.
+func (s *Session) Eval(expr ast.Node) (result string, err error) {
+	defer s.recover(&err)
+
+	s.ip.merge(s.m)
+	s.ip.flow, s.ip.label, s.ip.result = flowNext, "", nil
+
+	v := s.ip.eval(expr)
+	if v == nil {
+		// Control flow statements are not allowed outside of the
+		// functions, so they are not expected here.
+		return "", nil
+	}
+
+	t := s.m.TypeOf(expr)
+	if t == nil || t.Equals(types.Unit) {
+		return "", nil
+	}
+
+	return s.format(v, t), nil
+}
+
+func (s *Session) recover(err *error) {
+	switch r := recover().(type) {
+	case nil:
+
+	case *RuntimeError:
+		*err = r
+
+	case exitCode:
+		*err = &ExitError{int(r)}
+
+	default:
+		panic(r)
+	}
+
+	if flushErr := s.ip.out.Flush(); *err == nil {
+		*err = flushErr
+	}
+}
+
+func (s *Session) format(v value, t types.Type) string {
+	switch v := v.(type) {
+	case int64:
+		if kind, _ := primitiveKind(t); kind == types.KindChar {
+			return strconv.QuoteRune(rune(v))
+		}
+		return strconv.FormatInt(v, 10)
+
+	case uint64:
+		return strconv.FormatUint(v, 10)
+
+	case float64:
+		str := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(str, ".eInN") {
+			str += ".0"
+		}
+		return str
+
+	case bool:
+		return strconv.FormatBool(v)
+
+	case string:
+		return strconv.Quote(v)
+
+	case *cell:
+		return fmt.Sprintf("0x%x", s.ip.address(v))
+
+	case *structValue:
+		ty := types.AsStruct(t)
+		return s.m.TypeName(t) + s.formatFields(v, ty)
+
+	case *enumValue:
+		ty := types.AsEnum(t)
+		variant := ty.Fields()[v.tag]
+		result := s.m.TypeName(t) + "." + variant
+		if v.payload != nil && len(v.payload.fields) > 0 {
+			result += s.formatFields(v.payload, ty.Payload(variant))
+		}
+		return result
+
+	case *arrayValue:
+		return s.formatElems(v.elems, types.AsArray(t).ElemType())
+
+	case *sliceValue:
+		return s.formatElems(v.elems, types.AsSlice(t).ElemType())
+
+	case *tupleValue:
+		buf := strings.Builder{}
+		buf.WriteByte('(')
+		for i, elem := range v.elems {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(s.format(elem.v, types.AsTuple(t).Types()[i]))
+		}
+		buf.WriteByte(')')
+		return buf.String()
+
+	case *funcValue:
+		switch {
+		case v == nil:
+			return "null"
+
+		case v.fn != nil:
+			return v.fn.Name()
+		}
+		return "<lambda>"
+	}
+
+	return fmt.Sprint(v)
+}
+
+func (s *Session) formatFields(v *structValue, ty *types.Struct) string {
+	buf := strings.Builder{}
+	buf.WriteByte('(')
+	for i, field := range ty.Fields() {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(field.Name + " = " + s.format(v.fields[i].v, field.Type))
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+func (s *Session) formatElems(elems []*cell, ty types.Type) string {
+	buf := strings.Builder{}
+	buf.WriteByte('[')
+	for i, elem := range elems {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(s.format(elem.v, ty))
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
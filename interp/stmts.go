@@ -0,0 +1,302 @@
+package interp
+
+import (
+	"slices"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/types"
+)
+
+// Executes the statements of the block, the value of the block is the
+// value of the last statement. Deferred statements are executed in the
+// reverse order when the block exits, even if it is exited by the
+// control flow statement.
+func (ip *interpreter) block(list *ast.StmtList) value {
+	var (
+		result = unitValue
+		defers []*ast.Defer
+	)
+
+	for _, stmt := range list.Nodes {
+		ip.step(stmt)
+
+		switch stmt := stmt.(type) {
+		case *ast.Defer:
+			defers = append(defers, stmt)
+			result = unitValue
+
+		case *ast.Decl:
+			result = ip.decl(stmt)
+
+		case *ast.TupleDecl:
+			result = ip.tupleDecl(stmt)
+
+		default:
+			result = ip.eval(stmt)
+		}
+
+		if result == nil {
+			break
+		}
+	}
+
+	ip.runDefers(defers)
+	return result
+}
+
+func (ip *interpreter) runDefers(defers []*ast.Defer) {
+	if len(defers) == 0 {
+		return
+	}
+
+	// The control flow statement that exits the block is resumed after
+	// the deferred statements.
+	flow, label, result := ip.flow, ip.label, ip.result
+	ip.flow, ip.label, ip.result = flowNext, "", nil
+
+	for i := len(defers) - 1; i >= 0; i-- {
+		ip.eval(defers[i].X)
+		ip.flow, ip.label, ip.result = flowNext, "", nil
+	}
+
+	ip.flow, ip.label, ip.result = flow, label, result
+}
+
+func (ip *interpreter) decl(decl *ast.Decl) value {
+	v, _ := ip.symbolOf(decl.Ident).(*checker.Var)
+	if v == nil {
+		// Constants are inlined, types and functions are declared
+		// by the checker.
+		return unitValue
+	}
+
+	var value value
+
+	if decl.Value != nil {
+		if value = ip.eval(decl.Value); value == nil {
+			return nil
+		}
+	} else {
+		value = zeroValue(v.Type())
+	}
+
+	ip.vars[v] = &cell{copyValue(ip.convert(decl, value, v.Type()))}
+	return unitValue
+}
+
+func (ip *interpreter) tupleDecl(decl *ast.TupleDecl) value {
+	value := ip.eval(decl.Value)
+	if value == nil {
+		return nil
+	}
+
+	tuple := value.(*tupleValue)
+
+	for i, name := range decl.Names.Nodes {
+		v, _ := ip.symbolOf(name.(*ast.Ident)).(*checker.Var)
+		if v == nil {
+			// Skipped with '_'.
+			continue
+		}
+		ip.vars[v] = &cell{copyValue(ip.convert(name, tuple.elems[i].v, v.Type()))}
+	}
+
+	return unitValue
+}
+
+func (ip *interpreter) while(node *ast.While) value {
+	for {
+		ip.step(node)
+
+		cond := ip.eval(node.Cond)
+		if cond == nil {
+			return nil
+		}
+		if !cond.(bool) {
+			break
+		}
+
+		ip.eval(node.Body)
+
+		if !ip.loopNext(node.Label) {
+			break
+		}
+	}
+
+	if ip.stopped() {
+		return nil
+	}
+	return unitValue
+}
+
+func (ip *interpreter) forLoop(node *ast.For) value {
+	loopVars := make([]*checker.Var, len(node.DeclList.Nodes))
+	for i, decl := range node.DeclList.Nodes {
+		loopVars[i] = ip.symbolOf(decl.(*ast.Decl).Ident).(*checker.Var)
+	}
+
+	// Runs the body with the loop variables set to the values. Reports
+	// whether the loop must go on.
+	iteration := func(values ...value) bool {
+		ip.step(node)
+
+		for i, v := range loopVars {
+			ip.vars[v] = &cell{copyValue(ip.convert(node, values[i], v.Type()))}
+		}
+		ip.eval(node.Body)
+		return ip.loopNext(node.Label)
+	}
+
+	if rng, _ := node.IterExpr.(*ast.Op); rng != nil &&
+		(rng.Kind == ast.OperatorRangeExclusive || rng.Kind == ast.OperatorRangeInclusive) {
+		start, end := ip.eval(rng.X), ip.eval(rng.Y)
+		if start == nil || end == nil {
+			return nil
+		}
+
+		ty := loopVars[0].Type()
+		cmp := ast.OperatorLt
+		if rng.Kind == ast.OperatorRangeInclusive {
+			cmp = ast.OperatorLe
+		}
+
+		i, n := ip.convert(rng, start, ty), ip.convert(rng, end, ty)
+		for ip.binaryOp(rng, i, n, cmp).(bool) {
+			if !iteration(i) {
+				break
+			}
+			i = ip.convert(rng, ip.binaryOp(rng, i, ip.convert(rng, int64(1), ty), ast.OperatorAdd), ty)
+		}
+	} else {
+		iterable := ip.eval(node.IterExpr)
+		if iterable == nil {
+			return nil
+		}
+
+		// Runs the iteration for the element with the index.
+		element := func(i int, elem value) bool {
+			if len(loopVars) == 2 {
+				return iteration(int64(i), elem)
+			}
+			return iteration(elem)
+		}
+
+		switch iterable := iterable.(type) {
+		case *arrayValue:
+			for i, elem := range iterable.elems {
+				if !element(i, elem.v) {
+					break
+				}
+			}
+
+		case *sliceValue:
+			for i, elem := range iterable.elems {
+				if !element(i, elem.v) {
+					break
+				}
+			}
+
+		case string:
+			for i, b := range []byte(iterable) {
+				if !element(i, uint64(b)) {
+					break
+				}
+			}
+
+		default:
+			ip.iterate(node, iterable, element)
+		}
+	}
+
+	if ip.stopped() {
+		return nil
+	}
+	return unitValue
+}
+
+// Runs the loop over the iterator, which is a value of the type with
+// the 'next' and 'value' methods.
+func (ip *interpreter) iterate(node *ast.For, iterable value, element func(int, value) bool) {
+	ty := ip.typeOf(node.IterExpr)
+	next, valueFn := ip.methodOf(ty, "next"), ip.methodOf(ty, "value")
+
+	// The iterator is copied, because 'next' changes it.
+	iter := &cell{copyValue(iterable)}
+
+	for i := 0; ; i++ {
+		if !ip.call(node, &funcValue{fn: next}, []value{iter}).(bool) {
+			break
+		}
+
+		recv := value(iter)
+		if !types.IsRef(valueFn.Params()[0].Type()) {
+			recv = iter.v
+		}
+
+		if !element(i, ip.call(node, &funcValue{fn: valueFn}, []value{recv})) {
+			break
+		}
+	}
+}
+
+// Handles the control flow statement executed by the body of the loop.
+// Reports whether the loop must go on.
+func (ip *interpreter) loopNext(label *ast.Ident) bool {
+	switch ip.flow {
+	case flowBreak, flowContinue:
+		if ip.label != "" && ip.label != labelName(label) {
+			// Exits the enclosing loop.
+			return false
+		}
+		next := ip.flow == flowContinue
+		ip.flow, ip.label = flowNext, ""
+		return next
+
+	case flowReturn:
+		return false
+	}
+
+	return true
+}
+
+func (ip *interpreter) match(node *ast.Match) value {
+	operand := ip.eval(node.X)
+	if operand == nil {
+		return nil
+	}
+
+	x := operand.(*enumValue)
+	ty := types.AsEnum(ip.typeOf(node.X))
+	variant := ty.Fields()[x.tag]
+
+	var matched *ast.MatchArm
+
+	for _, arm := range node.Arms {
+		if arm.Variant.Name == variant {
+			matched = arm
+			break
+		}
+		if arm.Variant.Name == "_" && matched == nil {
+			matched = arm
+		}
+	}
+
+	if matched == nil {
+		return unitValue
+	}
+
+	if matched.Names != nil {
+		fields := ty.Payload(variant).Fields()
+
+		for _, name := range matched.Names.Nodes {
+			v := ip.symbolOf(name.(*ast.Ident)).(*checker.Var)
+			index := slices.IndexFunc(fields, func(field types.StructField) bool {
+				return field.Name == v.Name()
+			})
+			ip.vars[v] = &cell{copyValue(x.payload.fields[index].v)}
+		}
+	}
+
+	return ip.eval(matched.Body)
+}
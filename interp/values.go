@@ -0,0 +1,394 @@
+package interp
+
+import (
+	"math/big"
+
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/constant"
+	"github.com/saffage/jet/types"
+)
+
+// Values of the interpreted program are represented by Go values:
+//
+//   - signed integers and characters are int64, unsigned integers are uint64;
+//   - floats are float64, booleans are bool;
+//   - strings and pointers to C strings are string;
+//   - pointers are *cell, the null pointer is a nil *cell;
+//   - other values are pointers to the types declared below.
+//
+// Structs, arrays and tuples consist of cells, so pointers can refer to
+// their fields and elements. They are copied on assignment, as they are
+// in the generated C code.
+type value any
+
+// Location in the memory, pointers refer to cells. The value of a cell
+// allocated by 'malloc' is nil until it is accessed.
+type cell struct{ v value }
+
+type (
+	structValue struct{ fields []*cell } // Fields are in the order of declaration.
+	arrayValue  struct{ elems []*cell }
+	tupleValue  struct{ elems []*cell }
+	sliceValue  struct{ elems []*cell } // Cells are shared with the array.
+
+	enumValue struct {
+		tag     int
+		payload *structValue // Nil if the variant has no payload.
+	}
+
+	// Declared function or lambda with its environment.
+	funcValue struct {
+		fn     *checker.Func
+		lambda *checker.Lambda
+		env    map[*checker.Var]*cell
+	}
+
+	unit struct{}
+)
+
+// Value of the expressions of the unit type.
+var unitValue value = unit{}
+
+func newCells(values []value) []*cell {
+	cells := make([]*cell, len(values))
+	for i, v := range values {
+		cells[i] = &cell{v}
+	}
+	return cells
+}
+
+func copyCells(cells []*cell) []*cell {
+	copied := make([]*cell, len(cells))
+	for i, c := range cells {
+		copied[i] = &cell{copyValue(c.v)}
+	}
+	return copied
+}
+
+// Returns a deep copy of structs, arrays, tuples and enums. Other values
+// are returned as is.
+func copyValue(v value) value {
+	switch v := v.(type) {
+	case *structValue:
+		return &structValue{copyCells(v.fields)}
+
+	case *arrayValue:
+		return &arrayValue{copyCells(v.elems)}
+
+	case *tupleValue:
+		return &tupleValue{copyCells(v.elems)}
+
+	case *enumValue:
+		if v.payload != nil {
+			return &enumValue{v.tag, &structValue{copyCells(v.payload.fields)}}
+		}
+	}
+
+	return v
+}
+
+// Returns the value of the variable of the type that is not initialized
+// explicitly.
+func zeroValue(t types.Type) value {
+	if t == types.String {
+		return ""
+	}
+
+	switch t := t.Underlying().(type) {
+	case *types.Primitive:
+		switch {
+		case t.Kind() == types.KindBool || t.Kind() == types.KindUntypedBool:
+			return false
+
+		case t.Kind() == types.KindUntypedString:
+			return ""
+
+		case t.Kind() == types.KindPointer:
+			return (*cell)(nil)
+
+		case isUnsigned(t.Kind()):
+			return uint64(0)
+
+		case isFloat(t.Kind()):
+			return float64(0)
+		}
+
+		return int64(0)
+
+	case *types.Struct:
+		return zeroStruct(t)
+
+	case *types.Array:
+		elems := make([]value, t.Size())
+		for i := range elems {
+			elems[i] = zeroValue(t.ElemType())
+		}
+		return &arrayValue{newCells(elems)}
+
+	case *types.Tuple:
+		if t.Equals(types.Unit) {
+			return unitValue
+		}
+		elems := make([]value, t.Len())
+		for i, elem := range t.Types() {
+			elems[i] = zeroValue(elem)
+		}
+		return &tupleValue{newCells(elems)}
+
+	case *types.Enum:
+		if payload := t.Payload(t.Fields()[0]); payload != nil {
+			return &enumValue{0, zeroStruct(payload)}
+		}
+		return &enumValue{0, nil}
+
+	case *types.Slice:
+		return &sliceValue{}
+
+	case *types.Ref:
+		return (*cell)(nil)
+
+	case *types.Func:
+		return (*funcValue)(nil)
+	}
+
+	return nil
+}
+
+func zeroStruct(t *types.Struct) *structValue {
+	fields := make([]value, len(t.Fields()))
+	for i, field := range t.Fields() {
+		fields[i] = zeroValue(field.Type)
+	}
+	return &structValue{newCells(fields)}
+}
+
+// Converts the compile-time value to the value of the type.
+func fromConstant(c constant.Value, t types.Type) value {
+	switch c.Kind() {
+	case constant.Int:
+		x := constant.AsInt(c)
+
+		if kind, ok := primitiveKind(t); ok {
+			switch {
+			case isFloat(kind):
+				f, _ := new(big.Float).SetInt(x).Float64()
+				return convertFloat(f, kind)
+
+			case isUnsigned(kind):
+				return convertUint(truncate(x).Uint64(), kind)
+			}
+			return convertInt(int64(truncate(x).Uint64()), kind)
+		}
+
+		return int64(truncate(x).Uint64())
+
+	case constant.Float:
+		f, _ := constant.AsFloat(c).Float64()
+
+		if kind, ok := primitiveKind(t); ok {
+			switch {
+			case isUnsigned(kind):
+				return convertUint(uint64(f), kind)
+
+			case !isFloat(kind):
+				return convertInt(int64(f), kind)
+			}
+			return convertFloat(f, kind)
+		}
+
+		return f
+
+	case constant.String:
+		return *constant.AsString(c)
+
+	case constant.Bool:
+		return *constant.AsBool(c)
+
+	case constant.Array:
+		tyElem := types.Type(nil)
+		if array := types.AsArray(t); array != nil {
+			tyElem = array.ElemType()
+		}
+
+		elems := []value{}
+		for _, elem := range *constant.AsArray(c) {
+			elems = append(elems, fromConstant(elem, tyElem))
+		}
+		return &arrayValue{newCells(elems)}
+
+	case constant.Struct:
+		fields := constant.AsStruct(c)
+		values := []value{}
+
+		for _, field := range types.AsStruct(t).Fields() {
+			values = append(values, fromConstant(fields[field.Name], field.Type))
+		}
+		return &structValue{newCells(values)}
+	}
+
+	panic("unreachable")
+}
+
+// Returns the lower 64 bits of the integer in the two's complement form.
+func truncate(x *big.Int) *big.Int {
+	mask := new(big.Int).SetUint64(^uint64(0))
+	return new(big.Int).And(x, mask)
+}
+
+func primitiveKind(t types.Type) (types.PrimitiveKind, bool) {
+	if t != nil {
+		if primitive := types.AsPrimitive(t); primitive != nil {
+			return primitive.Kind(), true
+		}
+	}
+	return 0, false
+}
+
+func isUnsigned(kind types.PrimitiveKind) bool {
+	switch kind {
+	case types.KindU8, types.KindU16, types.KindU32, types.KindU64:
+		return true
+	}
+	return false
+}
+
+func isFloat(kind types.PrimitiveKind) bool {
+	switch kind {
+	case types.KindF32, types.KindF64, types.KindUntypedFloat:
+		return true
+	}
+	return false
+}
+
+// Wraps the integer around to the size of the type.
+func convertInt(x int64, kind types.PrimitiveKind) value {
+	switch kind {
+	case types.KindI8, types.KindChar:
+		return int64(int8(x))
+
+	case types.KindI16:
+		return int64(int16(x))
+
+	case types.KindI32:
+		return int64(int32(x))
+
+	case types.KindBool, types.KindUntypedBool:
+		return x != 0
+	}
+
+	if isUnsigned(kind) {
+		return convertUint(uint64(x), kind)
+	}
+
+	if isFloat(kind) {
+		return convertFloat(float64(x), kind)
+	}
+
+	return x
+}
+
+// Wraps the integer around to the size of the type.
+func convertUint(x uint64, kind types.PrimitiveKind) value {
+	switch kind {
+	case types.KindU8:
+		return uint64(uint8(x))
+
+	case types.KindU16:
+		return uint64(uint16(x))
+
+	case types.KindU32:
+		return uint64(uint32(x))
+
+	case types.KindU64:
+		return x
+	}
+
+	if isFloat(kind) {
+		return convertFloat(float64(x), kind)
+	}
+
+	return convertInt(int64(x), kind)
+}
+
+func convertFloat(x float64, kind types.PrimitiveKind) value {
+	switch {
+	case kind == types.KindF32:
+		return float64(float32(x))
+
+	case isFloat(kind):
+		return x
+
+	case isUnsigned(kind):
+		return convertUint(uint64(x), kind)
+	}
+
+	return convertInt(int64(x), kind)
+}
+
+// Returns the size and the alignment of the type in the generated C code.
+func layout(t types.Type) (size, align int) {
+	if t == types.String {
+		return 8, 8
+	}
+
+	switch t := t.Underlying().(type) {
+	case *types.Primitive:
+		switch t.Kind() {
+		case types.KindBool, types.KindI8, types.KindU8, types.KindChar:
+			return 1, 1
+
+		case types.KindI16, types.KindU16:
+			return 2, 2
+
+		case types.KindI32, types.KindU32, types.KindF32, types.KindUntypedInt:
+			return 4, 4
+		}
+		return 8, 8
+
+	case *types.Array:
+		size, align := layout(t.ElemType())
+		return size * t.Size(), align
+
+	case *types.Struct:
+		fields := make([]types.Type, len(t.Fields()))
+		for i, field := range t.Fields() {
+			fields[i] = field.Type
+		}
+		return structLayout(fields)
+
+	case *types.Tuple:
+		return structLayout(t.Types())
+
+	case *types.Enum:
+		if !t.IsTagged() {
+			return 4, 4
+		}
+
+		// The tag and the union of payloads.
+		size, align := 0, 4
+		for _, field := range t.Fields() {
+			if payload := t.Payload(field); payload != nil {
+				payloadSize, payloadAlign := layout(payload)
+				size, align = max(size, payloadSize), max(align, payloadAlign)
+			}
+		}
+		return structLayout([]types.Type{types.I32, types.NewArray(size, types.U8)})
+
+	case *types.Slice, *types.Func:
+		return 16, 8
+	}
+
+	return 8, 8
+}
+
+func structLayout(fields []types.Type) (size, align int) {
+	align = 1
+	for _, field := range fields {
+		fieldSize, fieldAlign := layout(field)
+		size = (size + fieldAlign - 1) / fieldAlign * fieldAlign
+		size += fieldSize
+		align = max(align, fieldAlign)
+	}
+	size = (size + align - 1) / align * align
+	return size, align
+}
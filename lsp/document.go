@@ -0,0 +1,404 @@
+package lsp
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/saffage/jet/ast"
+	"github.com/saffage/jet/checker"
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/interp"
+	"github.com/saffage/jet/parser"
+	"github.com/saffage/jet/report"
+	"github.com/saffage/jet/scanner"
+	"github.com/saffage/jet/token"
+	"github.com/saffage/jet/types"
+)
+
+type document struct {
+	cfg         *config.Config
+	uri         string
+	fileID      config.FileID
+	stmts       *ast.StmtList   // AST of the last parsed content.
+	module      *checker.Module // Module of the last parsed content.
+	diagnostics []Diagnostic
+}
+
+func newDocument(cfg *config.Config, uri string) *document {
+	path := uri
+
+	if u, err := url.Parse(uri); err == nil && u.Scheme == "file" {
+		path = filepath.FromSlash(u.Path)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	fileID := config.NextFileID()
+	cfg.Files[fileID] = config.FileInfo{Name: name, Path: path}
+
+	return &document{cfg: cfg, uri: uri, fileID: fileID}
+}
+
+// Checks the new content of the document and collects diagnostics.
+// If the content cannot be scanned or nothing can be parsed, the previous
+// AST and module are kept.
+func (doc *document) update(text []byte) {
+	finfo := doc.cfg.Files[doc.fileID]
+	finfo.Buf = bytes.NewBuffer(text)
+	doc.cfg.Files[doc.fileID] = finfo
+	doc.diagnostics = []Diagnostic{}
+
+	handler := report.Handler
+	defer func() { report.Handler = handler }()
+	report.Handler = doc.collect
+
+	defer func() {
+		if err := recover(); err != nil {
+			report.TaggedErrorf("internal", "%s", err)
+		}
+	}()
+
+	if err := checker.CheckBuiltInPkgs(doc.cfg); err != nil {
+		report.Errors(err)
+		return
+	}
+
+	tokens, err := scanner.Scan(text, doc.fileID, scanner.SkipWhitespace)
+	if err != nil {
+		report.Errors(err)
+		return
+	}
+
+	// The parser replaces the invalid code with [ast.BadNode], so the
+	// rest of the document is checked even if there are syntax errors.
+	stmts, err := parser.Parse(tokens, parser.DefaultFlags)
+	if err != nil {
+		report.Errors(err)
+	}
+
+	if stmts == nil {
+		if err != nil {
+			return
+		}
+		stmts = &ast.StmtList{}
+	}
+
+	doc.stmts = stmts
+	doc.module, err = checker.Check(doc.cfg, doc.fileID, stmts, checker.Options{Evaluator: interp.Comptime})
+	report.Errors(err)
+}
+
+// Converts the reported message to the diagnostic. Messages related to
+// other files are ignored, messages without a location are placed at
+// the beginning of the document. Hints and notes are ignored too.
+func (doc *document) collect(d report.Diagnostic) {
+	if d.Kind < report.KindWarning {
+		return
+	}
+
+	if d.Start.FileID != doc.fileID && d.Start.IsValid() {
+		return
+	}
+
+	severity := SeverityError
+	if d.Kind == report.KindWarning {
+		severity = SeverityWarning
+	}
+
+	source := "jet"
+	if d.Tag != "" {
+		source += " " + d.Tag
+	}
+
+	diagnostic := Diagnostic{
+		Range:    toRange(d.Start, d.End),
+		Severity: severity,
+		Code:     d.Code,
+		Source:   source,
+		Message:  d.Message,
+	}
+
+	// The checker can report the same error more than once.
+	if !slices.Contains(doc.diagnostics, diagnostic) {
+		doc.diagnostics = append(doc.diagnostics, diagnostic)
+	}
+}
+
+func (doc *document) hover(pos Position) *Hover {
+	ident := doc.identAt(pos)
+	if ident == nil || doc.module == nil {
+		return nil
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString("```jet\n")
+
+	if sym := doc.module.SymbolOf(ident); sym != nil {
+		buf.WriteString(symbolSignature(sym))
+	} else if t := doc.module.TypeOf(ident); t != nil {
+		buf.WriteString(fmt.Sprintf("%s: %s", ident.Name, t))
+	} else {
+		return nil
+	}
+
+	buf.WriteString("\n```")
+
+	if sym := doc.module.SymbolOf(ident); sym != nil {
+		if decl, _ := sym.Node().(*ast.Decl); decl != nil && decl.Docs != nil {
+			buf.WriteString("\n\n")
+			buf.WriteString(docsText(decl.Docs))
+		}
+	}
+
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: buf.String()},
+		Range:    toRange(ident.Pos(), ident.PosEnd()),
+	}
+}
+
+func (doc *document) definition(pos Position) *Location {
+	ident := doc.identAt(pos)
+	if ident == nil || doc.module == nil {
+		return nil
+	}
+
+	sym := doc.module.SymbolOf(ident)
+	if sym == nil || sym.Ident() == nil {
+		return nil
+	}
+
+	def := sym.Ident()
+	finfo, ok := doc.cfg.Files[def.Start.FileID]
+	if !ok {
+		return nil
+	}
+
+	uri := doc.uri
+	if def.Start.FileID != doc.fileID {
+		path, err := filepath.Abs(finfo.Path)
+		if err != nil {
+			return nil
+		}
+		uri = (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	}
+
+	return &Location{URI: uri, Range: toRange(def.Pos(), def.PosEnd())}
+}
+
+func (doc *document) symbols() []DocumentSymbol {
+	if doc.module == nil {
+		return []DocumentSymbol{}
+	}
+
+	symbols := []DocumentSymbol{}
+
+	for _, sym := range doc.module.Scope.Symbols() {
+		ident := sym.Ident()
+		if ident == nil || ident.Start.FileID != doc.fileID {
+			continue
+		}
+
+		docSym := DocumentSymbol{
+			Name:           sym.Name(),
+			Kind:           symbolKind(sym),
+			Range:          toRange(ident.Pos(), ident.PosEnd()),
+			SelectionRange: toRange(ident.Pos(), ident.PosEnd()),
+		}
+
+		if sym.Type() != nil {
+			docSym.Detail = sym.Type().String()
+		}
+
+		if decl, _ := sym.Node().(*ast.Decl); decl != nil {
+			docSym.Range = toRange(decl.Pos(), decl.PosEnd())
+
+			switch value := decl.Value.(type) {
+			case *ast.StructType:
+				for _, field := range value.Fields {
+					fieldSym := DocumentSymbol{
+						Name:           field.Ident.Name,
+						Kind:           SymbolKindField,
+						Range:          toRange(field.Pos(), field.PosEnd()),
+						SelectionRange: toRange(field.Ident.Pos(), field.Ident.PosEnd()),
+					}
+					if field.Type != nil {
+						fieldSym.Detail = field.Type.Repr()
+					}
+					docSym.Children = append(docSym.Children, fieldSym)
+				}
+
+			case *ast.EnumType:
+				for _, variant := range value.Variants {
+					variantSym := DocumentSymbol{
+						Name:           variant.Ident.Name,
+						Kind:           SymbolKindEnumMember,
+						Range:          toRange(variant.Pos(), variant.PosEnd()),
+						SelectionRange: toRange(variant.Ident.Pos(), variant.Ident.PosEnd()),
+					}
+					if variant.Fields != nil {
+						variantSym.Detail = variant.Fields.Repr()
+					}
+					docSym.Children = append(docSym.Children, variantSym)
+				}
+			}
+
+			for _, method := range methodsOf(decl.Value) {
+				methodSym := DocumentSymbol{
+					Name:           method.Ident.Name,
+					Kind:           SymbolKindMethod,
+					Range:          toRange(method.Pos(), method.PosEnd()),
+					SelectionRange: toRange(method.Ident.Pos(), method.Ident.PosEnd()),
+				}
+				if fn, _ := method.Value.(*ast.Function); fn != nil {
+					methodSym.Detail = fn.Signature.Repr()
+				}
+				docSym.Children = append(docSym.Children, methodSym)
+			}
+		}
+
+		symbols = append(symbols, docSym)
+	}
+
+	slices.SortFunc(symbols, func(a, b DocumentSymbol) int {
+		return comparePositions(a.Range.Start, b.Range.Start)
+	})
+
+	return symbols
+}
+
+// Returns the innermost identifier at the specified position.
+func (doc *document) identAt(pos Position) *ast.Ident {
+	if doc.stmts == nil {
+		return nil
+	}
+
+	var found *ast.Ident
+	var visit ast.Visitor
+
+	visit = func(node ast.Node) ast.Visitor {
+		if node == nil || !contains(node, pos) {
+			return nil
+		}
+
+		if ident, _ := node.(*ast.Ident); ident != nil {
+			found = ident
+		}
+
+		return visit
+	}
+
+	for _, node := range doc.stmts.Nodes {
+		if contains(node, pos) {
+			visit.WalkTopDown(node)
+		}
+	}
+
+	return found
+}
+
+func contains(node ast.Node, pos Position) bool {
+	start, end := node.Pos(), node.PosEnd()
+
+	if !start.IsValid() || !end.IsValid() {
+		// Lists without nodes don't have a location.
+		return true
+	}
+
+	rng := toRange(start, end)
+	return comparePositions(rng.Start, pos) <= 0 && comparePositions(pos, rng.End) < 0
+}
+
+func comparePositions(a, b Position) int {
+	if a.Line != b.Line {
+		return int(a.Line) - int(b.Line)
+	}
+	return int(a.Character) - int(b.Character)
+}
+
+// Converts the inclusive range of 1-based positions to the LSP range.
+func toRange(start, end token.Pos) Range {
+	rng := Range{Start: toPosition(start), End: toPosition(end)}
+	if end.IsValid() {
+		rng.End.Character++
+	} else {
+		rng.End = rng.Start
+	}
+	return rng
+}
+
+func toPosition(pos token.Pos) Position {
+	if pos.Line == 0 {
+		return Position{}
+	}
+	return Position{Line: pos.Line - 1, Character: max(pos.Char, 1) - 1}
+}
+
+func symbolSignature(sym checker.Symbol) string {
+	switch sym := sym.(type) {
+	case *checker.Struct, *checker.Enum, *checker.TypeAlias:
+		return fmt.Sprintf("%s := %s", sym.Name(), types.SkipTypeDesc(sym.Type()))
+
+	case *checker.Module:
+		return fmt.Sprintf("module %s", sym.Name())
+
+	default:
+		if sym.Type() == nil {
+			return sym.Name()
+		}
+		return fmt.Sprintf("%s: %s", sym.Name(), sym.Type())
+	}
+}
+
+func symbolKind(sym checker.Symbol) SymbolKind {
+	switch sym := sym.(type) {
+	case *checker.Func:
+		if sym.MethodOf() != nil {
+			return SymbolKindMethod
+		}
+		return SymbolKindFunction
+
+	case *checker.Const:
+		return SymbolKindConstant
+
+	case *checker.Struct:
+		return SymbolKindStruct
+
+	case *checker.Enum:
+		return SymbolKindEnum
+
+	case *checker.TypeAlias:
+		return SymbolKindTypeParameter
+
+	case *checker.Module:
+		return SymbolKindModule
+
+	default:
+		return SymbolKindVariable
+	}
+}
+
+// Returns methods declared in the struct or enum body.
+func methodsOf(value ast.Node) []*ast.Decl {
+	switch value := value.(type) {
+	case *ast.StructType:
+		return value.Methods
+
+	case *ast.EnumType:
+		return value.Methods
+	}
+
+	return nil
+}
+
+func docsText(docs *ast.CommentGroup) string {
+	lines := make([]string, 0, len(docs.Comments))
+
+	for _, comment := range docs.Comments {
+		lines = append(lines, strings.TrimPrefix(comment.Value, " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
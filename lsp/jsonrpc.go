@@ -0,0 +1,59 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+var ErrorMissingContentLength = errors.New("missing 'Content-Length' header")
+
+// Reads a message content in the base protocol format:
+//
+//	Content-Length: <length>\r\n
+//	\r\n
+//	<content>
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	lengthStr := header.Get("Content-Length")
+	if lengthStr == "" {
+		return nil, ErrorMissingContentLength
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(lengthStr))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid 'Content-Length' header: '%s'", lengthStr)
+	}
+
+	content := make([]byte, length)
+
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// Writes a message in the base protocol format.
+func writeMessage(w io.Writer, msg any) error {
+	content, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+
+	_, err = w.Write(content)
+	return err
+}
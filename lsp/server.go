@@ -0,0 +1,241 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/report"
+)
+
+// Server implements the Language Server Protocol for the Jet language.
+// Every opened document is checked as a separate module.
+type Server struct {
+	cfg         *config.Config
+	out         io.Writer
+	docs        map[string]*document // Indexed by the document URI.
+	initialized bool
+	shutdown    bool
+}
+
+func NewServer(cfg *config.Config) *Server {
+	return &Server{
+		cfg:  cfg,
+		docs: map[string]*document{},
+	}
+}
+
+// Reads requests from `r` and writes responses to `w` until the
+// 'exit' notification is received or the input is closed.
+//
+// While serving, all reported messages are captured by the server, so
+// nothing except the responses is written to the standard output.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	handler := report.Handler
+	defer func() { report.Handler = handler }()
+	report.Handler = func(report.Diagnostic) {}
+
+	in := bufio.NewReader(r)
+	s.out = w
+
+	for {
+		content, err := readMessage(in)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		req := request{}
+
+		if err := json.Unmarshal(content, &req); err != nil {
+			if err := s.respondError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("the server was exited without shutdown")
+			}
+			return nil
+		}
+
+		if err := s.handle(&req); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(req *request) error {
+	if !s.initialized && req.Method != "initialize" {
+		if req.ID == nil {
+			// Notifications must be dropped.
+			return nil
+		}
+		return s.respondError(req.ID, codeServerNotInitialized, "the server is not initialized")
+	}
+
+	switch req.Method {
+	case "initialize":
+		params := InitializeParams{}
+		if !s.decodeParams(req, &params) {
+			return nil
+		}
+		s.initialized = true
+		result := InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       textDocumentSyncFull,
+				HoverProvider:          true,
+				DefinitionProvider:     true,
+				DocumentSymbolProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "jet", Version: config.Version},
+		}
+		// Positions are counted in bytes, which is correct for UTF-16
+		// only if the text is ASCII.
+		if slices.Contains(params.Capabilities.General.PositionEncodings, "utf-8") {
+			result.Capabilities.PositionEncoding = "utf-8"
+		}
+		return s.respond(req.ID, result)
+
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil
+
+	case "shutdown":
+		s.shutdown = true
+		return s.respond(req.ID, nil)
+
+	case "textDocument/didOpen":
+		params := DidOpenTextDocumentParams{}
+		if !s.decodeParams(req, &params) {
+			return nil
+		}
+		doc := s.open(params.TextDocument.URI)
+		doc.update([]byte(params.TextDocument.Text))
+		return s.publishDiagnostics(doc)
+
+	case "textDocument/didChange":
+		params := DidChangeTextDocumentParams{}
+		if !s.decodeParams(req, &params) || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		doc := s.open(params.TextDocument.URI)
+		doc.update([]byte(params.ContentChanges[len(params.ContentChanges)-1].Text))
+		return s.publishDiagnostics(doc)
+
+	case "textDocument/didSave":
+		return nil
+
+	case "textDocument/didClose":
+		params := DidCloseTextDocumentParams{}
+		if !s.decodeParams(req, &params) {
+			return nil
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+
+	case "textDocument/hover":
+		params := TextDocumentPositionParams{}
+		if !s.decodeParams(req, &params) {
+			return nil
+		}
+		if doc := s.docs[params.TextDocument.URI]; doc != nil {
+			if hover := doc.hover(params.Position); hover != nil {
+				return s.respond(req.ID, hover)
+			}
+		}
+		return s.respond(req.ID, nil)
+
+	case "textDocument/definition":
+		params := TextDocumentPositionParams{}
+		if !s.decodeParams(req, &params) {
+			return nil
+		}
+		if doc := s.docs[params.TextDocument.URI]; doc != nil {
+			if location := doc.definition(params.Position); location != nil {
+				return s.respond(req.ID, location)
+			}
+		}
+		return s.respond(req.ID, nil)
+
+	case "textDocument/documentSymbol":
+		params := DocumentSymbolParams{}
+		if !s.decodeParams(req, &params) {
+			return nil
+		}
+		symbols := []DocumentSymbol{}
+		if doc := s.docs[params.TextDocument.URI]; doc != nil {
+			symbols = doc.symbols()
+		}
+		return s.respond(req.ID, symbols)
+
+	default:
+		if req.ID == nil {
+			// Unknown notifications are ignored.
+			return nil
+		}
+		return s.respondError(req.ID, codeMethodNotFound, fmt.Sprintf("method '%s' is not supported", req.Method))
+	}
+}
+
+// Returns the opened document or opens a new one.
+func (s *Server) open(uri string) *document {
+	if doc := s.docs[uri]; doc != nil {
+		return doc
+	}
+
+	doc := newDocument(s.cfg, uri)
+	s.docs[uri] = doc
+	return doc
+}
+
+// Decodes request parameters. If parameters are invalid, responds with
+// an error and returns false.
+func (s *Server) decodeParams(req *request, params any) bool {
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		if req.ID != nil {
+			_ = s.respondError(req.ID, codeInvalidParams, err.Error())
+		}
+		return false
+	}
+	return true
+}
+
+func (s *Server) publishDiagnostics(doc *document) error {
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         doc.uri,
+		Diagnostics: doc.diagnostics,
+	})
+}
+
+func (s *Server) respond(id *json.RawMessage, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return s.respondError(id, codeInternalError, err.Error())
+	}
+
+	raw := json.RawMessage(data)
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: id, Result: &raw})
+}
+
+func (s *Server) respondError(id *json.RawMessage, code int, message string) error {
+	return writeMessage(s.out, response{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &responseError{Code: code, Message: message},
+	})
+}
+
+func (s *Server) notify(method string, params any) error {
+	return writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
@@ -0,0 +1,178 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/saffage/jet/config"
+)
+
+func TestServerSession(t *testing.T) {
+	config.Global.Files = map[config.FileID]config.FileInfo{}
+	config.Global.Options.CoreLibPath = "../lib"
+
+	const uri = "file:///tmp/test.jet"
+	const text = `## Adds two numbers.
+add := (a: i32, b: i32) -> i32 { a + b }
+
+main := () {
+	$println(add(1, 2))
+	$println(undefined)
+}
+`
+	in := &bytes.Buffer{}
+	requests := []struct {
+		id     int
+		method string
+		params any
+	}{
+		{1, "initialize", map[string]any{"capabilities": map[string]any{}}},
+		{0, "initialized", map[string]any{}},
+		{0, "textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "jet", "version": 1, "text": text},
+		}},
+		{2, "textDocument/hover", map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 4, "character": 11},
+		}},
+		{3, "textDocument/definition", map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"position":     map[string]any{"line": 4, "character": 11},
+		}},
+		{4, "textDocument/documentSymbol", map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+		}},
+		{5, "shutdown", nil},
+		{0, "exit", nil},
+	}
+
+	for _, req := range requests {
+		msg := map[string]any{"jsonrpc": "2.0", "method": req.method, "params": req.params}
+		if req.id != 0 {
+			msg["id"] = req.id
+		}
+		if err := writeMessage(in, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := &bytes.Buffer{}
+
+	if err := NewServer(config.Global).Serve(in, out); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	responses := map[int]json.RawMessage{}
+	diagnostics := []PublishDiagnosticsParams{}
+	r := bufio.NewReader(out)
+
+	for {
+		content, err := readMessage(r)
+		if err != nil {
+			break
+		}
+
+		msg := struct {
+			ID     *int            `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Result json.RawMessage `json:"result"`
+			Error  *responseError  `json:"error"`
+		}{}
+
+		if err := json.Unmarshal(content, &msg); err != nil {
+			t.Fatal(err)
+		}
+
+		switch {
+		case msg.Error != nil:
+			t.Errorf("unexpected error response: %s", msg.Error.Message)
+
+		case msg.ID != nil:
+			responses[*msg.ID] = msg.Result
+
+		case msg.Method == "textDocument/publishDiagnostics":
+			params := PublishDiagnosticsParams{}
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				t.Fatal(err)
+			}
+			diagnostics = append(diagnostics, params)
+		}
+	}
+
+	if len(diagnostics) != 1 || len(diagnostics[0].Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", diagnostics)
+	}
+
+	if d := diagnostics[0].Diagnostics[0]; d.Range.Start != (Position{Line: 5, Character: 10}) ||
+		d.Severity != SeverityError || d.Code != "E0201" {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+
+	hover := Hover{}
+	if err := json.Unmarshal(responses[2], &hover); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(hover.Contents.Value, "add: ") ||
+		!strings.Contains(hover.Contents.Value, "Adds two numbers.") {
+		t.Errorf("unexpected hover: %q", hover.Contents.Value)
+	}
+
+	location := Location{}
+	if err := json.Unmarshal(responses[3], &location); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedRange := Range{Start: Position{Line: 1, Character: 0}, End: Position{Line: 1, Character: 3}}
+	if location.URI != uri || location.Range != expectedRange {
+		t.Errorf("unexpected definition: %+v", location)
+	}
+
+	symbols := []DocumentSymbol{}
+	if err := json.Unmarshal(responses[4], &symbols); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(symbols) != 2 || symbols[0].Name != "add" || symbols[1].Name != "main" ||
+		symbols[0].Kind != SymbolKindFunction {
+		t.Errorf("unexpected document symbols: %+v", symbols)
+	}
+}
+
+// The document with a syntax error is checked, the invalid code is
+// skipped and the rest of the document is still available.
+func TestPartialTree(t *testing.T) {
+	config.Global.Files = map[config.FileID]config.FileInfo{}
+	config.Global.Options.CoreLibPath = "../lib"
+
+	const text = `add := (a: i32, b: i32) -> i32 { a + b }
+
+broken := () -> i32 { 1 + }
+
+main := () {
+	$println(add(1, 2))
+	$println(undefined)
+}
+`
+	doc := newDocument(config.Global, "file:///tmp/partial.jet")
+	doc.update([]byte(text))
+
+	codes := []string{}
+	for _, d := range doc.diagnostics {
+		codes = append(codes, d.Code)
+	}
+
+	if !slices.Equal(codes, []string{"E0103", "E0201"}) {
+		t.Errorf("unexpected diagnostics: %+v", doc.diagnostics)
+	}
+
+	if hover := doc.hover(Position{Line: 5, Character: 11}); hover == nil ||
+		!strings.Contains(hover.Contents.Value, "add: ") {
+		t.Errorf("unexpected hover: %+v", hover)
+	}
+}
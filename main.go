@@ -15,6 +15,8 @@ func main() {
 	spew.Config.DisableCapacities = true
 	spew.Config.DisablePointerAddresses = true
 
+	defer report.Flush()
+
 	defer func() {
 		if err := recover(); err != nil {
 			report.TaggedErrorf("internal", "%s", err)
@@ -36,7 +36,10 @@ type parser struct {
 	indent int
 
 	// State
-	restoreData []restoreData
+	restoreData  []restoreData
+	commentGroup *ast.CommentGroup
+	delimiters   []token.Kind // Closing brackets of the lists being parsed.
+	badDecl      *ast.Ident   // Name of the last declaration with a syntax error.
 }
 
 func New(tokens []token.Token, flags Flags) *parser {
@@ -48,11 +51,13 @@ func New(tokens []token.Token, flags Flags) *parser {
 		panic("expected EOF token is the end of the stream")
 	}
 
-	return &parser{
+	p := &parser{
 		tokens: tokens,
 		flags:  flags,
 		tok:    tokens[0],
 	}
+	p.skipTokens()
+	return p
 }
 
 func (p *parser) Parse() (*ast.StmtList, error) {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/token"
 )
@@ -27,6 +28,25 @@ var (
 	ErrorExpectedIdentAfterMut  = errors.New("expected identifier after 'mut'")
 )
 
+// Codes of the errors, see [diag.Code].
+var codes = map[error]diag.Code{
+	ErrorInvalidBinaryOperator:  diag.InvalidBinaryOperator,
+	ErrorBracketIsNeverClosed:   diag.UnclosedBracket,
+	ErrorUnterminatedExpr:       diag.UnexpectedToken,
+	ErrorUnexpectedToken:        diag.UnexpectedToken,
+	ErrorExpectedExpr:           diag.ExpectedExpr,
+	ErrorExpectedOperand:        diag.ExpectedExpr,
+	ErrorExpectedBlock:          diag.ExpectedBlock,
+	ErrorExpectedBlockOrIf:      diag.ExpectedBlock,
+	ErrorExpectedType:           diag.ExpectedExpr,
+	ErrorExpectedTypeName:       diag.ExpectedExpr,
+	ErrorExpectedTypeOrValue:    diag.ExpectedExpr,
+	ErrorExpectedDecl:           diag.ExpectedDecl,
+	ErrorExpectedDeclAfterAttrs: diag.ExpectedDecl,
+	ErrorExpectedIdent:          diag.ExpectedIdent,
+	ErrorExpectedIdentAfterMut:  diag.ExpectedIdent,
+}
+
 type Error struct {
 	Start   token.Pos
 	End     token.Pos
@@ -49,6 +69,12 @@ func (e Error) Unwrap() error {
 	return e.err
 }
 
+// Returns the code of the error, or an empty code if the error is
+// internal.
+func (e Error) Code() diag.Code {
+	return codes[e.err]
+}
+
 func (e Error) Report() {
 	err, ok := e.err.(report.Reporter)
 	if ok && err != nil {
@@ -63,11 +89,18 @@ func (e Error) Report() {
 		if e.Message != "" {
 			message += ": " + e.Message
 		}
+		kind := report.KindError
 		if e.isWarn {
-			report.TaggedWarningAt(tag, e.Start, e.End, message)
-		} else {
-			report.TaggedErrorAt(tag, e.Start, e.End, message)
+			kind = report.KindWarning
 		}
+		report.Emit(report.Diagnostic{
+			Kind:    kind,
+			Tag:     tag,
+			Start:   e.Start,
+			End:     e.End,
+			Message: message,
+			Code:    string(e.Code()),
+		})
 	}
 }
 
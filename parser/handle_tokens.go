@@ -3,7 +3,9 @@ package parser
 import (
 	"fmt"
 	"slices"
+	"strings"
 
+	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/token"
 )
 
@@ -22,24 +24,14 @@ func (p *parser) next() {
 	}
 
 	p.tok = p.tokens[p.current]
+	p.skipTokens()
+}
 
-	// if p.commentGroup != nil && p.tok.End.Line > p.commentGroup.LocEnd().Line+1 {
-	// 	p.commentGroup = nil
-	// }
-
+// Skips comments and, depending on flags, whitespaces and illegal
+// tokens. Documentation comments are collected into the comment group.
+func (p *parser) skipTokens() {
 	if p.tok.Kind == token.Comment {
-		// 	if strings.HasPrefix(p.tok.Data, "##") {
-		// 		if p.commentGroup == nil {
-		// 			p.commentGroup = &ast.CommentGroup{}
-		// 		}
-
-		// 		p.commentGroup.Comments = append(p.commentGroup.Comments, &ast.Comment{
-		// 			Data:  p.tok.Data[2:],
-		// 			Start: p.tok.Start,
-		// 			End:   p.tok.End,
-		// 		})
-		// 	}
-
+		p.collectDocComment()
 		p.next()
 	}
 
@@ -53,6 +45,68 @@ func (p *parser) next() {
 	}
 }
 
+// Appends the current token to the comment group if it's a '##' comment
+// placed on its own line. A comment separated from the group by an empty
+// line starts a new group.
+func (p *parser) collectDocComment() {
+	if !strings.HasPrefix(p.tok.Data, "##") || !p.isFirstOnLine() {
+		return
+	}
+
+	if p.commentGroup != nil {
+		last := p.commentGroup.Comments[len(p.commentGroup.Comments)-1]
+
+		if p.tok.Start.Offset <= last.Start.Offset {
+			// Already collected (the parser was restored).
+			return
+		}
+
+		if p.tok.Start.Line > last.End.Line+1 {
+			p.commentGroup = nil
+		}
+	}
+
+	if p.commentGroup == nil {
+		p.commentGroup = &ast.CommentGroup{}
+	}
+
+	p.commentGroup.Comments = append(p.commentGroup.Comments, &ast.Comment{
+		Value: p.tok.Data[2:],
+		Start: p.tok.Start,
+		End:   p.tok.End,
+	})
+}
+
+func (p *parser) isFirstOnLine() bool {
+	for i := p.current - 1; i >= 0; i-- {
+		switch p.tokens[i].Kind {
+		case token.Whitespace, token.Tab:
+			continue
+
+		case token.NewLine:
+			return true
+
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns the comment group ending on the line directly above the
+// specified line and resets it.
+func (p *parser) takeDocs(line uint32) *ast.CommentGroup {
+	group := p.commentGroup
+	p.commentGroup = nil
+
+	if group == nil || group.PosEnd().Line+1 != line {
+		return nil
+	}
+
+	return group
+}
+
 func (p *parser) match(tokens ...token.Kind) bool {
 	return slices.Contains(tokens, p.tok.Kind)
 }
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"slices"
+
 	"github.com/saffage/jet/ast"
 	"github.com/saffage/jet/token"
 )
@@ -55,6 +57,9 @@ func (p *parser) declOr(f parseFunc) parseFunc {
 				p.restore(begin)
 				return f()
 			}
+
+			// It is a declaration with an error.
+			return nil
 		}
 
 		if isDecl {
@@ -78,9 +83,61 @@ func (p *parser) parseStmt() ast.Node {
 		return &ast.Empty{DesiredPos: p.tok.Start}
 	}
 
+	if p.isLabeledLoop() {
+		return p.parseLabeledLoop()
+	}
+
 	return p.declOr(p.parseExpr)()
 }
 
+// Reports whether the next tokens are 'label: while' or 'label: for'.
+func (p *parser) isLabeledLoop() bool {
+	if p.tok.Kind != token.Ident || p.current+2 >= len(p.tokens) {
+		return false
+	}
+
+	if p.tokens[p.current+1].Kind != token.Colon {
+		return false
+	}
+
+	switch p.tokens[p.current+2].Kind {
+	case token.KwWhile, token.KwFor:
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) parseLabeledLoop() ast.Node {
+	if p.flags&Trace != 0 {
+		defer un(trace(p))
+	}
+
+	label := p.parseIdentNode()
+	if label == nil || p.expect(token.Colon) == nil {
+		return nil
+	}
+
+	switch p.tok.Kind {
+	case token.KwWhile:
+		if loop, _ := p.parseWhile().(*ast.While); loop != nil {
+			loop.Label = label
+			return loop
+		}
+
+	case token.KwFor:
+		if loop, _ := p.parseFor().(*ast.For); loop != nil {
+			loop.Label = label
+			return loop
+		}
+
+	default:
+		p.errorExpectedToken(token.KwWhile, token.KwFor)
+	}
+
+	return nil
+}
+
 //------------------------------------------------
 // Expressions
 //------------------------------------------------
@@ -122,7 +179,7 @@ func (p *parser) parseSimpleExpr(allowAssign bool) ast.Node {
 		}
 
 		if p.match(token.Arrow) || p.match(exprStartKinds...) {
-			return p.parseFunction(params)
+			return p.parseFunction(nil, params)
 		}
 
 		x = params
@@ -167,6 +224,8 @@ func (p *parser) parseBinaryExpr(x ast.Node, precedence int) ast.Node {
 				"%s cannot be used in the binary expression",
 				tok.Kind.UserString(),
 			)
+			x = &ast.BadNode{DesiredPos: x.Pos()}
+			continue
 		}
 
 		x = &ast.Op{
@@ -265,6 +324,24 @@ func (p *parser) parsePrefixExpr() ast.Node {
 			return nil
 		}
 
+		if p.tok.Kind == token.LParen {
+			// Either a generic function '[T](x: T) -> T {...}'
+			// or an array type '[N](T, U)'.
+			params := p.parseParenList(p.declOr(p.parseExpr))
+			if params == nil {
+				return nil
+			}
+
+			if p.match(token.Arrow) || p.match(exprStartKinds...) {
+				return p.parseFunction(list, params)
+			}
+
+			return &ast.ArrayType{
+				X:    p.parseSuffixExpr(params),
+				Args: list,
+			}
+		}
+
 		if p.match(simpleExprStartKinds...) {
 			return &ast.ArrayType{
 				X:    p.parsePrefixExpr(),
@@ -349,6 +426,9 @@ func (p *parser) parseOperand() ast.Node {
 	case token.KwFor:
 		return p.parseFor()
 
+	case token.KwMatch:
+		return p.parseMatch()
+
 	case token.KwStruct:
 		return p.parseStructType()
 
@@ -405,7 +485,46 @@ func (p *parser) parseEllipsisExpr() ast.Node {
 	return p.parseSimpleExpr(false)
 }
 
-func (p *parser) parseFunction(params *ast.ParenList) ast.Node {
+// Parses the result type of the function. Unlike other expressions,
+// the parenthesized type followed by '{' is not a function, so the
+// function type can be used as the result: '() -> (() -> T) {...}'.
+// The function type in the result never has a body, the block after it
+// belongs to the enclosing function: '() -> () -> T {...}'.
+func (p *parser) parseResult() ast.Node {
+	if p.flags&Trace != 0 {
+		defer un(trace(p))
+	}
+
+	if p.tok.Kind != token.LParen {
+		return p.parseSimpleExpr(false)
+	}
+
+	list := p.parseParenList(p.declOr(p.parseExpr))
+	if list == nil {
+		return nil
+	}
+
+	if p.consume(token.Arrow) != nil {
+		result := p.parseResult()
+		if result == nil {
+			return nil
+		}
+
+		return &ast.Signature{
+			Params: list,
+			Result: result,
+		}
+	}
+
+	if len(list.Nodes) == 1 {
+		// Parentheses are used for grouping.
+		return list.Nodes[0]
+	}
+
+	return p.parseBinaryExpr(list, 2)
+}
+
+func (p *parser) parseFunction(typeParams *ast.BracketList, params *ast.ParenList) ast.Node {
 	if p.flags&Trace != 0 {
 		defer un(trace(p))
 	}
@@ -415,7 +534,7 @@ func (p *parser) parseFunction(params *ast.ParenList) ast.Node {
 	if p.consume(token.Arrow) != nil {
 		// (...) -> T
 		// (...) -> T {...}
-		result = p.parseSimpleExpr(false)
+		result = p.parseResult()
 		if result == nil {
 			return nil
 		}
@@ -438,15 +557,17 @@ func (p *parser) parseFunction(params *ast.ParenList) ast.Node {
 		}
 
 		return &ast.Signature{
-			Params: params,
-			Result: result,
+			TypeParams: typeParams,
+			Params:     params,
+			Result:     result,
 		}
 	}
 
 	return &ast.Function{
 		Signature: &ast.Signature{
-			Params: params,
-			Result: result,
+			TypeParams: typeParams,
+			Params:     params,
+			Result:     result,
 		},
 		Body: body,
 	}
@@ -518,6 +639,7 @@ func (p *parser) parseDecl() ast.Node {
 		defer un(trace(p))
 	}
 
+	docs := p.takeDocs(p.tok.Start.Line)
 	attributes := p.parseAttributeListNode()
 	if attributes != nil {
 		for p.tok.Kind == token.NewLine {
@@ -530,15 +652,23 @@ func (p *parser) parseDecl() ast.Node {
 		mutLoc = tokMut.Start
 	}
 
+	if attributes == nil && p.isTupleDecl() {
+		return p.parseTupleDecl(mutLoc)
+	}
+
 	if p.matchSequence(token.Ident, token.Colon) {
-		if decl := p.parseDeclNode(mutLoc, p.parseIdentNode()); decl != nil {
+		name := p.parseIdentNode()
+		if decl := p.parseDeclNode(mutLoc, name); decl != nil {
 			if attributes != nil {
 				decl.Attrs = attributes
 			}
-			// decl.Docs = p.commentGroup
-			// p.commentGroup = nil
+			decl.Docs = docs
 			return decl
 		}
+
+		// The name is kept in [ast.BadNode], so the checker knows
+		// which declaration is skipped.
+		p.badDecl = name
 	} else if mutLoc.IsValid() {
 		p.error(ErrorExpectedIdentAfterMut)
 	} else if attributes != nil {
@@ -584,6 +714,60 @@ func (p *parser) parseDeclNode(mut token.Pos, name *ast.Ident) *ast.Decl {
 	}
 }
 
+// Reports whether the current tokens are '(a, b) :'.
+func (p *parser) isTupleDecl() bool {
+	if p.tok.Kind != token.LParen {
+		return false
+	}
+
+	for i := p.current + 1; i+2 < len(p.tokens); i += 2 {
+		if p.tokens[i].Kind != token.Ident {
+			return false
+		}
+
+		switch p.tokens[i+1].Kind {
+		case token.Comma:
+			if p.tokens[i+2].Kind == token.RParen {
+				return i+3 < len(p.tokens) && p.tokens[i+3].Kind == token.Colon
+			}
+
+		case token.RParen:
+			return p.tokens[i+2].Kind == token.Colon
+
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+func (p *parser) parseTupleDecl(mut token.Pos) ast.Node {
+	if p.flags&Trace != 0 {
+		defer un(trace(p))
+	}
+
+	names := p.parseParenList(p.parseIdent)
+	if names == nil {
+		return nil
+	}
+
+	if p.expect(token.Colon) == nil || p.expect(token.Eq) == nil {
+		return nil
+	}
+
+	value := p.parseExpr()
+	if value == nil {
+		return nil
+	}
+
+	return &ast.TupleDecl{
+		Names: names,
+		Mut:   mut,
+		Value: value,
+	}
+}
+
 //------------------------------------------------
 // Language constructions
 //------------------------------------------------
@@ -617,23 +801,38 @@ func (p *parser) parseStructType() ast.Node {
 		return nil
 	}
 
+	var typeParams *ast.BracketList
+
+	if p.match(token.LBracket) {
+		if typeParams = p.parseBracketList(p.parseIdent); typeParams == nil {
+			return nil
+		}
+	}
+
 	body := p.parseBlockFunc(p.parseDecl)
 	if body == nil {
 		return nil
 	}
 
-	fields := make([]*ast.Decl, len(body.Nodes))
+	fields := make([]*ast.Decl, 0, len(body.Nodes))
+	methods := []*ast.Decl{}
 
-	// Filter bad nodes.
-	for i := range fields {
-		fields[i], _ = body.Nodes[i].(*ast.Decl)
+	// Filter bad nodes. Declarations with a value are methods.
+	for _, node := range body.Nodes {
+		if decl, _ := node.(*ast.Decl); decl == nil || decl.Value == nil {
+			fields = append(fields, decl)
+		} else {
+			methods = append(methods, decl)
+		}
 	}
 
 	return &ast.StructType{
-		Fields: fields,
-		TokPos: tok.Start,
-		Open:   body.Open,
-		Close:  body.Close,
+		TypeParams: typeParams,
+		Fields:     fields,
+		Methods:    methods,
+		TokPos:     tok.Start,
+		Open:       body.Open,
+		Close:      body.Close,
 	}
 }
 
@@ -647,24 +846,57 @@ func (p *parser) parseEnumType() ast.Node {
 		return nil
 	}
 
-	body := p.parseBlockFunc(p.parseIdent)
+	body := p.parseBlockFunc(p.parseEnumMember)
 	if body == nil {
 		return nil
 	}
 
-	fields := make([]*ast.Ident, len(body.Nodes))
+	variants := make([]*ast.Variant, 0, len(body.Nodes))
+	methods := []*ast.Decl{}
 
 	// Filter bad nodes.
-	for i := range fields {
-		fields[i], _ = body.Nodes[i].(*ast.Ident)
+	for _, node := range body.Nodes {
+		if decl, _ := node.(*ast.Decl); decl != nil {
+			methods = append(methods, decl)
+		} else {
+			variant, _ := node.(*ast.Variant)
+			variants = append(variants, variant)
+		}
 	}
 
 	return &ast.EnumType{
-		Fields: fields,
-		TokPos: tok.Start,
-		Open:   body.Open,
-		Close:  body.Close,
+		Variants: variants,
+		Methods:  methods,
+		TokPos:   tok.Start,
+		Open:     body.Open,
+		Close:    body.Close,
+	}
+}
+
+// Parses an enum variant or a method declaration.
+func (p *parser) parseEnumMember() ast.Node {
+	if p.flags&Trace != 0 {
+		defer un(trace(p))
+	}
+
+	if p.matchSequence(token.Ident, token.Colon) || p.match(token.At, token.KwMut) {
+		return p.parseDecl()
+	}
+
+	ident, _ := p.parseIdent().(*ast.Ident)
+	if ident == nil {
+		return nil
+	}
+
+	variant := &ast.Variant{Ident: ident}
+
+	if p.match(token.LParen) {
+		if variant.Fields = p.parseParenList(p.parseDecl); variant.Fields == nil {
+			return nil
+		}
 	}
+
+	return variant
 }
 
 func (p *parser) parseIf() ast.Node {
@@ -706,6 +938,73 @@ func (p *parser) parseIf() ast.Node {
 	}
 }
 
+func (p *parser) parseMatch() ast.Node {
+	if p.flags&Trace != 0 {
+		defer un(trace(p))
+	}
+
+	tok := p.expect(token.KwMatch)
+	if tok == nil {
+		return nil
+	}
+
+	x := p.parseSimpleExpr(false)
+	if x == nil {
+		start, end := p.skip()
+		p.errorAt(ErrorExpectedExpr, start, end)
+		return nil
+	}
+
+	body := p.parseBlockFunc(p.parseMatchArm)
+	if body == nil {
+		return nil
+	}
+
+	arms := make([]*ast.MatchArm, len(body.Nodes))
+
+	// Filter bad nodes.
+	for i := range arms {
+		arms[i], _ = body.Nodes[i].(*ast.MatchArm)
+	}
+
+	return &ast.Match{
+		X:      x,
+		Arms:   arms,
+		TokPos: tok.Start,
+		Open:   body.Open,
+		Close:  body.Close,
+	}
+}
+
+func (p *parser) parseMatchArm() ast.Node {
+	if p.flags&Trace != 0 {
+		defer un(trace(p))
+	}
+
+	variant, _ := p.parseIdent().(*ast.Ident)
+	if variant == nil {
+		return nil
+	}
+
+	arm := &ast.MatchArm{Variant: variant}
+
+	if p.match(token.LParen) {
+		if arm.Names = p.parseParenList(p.parseIdent); arm.Names == nil {
+			return nil
+		}
+	}
+
+	if p.expect(token.FatArrow) == nil {
+		return nil
+	}
+
+	if arm.Body = p.parseExpr(); arm.Body == nil {
+		return nil
+	}
+
+	return arm
+}
+
 func (p *parser) parseElse() ast.Node {
 	if p.flags&Trace != 0 {
 		defer un(trace(p))
@@ -895,7 +1194,7 @@ func (p *parser) parseReturn() ast.Node {
 
 	var x ast.Node
 
-	if !p.match(append(endOfStmtKinds, token.EOF)...) {
+	if !p.match(append(endOfStmtKinds, token.EOF)...) && !p.match(token.RCurly) {
 		x = p.parseExpr()
 		if x == nil {
 			return nil
@@ -1026,13 +1325,86 @@ func (p *parser) parseBlockFunc(f parseFunc) *ast.CurlyList {
 	return nil
 }
 
+// Parses the declaration or the import, imports are allowed only at
+// the top level.
+func (p *parser) parseTopLevelDecl() ast.Node {
+	if p.isImport() {
+		return p.parseImport()
+	}
+
+	return p.parseDecl()
+}
+
+// Reports whether the next tokens are the import, which can be
+// preceded by the attribute list.
+func (p *parser) isImport() bool {
+	i := p.current
+
+	if p.tokens[i].Kind == token.At {
+		// Skip the attribute list and the new lines after it.
+		for depth := 0; i < len(p.tokens); i++ {
+			if kind := p.tokens[i].Kind; kind == token.LBracket {
+				depth++
+			} else if kind == token.RBracket {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+
+		i++
+
+		for i < len(p.tokens) && p.tokens[i].Kind == token.NewLine {
+			i++
+		}
+	}
+
+	return i < len(p.tokens) && p.tokens[i].Kind == token.KwImport
+}
+
+func (p *parser) parseImport() ast.Node {
+	if p.flags&Trace != 0 {
+		defer un(trace(p))
+	}
+
+	attributes := p.parseAttributeListNode()
+	if attributes != nil {
+		for p.tok.Kind == token.NewLine {
+			p.next()
+		}
+	}
+
+	tok := p.expect(token.KwImport)
+	if tok == nil {
+		return nil
+	}
+
+	path := []*ast.Ident{}
+
+	for {
+		name := p.parseIdentNode()
+		if name == nil {
+			p.errorExpectedToken(token.Ident)
+			return nil
+		}
+
+		path = append(path, name)
+
+		if p.consume(token.Dot) == nil {
+			break
+		}
+	}
+
+	return &ast.Import{Attrs: attributes, Path: path, TokPos: tok.Start}
+}
+
 func (p *parser) parseDeclList() *ast.StmtList {
 	if p.flags&Trace != 0 {
 		defer un(trace(p))
 	}
 
 	if nodes, _ := p.listWithDelimiter(
-		p.parseDecl,
+		p.parseTopLevelDecl,
 		token.EOF,
 		token.Semicolon,
 		token.NewLine,
@@ -1061,6 +1433,8 @@ func (p *parser) listWithDelimiter(
 	}
 
 	nodes = []ast.Node{}
+	p.delimiters = append(p.delimiters, delimiter)
+	defer func() { p.delimiters = p.delimiters[:len(p.delimiters)-1] }()
 
 	// List = Expr {Separator Expr} [Separator]
 	for {
@@ -1080,6 +1454,7 @@ func (p *parser) listWithDelimiter(
 		}
 
 		nodeStart := p.tok.Start
+		p.badDecl = nil
 
 		if node := f(); node != nil {
 			switch {
@@ -1095,14 +1470,24 @@ func (p *parser) listWithDelimiter(
 				// [parseFunc] set the correct node, but no separator was found.
 				// Report it and assign [ast.BadNode] instead.
 				p.errorAt(ErrorUnterminatedExpr, node.Pos(), node.PosEnd())
+
+				if decl, _ := node.(*ast.Decl); decl != nil {
+					p.badDecl = decl.Ident
+				}
 			}
 		}
 
-		// Something went wrong, advance to some delimiter and
-		// continue parsing elements until we find the [closing] token.
+		// Something went wrong, advance to the next separator or the
+		// delimiter and continue parsing elements after it.
 		p.skip(append(separators, delimiter)...)
-		p.consume(separators...)
-		nodes = append(nodes, &ast.BadNode{DesiredPos: nodeStart})
+		nodes = append(nodes, &ast.BadNode{DesiredPos: nodeStart, Decl: p.badDecl})
+		p.badDecl = nil
+
+		if p.consume(separators...) == nil && p.tok.Kind != delimiter && p.tok.Kind != token.EOF {
+			// The skipping was stopped by a closing bracket of the
+			// enclosing list, which must recover from the error.
+			return nil, false
+		}
 	}
 
 	return nodes, wasSeparator
@@ -1126,6 +1511,11 @@ func (p *parser) parseBracketedList(
 	nodes, wasSeparator = p.listWithDelimiter(f, closing, separators...)
 
 	if nodes == nil {
+		// The list was stopped by the end of the file or by a closing
+		// bracket of the enclosing list.
+		if p.tok.Kind == token.EOF || slices.Contains(closingKinds, p.tok.Kind) {
+			p.errorAt(ErrorBracketIsNeverClosed, openLoc, openLoc)
+		}
 		return nil, token.Pos{}, token.Pos{}, false
 	}
 
@@ -62,8 +62,30 @@ func (p *parser) skip(to ...token.Kind) (start, end token.Pos) {
 	}
 
 	start = p.tok.Start
+	depth := 0
+
+	// Brackets opened while skipping are skipped together with their
+	// contents. A closing bracket without the opening one stops skipping
+	// if it closes one of the lists being parsed, so the list recovers
+	// from the error, a stray bracket of another kind is skipped.
+	for p.tok.Kind != token.EOF {
+		isClosing := slices.Contains(closingKinds, p.tok.Kind)
+
+		if depth == 0 {
+			if isClosing && slices.Contains(p.delimiters, p.tok.Kind) ||
+				!isClosing && slices.Contains(to, p.tok.Kind) {
+				break
+			}
+		}
+
+		switch {
+		case slices.Contains(openingKinds, p.tok.Kind):
+			depth++
+
+		case isClosing && depth > 0:
+			depth--
+		}
 
-	for p.tok.Kind != token.EOF && !slices.Contains(to, p.tok.Kind) {
 		end = p.tok.End
 		p.next()
 	}
@@ -96,6 +118,18 @@ var (
 		token.RBracket,
 	}...)
 
+	openingKinds = []token.Kind{
+		token.LParen,
+		token.LCurly,
+		token.LBracket,
+	}
+
+	closingKinds = []token.Kind{
+		token.RParen,
+		token.RCurly,
+		token.RBracket,
+	}
+
 	simpleExprStartKinds = []token.Kind{
 		token.Minus,
 		token.Bang,
@@ -109,6 +143,7 @@ var (
 		token.KwIf,
 		token.KwWhile,
 		token.KwFor,
+		token.KwMatch,
 		token.KwStruct,
 		token.KwEnum,
 		token.LCurly,
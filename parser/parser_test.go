@@ -2,8 +2,10 @@ package parser
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/saffage/jet/ast"
@@ -153,6 +155,255 @@ func TestExprs(t *testing.T) {
 	}
 }
 
+func TestEncodeDecodeJSON(t *testing.T) {
+	input := `
+## Documentation
+## comment.
+@[comptime]
+mut foo: [2]i32 = 10
+
+bar := (a: *i32, b: i32) -> i32 {
+	defer $println("hi")
+	if a.* == b { return 1 } else if b > 0 { return 2 } else { return 3 }
+	for i in 0..<b { x := S(x = i) }
+	return -a.*
+}`
+	tokens := scanner.MustScan(([]byte)(input), 1, scanner.SkipWhitespace)
+	stmts, err := Parse(tokens, DefaultFlags)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	decl, _ := stmts.Nodes[0].(*ast.Decl)
+
+	if decl == nil || decl.Docs == nil || len(decl.Docs.Comments) != 2 {
+		t.Errorf("expected the documentation comment to be attached to the declaration")
+	}
+
+	encoded, err := ast.EncodeJSON(stmts)
+	if err != nil {
+		t.Fatal("unexpected JSON encode error:", err)
+	}
+
+	decoded, err := ast.DecodeJSON(encoded)
+	if err != nil {
+		t.Fatal("unexpected JSON decode error:", err)
+	}
+
+	if !reflect.DeepEqual(decoded, ast.Node(stmts)) {
+		t.Errorf("decoded AST is not equal to the original\ngot %s", decoded.Repr())
+	}
+}
+
+func TestTypeParams(t *testing.T) {
+	input := `
+max := [T](a: T, b: T) -> T { if a > b { a } else { b } }
+Pair := struct[T, U] { first: T; second: U }
+pairs: [2](i32, i32)`
+	tokens := scanner.MustScan(([]byte)(input), 1, scanner.SkipWhitespace)
+	stmts, err := Parse(tokens, DefaultFlags)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(stmts.Nodes) != 3 {
+		t.Fatalf("expected 3 declarations, got %d", len(stmts.Nodes))
+	}
+
+	fn, _ := stmts.Nodes[0].(*ast.Decl).Value.(*ast.Function)
+	if fn == nil || fn.TypeParams == nil || len(fn.TypeParams.Nodes) != 1 {
+		t.Errorf("expected generic function, got %s", stmts.Nodes[0].Repr())
+	}
+
+	structType, _ := stmts.Nodes[1].(*ast.Decl).Value.(*ast.StructType)
+	if structType == nil || structType.TypeParams == nil || len(structType.TypeParams.Nodes) != 2 {
+		t.Errorf("expected generic struct, got %s", stmts.Nodes[1].Repr())
+	}
+
+	if _, isArray := stmts.Nodes[2].(*ast.Decl).Type.(*ast.ArrayType); !isArray {
+		t.Errorf("expected array type, got %s", stmts.Nodes[2].Repr())
+	}
+
+	clone := ast.Clone(fn)
+	if !reflect.DeepEqual(clone, fn) || clone.TypeParams == fn.TypeParams {
+		t.Errorf("expected a deep copy of the function")
+	}
+}
+
+func TestMethods(t *testing.T) {
+	input := `
+Node := struct { data: i32; print := (self: *Node) {} }
+Color := enum { Red; Green; code := (self: Color) -> i32 { 1 } }`
+	tokens := scanner.MustScan(([]byte)(input), 1, scanner.SkipWhitespace)
+	stmts, err := Parse(tokens, DefaultFlags)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(stmts.Nodes) != 2 {
+		t.Fatalf("expected 2 declarations, got %d", len(stmts.Nodes))
+	}
+
+	structType, _ := stmts.Nodes[0].(*ast.Decl).Value.(*ast.StructType)
+	if structType == nil || len(structType.Fields) != 1 || len(structType.Methods) != 1 {
+		t.Errorf("expected struct with 1 field and 1 method, got %s", stmts.Nodes[0].Repr())
+	}
+
+	enumType, _ := stmts.Nodes[1].(*ast.Decl).Value.(*ast.EnumType)
+	if enumType == nil || len(enumType.Variants) != 2 || len(enumType.Methods) != 1 {
+		t.Errorf("expected enum with 2 variants and 1 method, got %s", stmts.Nodes[1].Repr())
+	}
+}
+
+func TestMatch(t *testing.T) {
+	input := `
+Shape := enum { Circle(r: f64); Rect(w: f64, h: f64); Empty }
+area := (s: Shape) -> f64 { match s { Circle(r) => r * r; Rect(w, h) => w * h; _ => 0.0 } }`
+	tokens := scanner.MustScan(([]byte)(input), 1, scanner.SkipWhitespace)
+	stmts, err := Parse(tokens, DefaultFlags)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	enumType, _ := stmts.Nodes[0].(*ast.Decl).Value.(*ast.EnumType)
+	if enumType == nil || len(enumType.Variants) != 3 ||
+		enumType.Variants[1].Fields == nil || len(enumType.Variants[1].Fields.Nodes) != 2 ||
+		enumType.Variants[2].Fields != nil {
+		t.Errorf("expected enum with 3 variants, got %s", stmts.Nodes[0].Repr())
+	}
+
+	fn, _ := stmts.Nodes[1].(*ast.Decl).Value.(*ast.Function)
+	if fn == nil {
+		t.Fatalf("expected function, got %s", stmts.Nodes[1].Repr())
+	}
+
+	match, _ := fn.Body.(*ast.CurlyList).Nodes[0].(*ast.Match)
+	if match == nil || len(match.Arms) != 3 || match.Arms[2].Variant.Name != "_" || match.Arms[2].Names != nil {
+		t.Errorf("expected match with 3 arms, got %s", fn.Body.Repr())
+	}
+}
+
+func TestFunctionResult(t *testing.T) {
+	input := `
+choose := (c: bool) -> (() -> i32) { if c { one } else { two } }
+apply := (f: (i32) -> i32, x: i32) -> i32 { f(x) }
+make_adder := (n: i32) -> (i32) -> i32 { (x: i32) -> i32 { x + n } }`
+	tokens := scanner.MustScan(([]byte)(input), 1, scanner.SkipWhitespace)
+	stmts, err := Parse(tokens, DefaultFlags)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(stmts.Nodes) != 3 {
+		t.Fatalf("expected 3 declarations, got %d", len(stmts.Nodes))
+	}
+
+	// The block after the unparenthesized function type in the result
+	// is the body of the declared function.
+	for _, i := range []int{0, 2} {
+		fn, _ := stmts.Nodes[i].(*ast.Decl).Value.(*ast.Function)
+		if fn == nil || fn.Body == nil {
+			t.Fatalf("expected function with body, got %s", stmts.Nodes[i].Repr())
+		}
+
+		if _, isSignature := fn.Result.(*ast.Signature); !isSignature {
+			t.Errorf("expected function type as the result, got %s", fn.Result.Repr())
+		}
+	}
+
+	fn, _ := stmts.Nodes[1].(*ast.Decl).Value.(*ast.Function)
+	if fn == nil || len(fn.Params.Nodes) != 2 {
+		t.Errorf("expected function with 2 params, got %s", stmts.Nodes[1].Repr())
+	}
+}
+
+func TestTupleDecl(t *testing.T) {
+	input := `
+divmod := (a: i32, b: i32) -> (i32, i32) { (a / b, a % b) }
+main := () { (q, _) := divmod(7, 2); mut (x, y,) := (1, 2); (q) }`
+	tokens := scanner.MustScan(([]byte)(input), 1, scanner.SkipWhitespace)
+	stmts, err := Parse(tokens, DefaultFlags)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	fn, _ := stmts.Nodes[0].(*ast.Decl).Value.(*ast.Function)
+	if fn == nil || fn.Body == nil {
+		t.Fatalf("expected function with body, got %s", stmts.Nodes[0].Repr())
+	}
+
+	if result, _ := fn.Result.(*ast.ParenList); result == nil || len(result.Nodes) != 2 {
+		t.Errorf("expected tuple result, got %s", fn.Result.Repr())
+	}
+
+	fn, _ = stmts.Nodes[1].(*ast.Decl).Value.(*ast.Function)
+	if fn == nil {
+		t.Fatalf("expected function, got %s", stmts.Nodes[1].Repr())
+	}
+
+	body := fn.Body.(*ast.CurlyList).Nodes
+	if len(body) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(body))
+	}
+
+	decl, _ := body[0].(*ast.TupleDecl)
+	if decl == nil || len(decl.Names.Nodes) != 2 || decl.Mut.IsValid() {
+		t.Errorf("expected tuple declaration, got %s", body[0].Repr())
+	}
+
+	decl, _ = body[1].(*ast.TupleDecl)
+	if decl == nil || len(decl.Names.Nodes) != 2 || !decl.Mut.IsValid() {
+		t.Errorf("expected mutable tuple declaration, got %s", body[1].Repr())
+	}
+
+	if _, isParenList := body[2].(*ast.ParenList); !isParenList {
+		t.Errorf("expected expression, got %s", body[2].Repr())
+	}
+}
+
+func TestLabeledLoop(t *testing.T) {
+	input := `
+main := () {
+	outer: for i in 0..<3 { inner: while true { break outer } }
+	x: i32 = 1
+}`
+	tokens := scanner.MustScan(([]byte)(input), 1, scanner.SkipWhitespace)
+	stmts, err := Parse(tokens, DefaultFlags)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	body := stmts.Nodes[0].(*ast.Decl).Value.(*ast.Function).Body.(*ast.CurlyList).Nodes
+	if len(body) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(body))
+	}
+
+	outer, _ := body[0].(*ast.For)
+	if outer == nil || outer.Label == nil || outer.Label.Name != "outer" {
+		t.Fatalf("expected labeled for loop, got %s", body[0].Repr())
+	}
+
+	inner, _ := outer.Body.Nodes[0].(*ast.While)
+	if inner == nil || inner.Label == nil || inner.Label.Name != "inner" {
+		t.Fatalf("expected labeled while loop, got %s", outer.Body.Nodes[0].Repr())
+	}
+
+	if brk, _ := inner.Body.Nodes[0].(*ast.Break); brk == nil || brk.Label == nil || brk.Label.Name != "outer" {
+		t.Errorf("expected 'break outer', got %s", inner.Body.Nodes[0].Repr())
+	}
+
+	if _, isDecl := body[1].(*ast.Decl); !isDecl {
+		t.Errorf("expected declaration, got %s", body[1].Repr())
+	}
+}
+
 func checkError(t *testing.T, got, want error) bool {
 	if want == nil && got == nil {
 		return true
@@ -193,3 +444,113 @@ func JSONBytesEqual(a, b []byte) (bool, error) {
 
 	return reflect.DeepEqual(jsonB, jsonA), nil
 }
+
+func TestImport(t *testing.T) {
+	input := `
+import net.http
+@[allow(unused)]
+import util
+main := () {}`
+	tokens := scanner.MustScan(([]byte)(input), 1, scanner.SkipWhitespace)
+	stmts, err := Parse(tokens, DefaultFlags)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(stmts.Nodes) != 3 {
+		t.Fatalf("expected 3 declarations, got %d", len(stmts.Nodes))
+	}
+
+	for i, expected := range []string{"import net.http", "@[allow(unused)] import util"} {
+		node, _ := stmts.Nodes[i].(*ast.Import)
+		if node == nil || node.Repr() != expected {
+			t.Errorf("expected '%s', got %s", expected, stmts.Nodes[i].Repr())
+		}
+	}
+
+	if name := stmts.Nodes[0].(*ast.Import).Module().Name; name != "http" {
+		t.Errorf("expected module 'http', got '%s'", name)
+	}
+
+	encoded, err := ast.EncodeJSON(stmts)
+	if err != nil {
+		t.Fatal("unexpected JSON encode error:", err)
+	}
+
+	decoded, err := ast.DecodeJSON(encoded)
+	if err != nil {
+		t.Fatal("unexpected JSON decode error:", err)
+	}
+
+	if !reflect.DeepEqual(decoded, ast.Node(stmts)) {
+		t.Errorf("decoded AST is not equal to the original\ngot %s", decoded.Repr())
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	input := `
+a := (x: i32 y: i32) -> i32 { x }
+
+}
+
+b := () {
+	y := (1, 2
+	$println(y)
+}
+
+c := () -> i32 {
+	return 1 2
+	3
+}
+
+main := () {}`
+	tokens := scanner.MustScan(([]byte)(input), 1, scanner.SkipWhitespace)
+	stmts, err := Parse(tokens, DefaultFlags)
+
+	errs := []string{}
+	if err != nil {
+		for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+			err := err.(Error)
+			errs = append(errs, fmt.Sprintf("%d:%d: %s", err.Start.Line, err.Start.Char, err.Error()))
+		}
+	}
+
+	expected := []string{
+		"2:7: unterminated expression",
+		"4:1: expected declaration",
+		"7:11: unterminated expression",
+		"7:7: bracket is never closed",
+		"12:2: unterminated expression",
+	}
+
+	if !slices.Equal(errs, expected) {
+		t.Errorf("unexpected errors\nexpect: %q\nactual: %q", expected, errs)
+	}
+
+	decls := []string{}
+	for _, node := range stmts.Nodes {
+		if decl, _ := node.(*ast.Decl); decl != nil {
+			decls = append(decls, decl.Ident.Name)
+		}
+	}
+
+	if !slices.Equal(decls, []string{"a", "b", "c", "main"}) {
+		t.Errorf("unexpected declarations: %q", decls)
+	}
+
+	body := stmts.Nodes[len(stmts.Nodes)-2].(*ast.Decl).Value.(*ast.Function).Body.(*ast.CurlyList).Nodes
+	if len(body) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(body))
+	}
+
+	if _, isBad := body[0].(*ast.BadNode); !isBad {
+		t.Errorf("expected bad node, got %s", body[0].Repr())
+	}
+
+	// The name of the replaced declaration is kept.
+	body = stmts.Nodes[len(stmts.Nodes)-3].(*ast.Decl).Value.(*ast.Function).Body.(*ast.CurlyList).Nodes
+	if bad, _ := body[0].(*ast.BadNode); bad == nil || bad.Decl == nil || bad.Decl.Name != "y" {
+		t.Errorf("expected bad node of the declaration 'y', got %s", body[0].Repr())
+	}
+}
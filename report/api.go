@@ -15,6 +15,24 @@ var ShowLine = true
 // Specifies a level of messages.
 var Level = KindNote
 
+// If not nil, every reported message is passed to the handler instead
+// of being displayed. Used by tools that need the messages themselves
+// (for example, the language server).
+var Handler func(Diagnostic)
+
+// Diagnostic is a reported message with its location. Positions are
+// invalid if the message was reported without a location.
+type Diagnostic struct {
+	Kind    Kind
+	Tag     string
+	Start   token.Pos
+	End     token.Pos
+	Message string
+	Code    string       // Code of the diagnostic, for example 'E0301'. Can be empty.
+	Notes   []Diagnostic // Notes that explain the message.
+	Fixes   []Fix        // Suggested changes of the source code.
+}
+
 // Reporter is an interface that is used to make the report prettier/clearer.
 //
 // Types implementing this interface must call the functions they need
@@ -53,6 +71,12 @@ func Errors(errs ...error) {
 	}
 }
 
+// Reports the diagnostic and its notes. In the text format, the
+// notes are displayed as separate messages after the diagnostic.
+func Emit(d Diagnostic) {
+	reportDiagnostic(d)
+}
+
 // Reports a message of the specified kind.
 func Report(kind Kind, args ...any) {
 	reportInternal(kind, "", fmt.Sprint(args...))
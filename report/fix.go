@@ -0,0 +1,53 @@
+package report
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/token"
+)
+
+// Fix is a machine-applicable suggestion attached to the diagnostic:
+// the source code from Start to End (both inclusive, like the range of
+// the diagnostic) is replaced with NewText.
+type Fix struct {
+	Message string // Description of the fix, for example "did you mean 'count'?".
+	Start   token.Pos
+	End     token.Pos
+	NewText string
+}
+
+// Applies the fixes to the source code of the file and returns the
+// result with the applied fixes. The fixes of other
+// files and the fixes that overlap with a previous fix are skipped,
+// the same fix reported twice is applied once.
+func ApplyFixes(src []byte, fileID config.FileID, fixes []Fix) ([]byte, []Fix) {
+	fixes = slices.DeleteFunc(slices.Clone(fixes), func(fix Fix) bool {
+		return fix.Start.FileID != fileID ||
+			fix.Start.Offset > fix.End.Offset ||
+			fix.End.Offset >= uint64(len(src))
+	})
+
+	slices.SortStableFunc(fixes, func(a, b Fix) int {
+		return cmp.Compare(a.Start.Offset, b.Start.Offset)
+	})
+
+	result := bytes.Buffer{}
+	applied, next := []Fix{}, uint64(0)
+
+	for _, fix := range fixes {
+		if fix.Start.Offset < next {
+			continue
+		}
+
+		result.Write(src[next:fix.Start.Offset])
+		result.WriteString(fix.NewText)
+		next = fix.End.Offset + 1
+		applied = append(applied, fix)
+	}
+
+	result.Write(src[next:])
+	return result.Bytes(), applied
+}
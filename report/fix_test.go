@@ -0,0 +1,32 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/saffage/jet/token"
+)
+
+func TestApplyFixes(t *testing.T) {
+	src := []byte("x := cuont + lne\n")
+	pos := func(offset uint64) token.Pos {
+		return token.Pos{FileID: 1, Offset: offset, Line: 1, Char: uint32(offset) + 1}
+	}
+
+	fixes := []Fix{
+		{Message: "did you mean 'len'?", Start: pos(13), End: pos(15), NewText: "len"},
+		{Message: "did you mean 'count'?", Start: pos(5), End: pos(9), NewText: "count"},
+		{Message: "did you mean 'count'?", Start: pos(5), End: pos(9), NewText: "count"},
+		{Message: "overlaps", Start: pos(8), End: pos(13), NewText: "?"},
+		{Message: "other file", Start: token.Pos{FileID: 2, Line: 1, Char: 1}, NewText: "?"},
+	}
+
+	result, applied := ApplyFixes(src, 1, fixes)
+
+	if string(result) != "x := count + len\n" {
+		t.Errorf("unexpected result %q", result)
+	}
+
+	if len(applied) != 2 || applied[0].NewText != "count" || applied[1].NewText != "len" {
+		t.Errorf("unexpected applied fixes %+v", applied)
+	}
+}
@@ -0,0 +1,343 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/token"
+)
+
+// Format of the reported messages.
+type Format byte
+
+const (
+	// Human-readable text with the source lines, the default format.
+	FormatText Format = iota
+
+	// Every message is written as a JSON object on a separate line.
+	FormatJSON
+
+	// Messages are collected and written as a single SARIF 2.1.0
+	// document by [Flush].
+	FormatSARIF
+)
+
+// Specifies the format of the reported messages.
+var OutputFormat = FormatText
+
+// Messages in the machine-readable formats are written to this writer.
+var Output io.Writer = os.Stderr
+
+var formats = map[string]Format{
+	"text":  FormatText,
+	"json":  FormatJSON,
+	"sarif": FormatSARIF,
+}
+
+// Returns the format with the specified name ('text', 'json' or 'sarif').
+func ParseFormat(name string) (Format, error) {
+	if format, ok := formats[name]; ok {
+		return format, nil
+	}
+	return FormatText, fmt.Errorf("unknown diagnostics format '%s' (expected text, json or sarif)", name)
+}
+
+// Writes the messages collected in the SARIF format. Does nothing
+// if the messages are written immediately, so it is safe to call it
+// before the compiler exits regardless of the format.
+func Flush() {
+	if OutputFormat != FormatSARIF || sarifFlushed {
+		return
+	}
+
+	sarifFlushed = true
+	writeJSON(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "jet", Version: config.Version}},
+			Results: sarifResults,
+		}},
+	})
+	sarifResults = nil
+}
+
+// Writes the diagnostic in the machine-readable format. Hints and debug
+// messages describe the progress of the compiler rather than the code,
+// so they are not written.
+func emit(d Diagnostic) {
+	if d.Kind == KindDebug || d.Kind == KindHint {
+		return
+	}
+
+	switch OutputFormat {
+	case FormatJSON:
+		writeJSON(toJSON(d))
+
+	case FormatSARIF:
+		sarifResults = append(sarifResults, toSARIF(d))
+
+	default:
+		panic("unreachable")
+	}
+}
+
+func writeJSON(v any) {
+	encoder := json.NewEncoder(Output)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------
+// JSON
+//------------------------------------------------
+
+type jsonDiagnostic struct {
+	Severity string           `json:"severity"`
+	Code     string           `json:"code,omitempty"`
+	Tag      string           `json:"tag,omitempty"`
+	Message  string           `json:"message"`
+	Location *jsonLocation    `json:"location,omitempty"`
+	Notes    []jsonDiagnostic `json:"notes,omitempty"`
+	Fixes    []jsonFix        `json:"fixes,omitempty"`
+}
+
+// The text in the location is replaced with the new text.
+type jsonFix struct {
+	Message  string        `json:"message"`
+	Location *jsonLocation `json:"location"`
+	NewText  string        `json:"newText"`
+}
+
+// Lines and columns start at 1, the end column is exclusive.
+type jsonLocation struct {
+	File        string `json:"file,omitempty"`
+	StartLine   uint32 `json:"startLine"`
+	StartColumn uint32 `json:"startColumn,omitempty"`
+	EndLine     uint32 `json:"endLine,omitempty"`
+	EndColumn   uint32 `json:"endColumn,omitempty"`
+}
+
+func toJSON(d Diagnostic) jsonDiagnostic {
+	result := jsonDiagnostic{
+		Severity: d.Kind.String(),
+		Code:     d.Code,
+		Tag:      d.Tag,
+		Message:  d.Message,
+	}
+
+	result.Location = toJSONLocation(d.Start, d.End)
+
+	for _, note := range d.Notes {
+		result.Notes = append(result.Notes, toJSON(note))
+	}
+
+	for _, fix := range d.Fixes {
+		result.Fixes = append(result.Fixes, jsonFix{
+			Message:  fix.Message,
+			Location: toJSONLocation(fix.Start, fix.End),
+			NewText:  fix.NewText,
+		})
+	}
+
+	return result
+}
+
+func toJSONLocation(start, end token.Pos) *jsonLocation {
+	if start.Line == 0 {
+		return nil
+	}
+
+	location := &jsonLocation{File: filePath(start)}
+	location.StartLine, location.StartColumn,
+		location.EndLine, location.EndColumn = lineRange(start, end)
+
+	return location
+}
+
+//------------------------------------------------
+// SARIF
+//------------------------------------------------
+
+var (
+	sarifResults = []sarifResult{}
+	sarifFlushed = false
+)
+
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	sarifResult struct {
+		RuleID           string          `json:"ruleId,omitempty"`
+		Level            string          `json:"level"`
+		Message          sarifMessage    `json:"message"`
+		Locations        []sarifLocation `json:"locations,omitempty"`
+		RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+		Fixes            []sarifFix      `json:"fixes,omitempty"`
+		Properties       *sarifProps     `json:"properties,omitempty"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+		Message          *sarifMessage          `json:"message,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine   uint32 `json:"startLine"`
+		StartColumn uint32 `json:"startColumn,omitempty"`
+		EndLine     uint32 `json:"endLine,omitempty"`
+		EndColumn   uint32 `json:"endColumn,omitempty"`
+	}
+
+	sarifFix struct {
+		Description     sarifMessage          `json:"description"`
+		ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+	}
+
+	sarifArtifactChange struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Replacements     []sarifReplacement    `json:"replacements"`
+	}
+
+	sarifReplacement struct {
+		DeletedRegion   sarifRegion          `json:"deletedRegion"`
+		InsertedContent sarifArtifactContent `json:"insertedContent"`
+	}
+
+	sarifArtifactContent struct {
+		Text string `json:"text"`
+	}
+
+	sarifProps struct {
+		Tag string `json:"tag"`
+	}
+)
+
+func toSARIF(d Diagnostic) sarifResult {
+	result := sarifResult{
+		RuleID:  d.Code,
+		Level:   sarifLevel(d.Kind),
+		Message: sarifMessage{d.Message},
+	}
+
+	if location := toSARIFLocation(d.Start, d.End); location != nil {
+		result.Locations = []sarifLocation{{PhysicalLocation: location}}
+	}
+
+	for _, note := range d.Notes {
+		result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+			PhysicalLocation: toSARIFLocation(note.Start, note.End),
+			Message:          &sarifMessage{note.Message},
+		})
+	}
+
+	for _, fix := range d.Fixes {
+		if location := toSARIFLocation(fix.Start, fix.End); location != nil {
+			result.Fixes = append(result.Fixes, sarifFix{
+				Description: sarifMessage{fix.Message},
+				ArtifactChanges: []sarifArtifactChange{{
+					ArtifactLocation: location.ArtifactLocation,
+					Replacements: []sarifReplacement{{
+						DeletedRegion:   location.Region,
+						InsertedContent: sarifArtifactContent{fix.NewText},
+					}},
+				}},
+			})
+		}
+	}
+
+	if d.Tag != "" {
+		result.Properties = &sarifProps{Tag: d.Tag}
+	}
+
+	return result
+}
+
+func toSARIFLocation(start, end token.Pos) *sarifPhysicalLocation {
+	if start.Line == 0 {
+		return nil
+	}
+
+	location := &sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(filePath(start))},
+	}
+	location.Region.StartLine, location.Region.StartColumn,
+		location.Region.EndLine, location.Region.EndColumn = lineRange(start, end)
+
+	return location
+}
+
+func sarifLevel(kind Kind) string {
+	switch kind {
+	case KindWarning:
+		return "warning"
+
+	case KindError:
+		return "error"
+	}
+	return "note"
+}
+
+//------------------------------------------------
+// Helper functions
+//------------------------------------------------
+
+func filePath(pos token.Pos) string {
+	if fileInfo, ok := config.Global.Files[pos.FileID]; ok {
+		return fileInfo.Path
+	}
+	return ""
+}
+
+// Returns the range of the positions. The end of [token.Pos] is
+// inclusive, so the end column is moved to the next character.
+func lineRange(start, end token.Pos) (startLine, startColumn, endLine, endColumn uint32) {
+	startLine, startColumn = start.Line, start.Char
+
+	if end.Line > 0 {
+		endLine = end.Line
+		if end.Char > 0 {
+			endColumn = end.Char + 1
+		}
+	}
+
+	return
+}
@@ -0,0 +1,156 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/saffage/jet/config"
+	"github.com/saffage/jet/token"
+)
+
+func setupFormat(t *testing.T, format Format) *bytes.Buffer {
+	t.Helper()
+
+	output := &bytes.Buffer{}
+	files, prevOutput, prevLevel := config.Global.Files, Output, Level
+
+	OutputFormat, Output, Level = format, output, KindNote
+	config.Global.Files = map[config.FileID]config.FileInfo{
+		1: {Name: "main", Path: "src/main.jet", Buf: bytes.NewBufferString("x := y\n")},
+	}
+
+	t.Cleanup(func() {
+		OutputFormat, Output, Level = FormatText, prevOutput, prevLevel
+		config.Global.Files = files
+	})
+
+	return output
+}
+
+var testDiagnostic = Diagnostic{
+	Kind:    KindError,
+	Tag:     "checker",
+	Code:    "E0201",
+	Start:   token.Pos{FileID: 1, Line: 1, Char: 6},
+	End:     token.Pos{FileID: 1, Line: 1, Char: 6},
+	Message: "identifier 'y' is undefined",
+	Notes: []Diagnostic{{
+		Kind:    KindNote,
+		Tag:     "checker",
+		Start:   token.Pos{FileID: 1, Line: 1, Char: 1},
+		End:     token.Pos{FileID: 1, Line: 1, Char: 1},
+		Message: "in the declaration of 'x'",
+	}},
+	Fixes: []Fix{{
+		Message: "did you mean 'x'?",
+		Start:   token.Pos{FileID: 1, Offset: 5, Line: 1, Char: 6},
+		End:     token.Pos{FileID: 1, Offset: 5, Line: 1, Char: 6},
+		NewText: "x",
+	}},
+}
+
+func TestFormatJSON(t *testing.T) {
+	output := setupFormat(t, FormatJSON)
+
+	Emit(testDiagnostic)
+	TaggedHintf("checker", "checking module '%s'", "main")
+	Level = KindDebug
+	TaggedDebugf("checker", "checking declaration '%s'", "x")
+
+	expected := `{"severity":"error","code":"E0201","tag":"checker","message":"identifier 'y' is undefined",` +
+		`"location":{"file":"src/main.jet","startLine":1,"startColumn":6,"endLine":1,"endColumn":7},` +
+		`"notes":[{"severity":"note","tag":"checker","message":"in the declaration of 'x'",` +
+		`"location":{"file":"src/main.jet","startLine":1,"startColumn":1,"endLine":1,"endColumn":2}}],` +
+		`"fixes":[{"message":"did you mean 'x'?",` +
+		`"location":{"file":"src/main.jet","startLine":1,"startColumn":6,"endLine":1,"endColumn":7},"newText":"x"}]}` + "\n"
+
+	if output.String() != expected {
+		t.Errorf("unexpected output\nexpect:\n%s\nactual:\n%s", expected, output)
+	}
+}
+
+func TestFormatSARIF(t *testing.T) {
+	output := setupFormat(t, FormatSARIF)
+	sarifResults, sarifFlushed = []sarifResult{}, false
+
+	Emit(testDiagnostic)
+	TaggedHint("cc", "gcc -c -o main.o main.c")
+	TaggedWarningAt("lint", token.Pos{FileID: 1, Line: 1, Char: 1}, token.Pos{FileID: 1, Line: 1, Char: 1}, "unused variable 'x'")
+
+	if output.Len() != 0 {
+		t.Fatalf("expected the document to be written by Flush, got %s", output)
+	}
+
+	Flush()
+	Flush()
+
+	var log sarifLog
+	if err := json.Unmarshal(output.Bytes(), &log); err != nil {
+		t.Fatalf("expected a single SARIF document: %s\n%s", err, output)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Name != "jet" {
+		t.Fatalf("unexpected SARIF document\n%s", output)
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Level != "error" || results[1].Level != "warning" {
+		t.Errorf("unexpected levels '%s' and '%s'", results[0].Level, results[1].Level)
+	}
+
+	if results[0].RuleID != "E0201" || results[1].RuleID != "" {
+		t.Errorf("unexpected rule IDs '%s' and '%s'", results[0].RuleID, results[1].RuleID)
+	}
+
+	location := results[0].Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "src/main.jet" ||
+		location.Region != (sarifRegion{StartLine: 1, StartColumn: 6, EndLine: 1, EndColumn: 7}) {
+		t.Errorf("unexpected location %+v", location)
+	}
+
+	fixes := results[0].Fixes
+	if len(fixes) != 1 || fixes[0].ArtifactChanges[0].Replacements[0].InsertedContent.Text != "x" {
+		t.Errorf("unexpected fixes %+v", fixes)
+	}
+
+	related := results[0].RelatedLocations
+	if len(related) != 1 || related[0].Message.Text != "in the declaration of 'x'" {
+		t.Errorf("expected the note to be a related location, got %+v", related)
+	}
+}
+
+func TestMaxErrors(t *testing.T) {
+	output := setupFormat(t, FormatJSON)
+	maxErrors := config.Global.MaxErrors
+	config.Global.MaxErrors, errorCount = 2, 0
+
+	t.Cleanup(func() {
+		config.Global.MaxErrors, errorCount = maxErrors, 0
+	})
+
+	for range 4 {
+		Emit(testDiagnostic)
+	}
+	Warningf("unused variable 'x'")
+
+	lines := bytes.Split(bytes.TrimSpace(output.Bytes()), []byte("\n"))
+	severities := []string{}
+
+	for _, line := range lines {
+		d := jsonDiagnostic{}
+		if err := json.Unmarshal(line, &d); err != nil {
+			t.Fatal(err)
+		}
+		severities = append(severities, d.Severity)
+	}
+
+	if !slices.Equal(severities, []string{"error", "error", "note", "warning"}) {
+		t.Errorf("unexpected diagnostics:\n%s", output)
+	}
+}
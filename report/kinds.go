@@ -40,13 +40,26 @@ func (kind Kind) Label() string {
 
 // Label format is `kind(tag):` if 'tag' is not empty.
 func (kind Kind) TaggedLabel(tag string) string {
+	return kind.CodedLabel(tag, "")
+}
+
+// Label format is `kind[code](tag):`, the code and the tag are omitted
+// if they are empty.
+func (kind Kind) CodedLabel(tag, code string) string {
+	suffix := ""
+	if code != "" {
+		suffix += "[" + code + "]"
+	}
 	if tag != "" {
-		if UseColors {
-			return kind.Color().Sprintf("%"+align()+"s(%s):", kind.String(), tag)
-		}
-		return fmt.Sprintf("%"+align()+"s(%s):", kind.String(), tag)
+		suffix += "(" + tag + ")"
+	}
+	if suffix == "" {
+		return kind.Label()
+	}
+	if UseColors {
+		return kind.Color().Sprintf("%"+align()+"s%s:", kind.String(), suffix)
 	}
-	return kind.Label()
+	return fmt.Sprintf("%"+align()+"s%s:", kind.String(), suffix)
 }
 
 func align() string {
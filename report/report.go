@@ -31,43 +31,115 @@ func display(kind Kind, message string) {
 }
 
 func reportInternal(kind Kind, tag, message string) {
-	if kind < Level {
+	reportDiagnostic(Diagnostic{Kind: kind, Tag: tag, Message: message})
+}
+
+func reportAtInternal(kind Kind, tag string, start, end token.Pos, message string) {
+	reportDiagnostic(Diagnostic{Kind: kind, Tag: tag, Start: start, End: end, Message: message})
+}
+
+// Number of the errors written to the output, the errors beyond
+// [config.Config.MaxErrors] are not written.
+var errorCount = 0
+
+// Passes the diagnostic to the [Handler] or writes it in the selected
+// format.
+func reportDiagnostic(d Diagnostic) {
+	if d.Kind < Level {
 		return
 	}
 
-	if strings.TrimSpace(message) == "" {
-		message = "<no message provided>"
+	if d.Start.FileID != d.End.FileID {
+		panic(fmt.Sprintf("start & end position have different file IDs (%d and %d)", d.Start.FileID, d.End.FileID))
 	}
 
-	display(kind, fmt.Sprintf("%s %s", kind.TaggedLabel(tag), message))
-}
+	if strings.TrimSpace(d.Message) == "" {
+		d.Message = "<no message provided>"
+	}
 
-func reportAtInternal(kind Kind, tag string, start, end token.Pos, message string) {
-	if kind < Level {
+	if Handler != nil {
+		Handler(d)
 		return
 	}
 
-	if start.FileID != end.FileID {
-		panic(fmt.Sprintf("start & end position have different file IDs (%d and %d)", start.FileID, end.FileID))
+	if d.Kind == KindError && config.Global.MaxErrors > 0 {
+		errorCount++
+
+		switch {
+		case errorCount == config.Global.MaxErrors+1:
+			d = Diagnostic{
+				Kind:    KindNote,
+				Message: fmt.Sprintf("too many errors, only the first %d are reported", config.Global.MaxErrors),
+			}
+
+		case errorCount > config.Global.MaxErrors+1:
+			return
+		}
 	}
 
-	// We do it here because the message will not be empty
-	// if the location is specified.
-	if strings.TrimSpace(message) == "" {
-		message = "<no message provided>"
+	switch {
+	case OutputFormat != FormatText:
+		emit(d)
+
+	default:
+		display(d.Kind, formatText(d))
+
+		for _, note := range d.Notes {
+			if note.Kind >= Level {
+				display(note.Kind, formatText(note))
+			}
+		}
+
+		for _, fix := range d.Fixes {
+			display(d.Kind, formatFix(fix))
+		}
 	}
+}
+
+// Formats the diagnostic as the label, the message and the source
+// line with the underlined range, if the location is known.
+func formatText(d Diagnostic) string {
+	message := d.Message
 
-	line := "\n" + formatLoc(start)
+	if d.Start.Line > 0 {
+		message += "\n" + formatLoc(d.Start)
 
-	if fileInfo, ok := config.Global.Files[start.FileID]; ok {
-		line += generateLine(kind, start, end, fileInfo.Buf.Bytes())
+		if fileInfo, ok := config.Global.Files[d.Start.FileID]; ok {
+			message += generateLine(d.Kind, d.Start, d.End, fileInfo.Buf.Bytes())
+		}
 	}
 
+	return fmt.Sprintf("%s %s", d.Kind.CodedLabel(d.Tag, d.Code), message)
+}
+
+// Formats the fix as the label, the message and the source line with
+// the fix applied, if the fixed range is on a single line.
+func formatFix(fix Fix) string {
+	label := fmt.Sprintf("%"+align()+"s:", "fix")
 	if UseColors {
-		reportInternal(kind, tag, message+line)
-	} else {
-		reportInternal(kind, tag, message+line)
+		label = fixColor.Sprint(label)
 	}
+
+	message := fix.Message
+
+	if fix.Start.Line > 0 {
+		message += "\n" + formatLoc(fix.Start)
+
+		if fileInfo, ok := config.Global.Files[fix.Start.FileID]; ok && ShowLine && fix.Start.Line == fix.End.Line {
+			line := base.New(fileInfo.Buf.Bytes(), fix.Start.FileID).GetLine(int(fix.Start.Line))
+			left, right := int(fix.Start.Char)-1, int(fix.End.Char)
+
+			if 0 <= left && left <= right && right <= len(line) {
+				newText := fix.NewText
+				if UseColors {
+					newText = fixColor.Sprint(newText)
+				}
+				message += "\n" + lineNum(fmt.Sprintf("%d", fix.Start.Line)) + line[:left] + newText + line[right:]
+			}
+		}
+	}
+
+	return fmt.Sprintf("%s %s", label, message)
 }
 
 func generateLine(kind Kind, start, end token.Pos, buffer []byte) string {
@@ -191,4 +263,7 @@ func numLen(num int) (len int) {
 	return len
 }
 
-var lineNumStyle = color.New(color.Bold, color.FgHiGreen)
+var (
+	lineNumStyle = color.New(color.Bold, color.FgHiGreen)
+	fixColor     = color.New(color.FgGreen)
+)
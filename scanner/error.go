@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/saffage/jet/diag"
 	"github.com/saffage/jet/report"
 	"github.com/saffage/jet/token"
 )
@@ -23,6 +24,22 @@ var (
 	ErrorExpectedOctNumber       = errors.New("expected octal number")
 )
 
+// Codes of the errors, see [diag.Code].
+var codes = map[error]diag.Code{
+	ErrorIllegalCharacter:        diag.IllegalCharacter,
+	ErrorIllegalNumericBase:      diag.InvalidNumber,
+	ErrorInvalidByte:             diag.IllegalCharacter,
+	ErrorInvalidEscape:           diag.InvalidEscape,
+	ErrorUnterminatedStringLit:   diag.UnterminatedString,
+	ErrorFirstDigitIsZero:        diag.InvalidNumber,
+	ErrorExpectedIdentForSuffix:  diag.InvalidNumber,
+	ErrorExpectedDigitAfterPoint: diag.InvalidNumber,
+	ErrorExpectedDecNumber:       diag.InvalidNumber,
+	ErrorExpectedHexNumber:       diag.InvalidNumber,
+	ErrorExpectedBinNumber:       diag.InvalidNumber,
+	ErrorExpectedOctNumber:       diag.InvalidNumber,
+}
+
 type Error struct {
 	Start   token.Pos
 	End     token.Pos
@@ -46,6 +63,11 @@ func (e Error) Is(err error) bool {
 	return e.err == err
 }
 
+// Returns the code of the error.
+func (e Error) Code() diag.Code {
+	return codes[e.err]
+}
+
 func (e Error) Report() {
 	err, ok := e.err.(report.Reporter)
 	if ok && err != nil {
@@ -56,7 +78,14 @@ func (e Error) Report() {
 		if e.Message != "" {
 			message += ": " + e.Message
 		}
-		report.TaggedErrorAt("scanner", e.Start, e.End, message)
+		report.Emit(report.Diagnostic{
+			Kind:    report.KindError,
+			Tag:     "scanner",
+			Start:   e.Start,
+			End:     e.End,
+			Message: message,
+			Code:    string(e.Code()),
+		})
 	}
 }
 
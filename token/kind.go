@@ -94,6 +94,8 @@ const (
 	KwReturn   // keyword 'return'
 	KwBreak    // keyword 'break'
 	KwContinue // keyword 'continue'
+	KwMatch    // keyword 'match'
+	KwImport   // keyword 'import'
 )
 
 const (
@@ -110,7 +112,7 @@ const (
 	_operator_end   = Ellipsis
 
 	_keywords_begin = KwAnd
-	_keywords_end   = KwContinue
+	_keywords_end   = KwImport
 
 	_kinds_last = _keywords_end
 )
@@ -252,4 +254,6 @@ var representableKinds = map[Kind]string{
 	KwReturn:     "return",
 	KwBreak:      "break",
 	KwContinue:   "continue",
+	KwMatch:      "match",
+	KwImport:     "import",
 }
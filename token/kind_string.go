@@ -79,11 +79,13 @@ func _() {
 	_ = x[KwReturn-68]
 	_ = x[KwBreak-69]
 	_ = x[KwContinue-70]
+	_ = x[KwMatch-71]
+	_ = x[KwImport-72]
 }
 
-const _Kind_name = "IllegalEOFCommentWhitespaceTabNewLineIdentIntFloatStringLParenRParenLCurlyRCurlyLBracketRBracketCommaColonSemicolonEqEqOpBangNeOpLtOpLeOpGtOpGeOpShlShlEqShrShrEqPlusPlusEqMinusMinusEqAsteriskAsteriskEqSlashSlashEqPercentPercentEqAmpAmpEqPipePipeEqCaretCaretEqAtDollarQuestionMarkArrowFatArrowDotDot2Dot2LessEllipsisKwAndKwOrKwStructKwEnumKwMutKwIfKwElseKwWhileKwForKwInKwAsKwDeferKwReturnKwBreakKwContinue"
+const _Kind_name = "IllegalEOFCommentWhitespaceTabNewLineIdentIntFloatStringLParenRParenLCurlyRCurlyLBracketRBracketCommaColonSemicolonEqEqOpBangNeOpLtOpLeOpGtOpGeOpShlShlEqShrShrEqPlusPlusEqMinusMinusEqAsteriskAsteriskEqSlashSlashEqPercentPercentEqAmpAmpEqPipePipeEqCaretCaretEqAtDollarQuestionMarkArrowFatArrowDotDot2Dot2LessEllipsisKwAndKwOrKwStructKwEnumKwMutKwIfKwElseKwWhileKwForKwInKwAsKwDeferKwReturnKwBreakKwContinueKwMatchKwImport"
 
-var _Kind_index = [...]uint16{0, 7, 10, 17, 27, 30, 37, 42, 45, 50, 56, 62, 68, 74, 80, 88, 96, 101, 106, 115, 117, 121, 125, 129, 133, 137, 141, 145, 148, 153, 156, 161, 165, 171, 176, 183, 191, 201, 206, 213, 220, 229, 232, 237, 241, 247, 252, 259, 261, 267, 279, 284, 292, 295, 299, 307, 315, 320, 324, 332, 338, 343, 347, 353, 360, 365, 369, 373, 380, 388, 395, 405}
+var _Kind_index = [...]uint16{0, 7, 10, 17, 27, 30, 37, 42, 45, 50, 56, 62, 68, 74, 80, 88, 96, 101, 106, 115, 117, 121, 125, 129, 133, 137, 141, 145, 148, 153, 156, 161, 165, 171, 176, 183, 191, 201, 206, 213, 220, 229, 232, 237, 241, 247, 252, 259, 261, 267, 279, 284, 292, 295, 299, 307, 315, 320, 324, 332, 338, 343, 347, 353, 360, 365, 369, 373, 380, 388, 395, 405, 412, 420}
 
 func (i Kind) String() string {
 	if i >= Kind(len(_Kind_index)-1) {
@@ -79,11 +79,13 @@ func _() {
 	_ = x[KwReturn-68]
 	_ = x[KwBreak-69]
 	_ = x[KwContinue-70]
+	_ = x[KwMatch-71]
+	_ = x[KwImport-72]
 }
 
-const _Kind_user_name = "illegal characterend of filecommentwhitespacehorizontal tabulationnew lineidentifieruntyped intuntyped floatuntyped string'('')''{''}''['']'','':'';'operator '='operator '=='operator '!'operator '!='operator '<'operator '<='operator '>'operator '>='operator '<<'operator '<<='operator '>>'operator '>>='operator '+'operator '+='operator '-'operator '-='operator '*'operator '*='operator '/'operator '/='operator '%'operator '%='operator '&'operator '&='operator '|'operator '|='operator '^'operator '^='operator '@'operator '$'operator '?'operator '->'operator '=>'operator '.'operator '..'operator '..<'operator '...'keyword 'and'keyword 'or'keyword 'struct'keyword 'enum'keyword 'mut'keyword 'if'keyword 'else'keyword 'while'keyword 'for'keyword 'in'keyword 'as'keyword 'defer'keyword 'return'keyword 'break'keyword 'continue'"
+const _Kind_user_name = "illegal characterend of filecommentwhitespacehorizontal tabulationnew lineidentifieruntyped intuntyped floatuntyped string'('')''{''}''['']'','':'';'operator '='operator '=='operator '!'operator '!='operator '<'operator '<='operator '>'operator '>='operator '<<'operator '<<='operator '>>'operator '>>='operator '+'operator '+='operator '-'operator '-='operator '*'operator '*='operator '/'operator '/='operator '%'operator '%='operator '&'operator '&='operator '|'operator '|='operator '^'operator '^='operator '@'operator '$'operator '?'operator '->'operator '=>'operator '.'operator '..'operator '..<'operator '...'keyword 'and'keyword 'or'keyword 'struct'keyword 'enum'keyword 'mut'keyword 'if'keyword 'else'keyword 'while'keyword 'for'keyword 'in'keyword 'as'keyword 'defer'keyword 'return'keyword 'break'keyword 'continue'keyword 'match'keyword 'import'"
 
-var _Kind_user_index = [...]uint16{0, 17, 28, 35, 45, 66, 74, 84, 95, 108, 122, 125, 128, 131, 134, 137, 140, 143, 146, 149, 161, 174, 186, 199, 211, 224, 236, 249, 262, 276, 289, 303, 315, 328, 340, 353, 365, 378, 390, 403, 415, 428, 440, 453, 465, 478, 490, 503, 515, 527, 539, 552, 565, 577, 590, 604, 618, 631, 643, 659, 673, 686, 698, 712, 727, 740, 752, 764, 779, 795, 810, 828}
+var _Kind_user_index = [...]uint16{0, 17, 28, 35, 45, 66, 74, 84, 95, 108, 122, 125, 128, 131, 134, 137, 140, 143, 146, 149, 161, 174, 186, 199, 211, 224, 236, 249, 262, 276, 289, 303, 315, 328, 340, 353, 365, 378, 390, 403, 415, 428, 440, 453, 465, 478, 490, 503, 515, 527, 539, 552, 565, 577, 590, 604, 618, 631, 643, 659, 673, 686, 698, 712, 727, 740, 752, 764, 779, 795, 810, 828, 843, 859}
 
 func (i Kind) UserString() string {
 	if i >= Kind(len(_Kind_user_index)-1) {
@@ -3,11 +3,22 @@ package types
 import "strings"
 
 type Enum struct {
-	fields []string
+	fields   []string
+	payloads []*Struct // Payload of each field, nil if the field has no payload.
 }
 
 func NewEnum(fields ...string) *Enum {
-	return &Enum{fields}
+	return &Enum{fields, make([]*Struct, len(fields))}
+}
+
+// Sets payloads of the fields. Payloads are set after the enum is
+// created, so a payload can refer to the enum itself.
+func (t *Enum) SetPayloads(payloads ...*Struct) {
+	if len(payloads) != len(t.fields) {
+		panic("payloads count mismatch")
+	}
+
+	t.payloads = payloads
 }
 
 func (t *Enum) Equals(other Type) bool {
@@ -15,6 +26,10 @@ func (t *Enum) Equals(other Type) bool {
 		return t2.kind == KindAny
 	}
 	if t2 := AsEnum(other); t2 != nil {
+		if t == t2 {
+			return true
+		}
+
 		if len(t2.fields) != len(t.fields) {
 			return false
 		}
@@ -23,6 +38,11 @@ func (t *Enum) Equals(other Type) bool {
 			if t.fields[i] != t2.fields[i] {
 				return false
 			}
+
+			if (t.payloads[i] == nil) != (t2.payloads[i] == nil) ||
+				t.payloads[i] != nil && !t.payloads[i].Equals(t2.payloads[i]) {
+				return false
+			}
 		}
 
 		return true
@@ -37,13 +57,18 @@ func (t *Enum) String() string {
 	buf.WriteString("enum{")
 
 	first := true
-	for _, field := range t.fields {
+	for i, field := range t.fields {
 		if !first {
 			buf.WriteString("; ")
 		}
 
 		buf.WriteString(field)
 		first = false
+
+		if t.payloads[i] != nil {
+			buf.WriteByte(' ')
+			buf.WriteString(t.payloads[i].String())
+		}
 	}
 
 	buf.WriteByte('}')
@@ -52,6 +77,28 @@ func (t *Enum) String() string {
 
 func (t *Enum) Fields() []string { return t.fields }
 
+// Returns the payload of the field, or nil if the field has no payload.
+func (t *Enum) Payload(field string) *Struct {
+	for i := range t.fields {
+		if t.fields[i] == field {
+			return t.payloads[i]
+		}
+	}
+
+	return nil
+}
+
+// Reports whether any field of the enum has a payload.
+func (t *Enum) IsTagged() bool {
+	for _, payload := range t.payloads {
+		if payload != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 func IsEnum(t Type) bool { return AsEnum(t) != nil }
 
 func AsEnum(t Type) *Enum {
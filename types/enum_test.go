@@ -0,0 +1,22 @@
+package types
+
+import "testing"
+
+func TestEnumPayloads(t *testing.T) {
+	x := NewEnum("Num", "Neg")
+	x.SetPayloads(NewStruct(StructField{"v", I32}), NewStruct(StructField{"x", NewRef(x)}))
+	y := NewEnum("Num", "Neg")
+	y.SetPayloads(NewStruct(StructField{"v", I32}), NewStruct(StructField{"x", NewRef(y)}))
+	z := NewEnum("Num", "Neg")
+
+	test(t, x, y, true)
+	test(t, x, z, false)
+
+	if !x.IsTagged() || z.IsTagged() {
+		t.Errorf("expected only '%s' to be tagged", x)
+	}
+
+	if x.Payload("Num") == nil || x.Payload("Other") != nil {
+		t.Errorf("unexpected payloads of '%s'", x)
+	}
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -82,6 +83,13 @@ func (t *Func) CheckArgValues(args []constant.Value) (idx int, err error) {
 	return t.CheckArgs(tyArgs)
 }
 
+// Errors returned by [Func.CheckArgs] when the number of the arguments
+// does not match the number of the parameters.
+var (
+	ErrTooManyArgs   = errors.New("too many arguments")
+	ErrNotEnoughArgs = errors.New("not enough arguments")
+)
+
 func (t *Func) CheckArgs(args *Tuple) (idx int, err error) {
 	{
 		diff := t.params.Len() - args.Len()
@@ -94,12 +102,12 @@ func (t *Func) CheckArgs(args *Tuple) (idx int, err error) {
 
 		if diff < 0 && t.variadic == nil {
 			return min(t.params.Len(), args.Len()),
-				fmt.Errorf("too many arguments (expected %d, got %d)", t.params.Len(), args.Len())
+				fmt.Errorf("%w (expected %d, got %d)", ErrTooManyArgs, t.params.Len(), args.Len())
 		}
 
 		if diff > 0 {
 			return min(t.params.Len(), args.Len()),
-				fmt.Errorf("not enough arguments (expected %d, got %d)", t.params.Len(), args.Len())
+				fmt.Errorf("%w (expected %d, got %d)", ErrNotEnoughArgs, t.params.Len(), args.Len())
 		}
 	}
 
@@ -164,6 +164,21 @@ func AsPrimitive(t Type) *Primitive {
 	return nil
 }
 
+// Reports whether the type is an integer or a floating-point number,
+// including the untyped numbers.
+func IsNumeric(t Type) bool {
+	if p := AsPrimitive(t); p != nil {
+		switch p.kind {
+		case KindUntypedInt, KindUntypedFloat,
+			KindI8, KindI16, KindI32, KindI64,
+			KindU8, KindU16, KindU32, KindU64,
+			KindF32, KindF64:
+			return true
+		}
+	}
+	return false
+}
+
 func SkipUntyped(t Type) Type {
 	if t != nil {
 		switch t := SkipAlias(t).(type) {
@@ -0,0 +1,51 @@
+package types
+
+import "fmt"
+
+// Slice is a view into a sequence of elements, represented as
+// a pointer to the first element and a length.
+type Slice struct {
+	elem Type
+}
+
+func NewSlice(t Type) *Slice {
+	return &Slice{t}
+}
+
+func (t *Slice) Equals(expected Type) bool {
+	if expected := AsPrimitive(expected); expected != nil {
+		return expected.kind == KindAny
+	}
+
+	if expected := AsSlice(expected); expected != nil {
+		return t.elem.Equals(expected.elem)
+	}
+
+	return false
+}
+
+func (t *Slice) Underlying() Type {
+	return t
+}
+
+func (t *Slice) String() string {
+	return fmt.Sprintf("[]%s", t.elem)
+}
+
+func (t *Slice) ElemType() Type {
+	return t.elem
+}
+
+func IsSlice(t Type) bool {
+	return AsSlice(t) != nil
+}
+
+func AsSlice(t Type) *Slice {
+	if t != nil {
+		if slice, _ := t.Underlying().(*Slice); slice != nil {
+			return slice
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package types
+
+import "testing"
+
+func TestSlice(t *testing.T) {
+	test(t, NewSlice(I32), NewSlice(I32), true)
+	test(t, NewSlice(I32), NewSlice(U8), false)
+	test(t, NewSlice(I32), NewArray(2, I32), false)
+	test(t, NewSlice(NewSlice(I32)), NewSlice(NewSlice(I32)), true)
+
+	if s := NewSlice(NewRef(I32)).String(); s != "[]*i32" {
+		t.Errorf("expected '[]*i32', got '%s'", s)
+	}
+}
@@ -18,6 +18,14 @@ type Struct struct {
 }
 
 func NewStruct(fields ...StructField) *Struct {
+	t := &Struct{}
+	t.SetFields(fields...)
+	return t
+}
+
+// Replaces fields of the struct. Struct can be created without fields
+// and completed later, so its fields can refer to the struct itself.
+func (t *Struct) SetFields(fields ...StructField) {
 	fieldNames := make([]string, 0, len(fields))
 	for _, field := range fields {
 		if IsUntyped(field.Type) {
@@ -28,14 +36,31 @@ func NewStruct(fields ...StructField) *Struct {
 		}
 		fieldNames = append(fieldNames, field.Name)
 	}
-	return &Struct{fields}
+	t.fields = fields
 }
 
+// Pairs of structs which are being compared right now. Recursive structs
+// refer to themselves, so when the comparison comes back to the same
+// pair, the structs are considered equal.
+var comparing = map[[2]*Struct]struct{}{}
+
+// Structs which are being converted to a string right now.
+var printing = map[*Struct]struct{}{}
+
 func (t *Struct) Equals(other Type) bool {
 	if t2 := AsPrimitive(other); t2 != nil {
 		return t2.kind == KindAny
 	}
 	if t2 := AsStruct(other); t2 != nil {
+		if t == t2 {
+			return true
+		}
+		pair := [2]*Struct{t, t2}
+		if _, ok := comparing[pair]; ok {
+			return true
+		}
+		comparing[pair] = struct{}{}
+		defer delete(comparing, pair)
 		return slices.EqualFunc(t.fields, t2.fields, func(f1, f2 StructField) bool {
 			return f1.Name == f2.Name && f1.Type.Equals(f2.Type)
 		})
@@ -50,6 +75,12 @@ func (t *Struct) Equals(other Type) bool {
 func (t *Struct) Underlying() Type { return t }
 
 func (t *Struct) String() string {
+	if _, ok := printing[t]; ok {
+		return "struct{...}"
+	}
+	printing[t] = struct{}{}
+	defer delete(printing, t)
+
 	buf := strings.Builder{}
 	buf.WriteString("struct{")
 
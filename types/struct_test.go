@@ -19,3 +19,17 @@ func TestEquals(t *testing.T) {
 		t.Errorf("struct types are equals, but shouldn't:\nx: %s\ny: %s", x, z)
 	}
 }
+
+func TestEqualsRecursive(t *testing.T) {
+	x, y := NewStruct(), NewStruct()
+	x.SetFields(StructField{"data", I32}, StructField{"next", NewRef(x)})
+	y.SetFields(StructField{"data", I32}, StructField{"next", NewRef(y)})
+
+	if !x.Equals(y) {
+		t.Errorf("struct types are not equals, but should:\nx: %s\ny: %s", x, y)
+	}
+
+	if s := x.String(); s != "struct{data i32; next *struct{...}}" {
+		t.Errorf("unexpected string representation: %s", s)
+	}
+}